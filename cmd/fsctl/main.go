@@ -0,0 +1,316 @@
+// Command fsctl is a command line client for the file-streamer server.
+//
+// Usage:
+//
+//	fsctl [-addr url] [-namespace name] [-key-file path] [-api-key key | -token jwt]
+//	      [-tls-ca path] [-tls-cert path -tls-key path] <command> [args]
+//
+// Commands:
+//
+//	keygen                           print a new encryption key
+//	info <name>                      show information about a stored file
+//	upload [-tag k=v]... <local-path> <name>
+//	                                 upload a file ("-" reads stdin)
+//	download [-offset n] [-length n] <name> <local-path>
+//	                                 download a file or a byte range ("-" writes stdout)
+//	versions <name>                  list the versions of a file
+//	restore <name> <version-id>      make an old version the latest one
+//	rm <name>                        move a file to the trash
+//	trash                            list deleted files
+//	undelete <name> [trash-id]       restore a deleted file
+//
+// When -key-file is set, uploads are encrypted client-side and downloads of
+// encrypted files are decrypted, so the server never sees plaintext.
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/client"
+	"github.com/gilwong00/file-streamer/internal/pkg/envelope"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("fsctl: ")
+	addr := flag.String("addr", "http://localhost:5555", "ConnectRPC server base URL")
+	namespace := flag.String("namespace", "", "namespace to address (server default if empty)")
+	keyFile := flag.String("key-file", "", "file holding a base64 encryption key; enables end-to-end encryption")
+	apiKey := flag.String("api-key", os.Getenv("FSCTL_API_KEY"), "API key to authenticate with (default $FSCTL_API_KEY)")
+	token := flag.String("token", os.Getenv("FSCTL_TOKEN"), "bearer token (JWT) to authenticate with (default $FSCTL_TOKEN)")
+	tlsCA := flag.String("tls-ca", "", "PEM file of the CAs trusted to verify an https server (system roots if empty)")
+	tlsCert := flag.String("tls-cert", "", "PEM client certificate to present to an https server, for mTLS")
+	tlsKey := flag.String("tls-key", "", "PEM private key of -tls-cert")
+	batch := flag.Bool("batch", false, "mark requests as batch work, admitted after interactive ones when the server is busy")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: fsctl [flags] keygen|info|upload|download|versions|restore|rm|trash|undelete [args]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	cmd, args := flag.Arg(0), flag.Args()[1:]
+	if cmd == "keygen" {
+		key, err := envelope.GenerateKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(key.String())
+		return
+	}
+	opts := []client.Option{client.WithNamespace(*namespace)}
+	if *keyFile != "" {
+		key, err := envelope.LoadKeyFile(*keyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, client.WithEncryptionKey(key))
+	}
+	if *apiKey != "" {
+		opts = append(opts, client.WithAPIKey(*apiKey))
+	}
+	if *token != "" {
+		opts = append(opts, client.WithBearerToken(*token))
+	}
+	if *batch {
+		opts = append(opts, client.WithBatchPriority())
+	}
+	if *tlsCA != "" || *tlsCert != "" {
+		tlsConfig, err := loadTLSConfig(*tlsCA, *tlsCert, *tlsKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts = append(opts, client.WithTLSConfig(tlsConfig))
+	}
+	c := client.New(*addr, opts...)
+	var err error
+	switch cmd {
+	case "info":
+		err = info(ctx, c, args)
+	case "upload":
+		err = upload(ctx, c, args)
+	case "download":
+		err = download(ctx, c, args)
+	case "versions":
+		err = versions(ctx, c, args)
+	case "restore":
+		err = restore(ctx, c, args)
+	case "rm":
+		err = remove(ctx, c, args)
+	case "trash":
+		err = listTrash(ctx, c, args)
+	case "undelete":
+		err = undelete(ctx, c, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// loadTLSConfig returns a client TLS configuration trusting the CAs in
+// caFile, when set, and presenting the certificate in certFile, when set.
+func loadTLSConfig(caFile, certFile, keyFile string) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%s holds no certificates", caFile)
+		}
+	}
+	if certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+func info(ctx context.Context, c *client.Client, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: fsctl info <name>")
+	}
+	info, err := c.Stat(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("name:         %s\n", info.Name)
+	fmt.Printf("size:         %d\n", info.Size)
+	fmt.Printf("stored size:  %d\n", info.StoredSize)
+	fmt.Printf("etag:         %s\n", info.ETag)
+	fmt.Printf("content type: %s\n", info.ContentType)
+	fmt.Printf("encrypted:    %t\n", info.Encrypted)
+	if info.VersionID != "" {
+		fmt.Printf("version:      %s\n", info.VersionID)
+	}
+	return nil
+}
+
+func upload(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("upload", flag.ContinueOnError)
+	tags := tagFlag{}
+	fs.Var(tags, "tag", "tag to attach as key=value (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	args = fs.Args()
+	if len(args) != 2 {
+		return fmt.Errorf("usage: fsctl upload [-tag k=v]... <local-path> <name>")
+	}
+	var src io.Reader = os.Stdin
+	if args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		src = f
+	}
+	n, err := c.Upload(ctx, args[1], src, client.WithUploadTags(tags))
+	if err != nil {
+		return err
+	}
+	log.Printf("uploaded %s (%d bytes stored)", args[1], n)
+	return nil
+}
+
+// tagFlag collects repeated key=value flags.
+type tagFlag map[string]string
+
+func (t tagFlag) String() string {
+	pairs := make([]string, 0, len(t))
+	for k, v := range t {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (t tagFlag) Set(value string) error {
+	k, v, ok := strings.Cut(value, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("invalid tag %q, want key=value", value)
+	}
+	t[k] = v
+	return nil
+}
+
+func download(ctx context.Context, c *client.Client, args []string) error {
+	fs := flag.NewFlagSet("download", flag.ContinueOnError)
+	offset := fs.Int64("offset", 0, "first byte to download")
+	length := fs.Int64("length", -1, "number of bytes to download (-1 for the rest of the file)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: fsctl download [-offset n] [-length n] <name> <local-path>")
+	}
+	var dst io.Writer = os.Stdout
+	if fs.Arg(1) != "-" {
+		f, err := os.Create(fs.Arg(1))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		dst = f
+	}
+	_, err := c.Download(ctx, fs.Arg(0), dst, *offset, *length)
+	return err
+}
+
+func versions(ctx context.Context, c *client.Client, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: fsctl versions <name>")
+	}
+	versions, err := c.Versions(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	for _, v := range versions {
+		state := ""
+		switch {
+		case v.IsDeleteMarker:
+			state = "deleted"
+		case v.IsLatest:
+			state = "latest"
+		}
+		fmt.Printf("%s\t%d\t%s\t%s\n", v.ID, v.Size, v.LastModified.Format(time.RFC3339), state)
+	}
+	return nil
+}
+
+func restore(ctx context.Context, c *client.Client, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: fsctl restore <name> <version-id>")
+	}
+	versionID, err := c.RestoreVersion(ctx, args[0], args[1])
+	if err != nil {
+		return err
+	}
+	log.Printf("restored %s to version %s (new version %s)", args[0], args[1], versionID)
+	return nil
+}
+
+func remove(ctx context.Context, c *client.Client, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: fsctl rm <name>")
+	}
+	entry, err := c.Delete(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	log.Printf("moved %s to trash (id %s, restorable until %s)", args[0], entry.ID, entry.ExpiresAt.Format(time.RFC3339))
+	return nil
+}
+
+func listTrash(ctx context.Context, c *client.Client, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("usage: fsctl trash")
+	}
+	entries, err := c.ListTrash(ctx)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		fmt.Printf("%s\t%s\t%d\t%s\t%s\n", e.ID, e.FileName, e.Size,
+			e.DeletedAt.Format(time.RFC3339), e.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
+
+func undelete(ctx context.Context, c *client.Client, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return fmt.Errorf("usage: fsctl undelete <name> [trash-id]")
+	}
+	var trashID string
+	if len(args) == 2 {
+		trashID = args[1]
+	}
+	if err := c.Undelete(ctx, args[0], trashID); err != nil {
+		return err
+	}
+	log.Printf("restored %s", args[0])
+	return nil
+}
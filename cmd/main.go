@@ -2,20 +2,40 @@ package main
 
 import (
 	"context"
-	"log"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gilwong00/file-streamer/internal/pkg/config"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
 	"github.com/gilwong00/file-streamer/internal/server"
 )
 
 func main() {
-	ctx := context.Background()
-	cfg, err := config.NewConfig()
+	// Shutdown starts on the first SIGINT or SIGTERM; a second one kills
+	// the process without waiting for in-flight requests.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	configFile := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML, TOML or JSON configuration file; environment variables override its settings (default $CONFIG_FILE)")
+	flag.Parse()
+	cfg, err := config.Load(*configFile)
 	if err != nil {
-		log.Fatalf("Error unmarshalling config")
+		slog.Error("loading config", "error", err)
+		os.Exit(1)
+	}
+	if err := logging.Setup(os.Stderr, cfg.LogLevel, cfg.LogFormat); err != nil {
+		slog.Error("configuring logging", "error", err)
+		os.Exit(1)
 	}
-	// TODO: will need to add logging and maybe a better implementation
 	if err := server.StartServer(ctx, cfg); err != nil {
-		panic(err)
+		slog.Error("server stopped", "error", err)
+		os.Exit(1)
 	}
+	slog.Info("server stopped")
 }
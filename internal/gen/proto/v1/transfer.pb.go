@@ -21,9 +21,59 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type PresignMethod int32
+
+const (
+	PresignMethod_PRESIGN_METHOD_UNSPECIFIED PresignMethod = 0
+	PresignMethod_PRESIGN_METHOD_GET         PresignMethod = 1
+	PresignMethod_PRESIGN_METHOD_PUT         PresignMethod = 2
+)
+
+// Enum value maps for PresignMethod.
+var (
+	PresignMethod_name = map[int32]string{
+		0: "PRESIGN_METHOD_UNSPECIFIED",
+		1: "PRESIGN_METHOD_GET",
+		2: "PRESIGN_METHOD_PUT",
+	}
+	PresignMethod_value = map[string]int32{
+		"PRESIGN_METHOD_UNSPECIFIED": 0,
+		"PRESIGN_METHOD_GET":         1,
+		"PRESIGN_METHOD_PUT":         2,
+	}
+)
+
+func (x PresignMethod) Enum() *PresignMethod {
+	p := new(PresignMethod)
+	*p = x
+	return p
+}
+
+func (x PresignMethod) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PresignMethod) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_v1_transfer_proto_enumTypes[0].Descriptor()
+}
+
+func (PresignMethod) Type() protoreflect.EnumType {
+	return &file_proto_v1_transfer_proto_enumTypes[0]
+}
+
+func (x PresignMethod) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PresignMethod.Descriptor instead.
+func (PresignMethod) EnumDescriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{0}
+}
+
 type GetFileSizeRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,6 +115,13 @@ func (x *GetFileSizeRequest) GetFileName() string {
 	return ""
 }
 
+func (x *GetFileSizeRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
 type GetFileSizeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Size          int64                  `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
@@ -109,30 +166,32 @@ func (x *GetFileSizeResponse) GetSize() int64 {
 	return 0
 }
 
-type StreamFileRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
-	Start         int64                  `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
-	ChunkSize     int64                  `protobuf:"varint,3,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
-	CanDecompress bool                   `protobuf:"varint,4,opt,name=can_decompress,json=canDecompress,proto3" json:"can_decompress,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+type FileVersion struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	VersionId      string                 `protobuf:"bytes,1,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Size           int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Etag           string                 `protobuf:"bytes,3,opt,name=etag,proto3" json:"etag,omitempty"`
+	LastModified   int64                  `protobuf:"varint,4,opt,name=last_modified,json=lastModified,proto3" json:"last_modified,omitempty"` // unix seconds
+	IsLatest       bool                   `protobuf:"varint,5,opt,name=is_latest,json=isLatest,proto3" json:"is_latest,omitempty"`
+	IsDeleteMarker bool                   `protobuf:"varint,6,opt,name=is_delete_marker,json=isDeleteMarker,proto3" json:"is_delete_marker,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
-func (x *StreamFileRequest) Reset() {
-	*x = StreamFileRequest{}
+func (x *FileVersion) Reset() {
+	*x = FileVersion{}
 	mi := &file_proto_v1_transfer_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamFileRequest) String() string {
+func (x *FileVersion) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamFileRequest) ProtoMessage() {}
+func (*FileVersion) ProtoMessage() {}
 
-func (x *StreamFileRequest) ProtoReflect() protoreflect.Message {
+func (x *FileVersion) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_transfer_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -144,62 +203,77 @@ func (x *StreamFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamFileRequest.ProtoReflect.Descriptor instead.
-func (*StreamFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use FileVersion.ProtoReflect.Descriptor instead.
+func (*FileVersion) Descriptor() ([]byte, []int) {
 	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *StreamFileRequest) GetFileName() string {
+func (x *FileVersion) GetVersionId() string {
 	if x != nil {
-		return x.FileName
+		return x.VersionId
 	}
 	return ""
 }
 
-func (x *StreamFileRequest) GetStart() int64 {
+func (x *FileVersion) GetSize() int64 {
 	if x != nil {
-		return x.Start
+		return x.Size
 	}
 	return 0
 }
 
-func (x *StreamFileRequest) GetChunkSize() int64 {
+func (x *FileVersion) GetEtag() string {
 	if x != nil {
-		return x.ChunkSize
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *FileVersion) GetLastModified() int64 {
+	if x != nil {
+		return x.LastModified
 	}
 	return 0
 }
 
-func (x *StreamFileRequest) GetCanDecompress() bool {
+func (x *FileVersion) GetIsLatest() bool {
 	if x != nil {
-		return x.CanDecompress
+		return x.IsLatest
 	}
 	return false
 }
 
-type StreamFileResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
-	Compressed    bool                   `protobuf:"varint,2,opt,name=compressed,proto3" json:"compressed,omitempty"`
-	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *FileVersion) GetIsDeleteMarker() bool {
+	if x != nil {
+		return x.IsDeleteMarker
+	}
+	return false
 }
 
-func (x *StreamFileResponse) Reset() {
-	*x = StreamFileResponse{}
+type GetFileInfoRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FileName        string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Namespace       string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	VersionId       string                 `protobuf:"bytes,3,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"` // empty for the latest version
+	IncludeVersions bool                   `protobuf:"varint,4,opt,name=include_versions,json=includeVersions,proto3" json:"include_versions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *GetFileInfoRequest) Reset() {
+	*x = GetFileInfoRequest{}
 	mi := &file_proto_v1_transfer_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StreamFileResponse) String() string {
+func (x *GetFileInfoRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StreamFileResponse) ProtoMessage() {}
+func (*GetFileInfoRequest) ProtoMessage() {}
 
-func (x *StreamFileResponse) ProtoReflect() protoreflect.Message {
+func (x *GetFileInfoRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_transfer_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -211,56 +285,67 @@ func (x *StreamFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StreamFileResponse.ProtoReflect.Descriptor instead.
-func (*StreamFileResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetFileInfoRequest.ProtoReflect.Descriptor instead.
+func (*GetFileInfoRequest) Descriptor() ([]byte, []int) {
 	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *StreamFileResponse) GetChunk() []byte {
+func (x *GetFileInfoRequest) GetFileName() string {
 	if x != nil {
-		return x.Chunk
+		return x.FileName
 	}
-	return nil
+	return ""
 }
 
-func (x *StreamFileResponse) GetCompressed() bool {
+func (x *GetFileInfoRequest) GetNamespace() string {
 	if x != nil {
-		return x.Compressed
+		return x.Namespace
 	}
-	return false
+	return ""
 }
 
-func (x *StreamFileResponse) GetOffset() int64 {
+func (x *GetFileInfoRequest) GetVersionId() string {
 	if x != nil {
-		return x.Offset
+		return x.VersionId
 	}
-	return 0
+	return ""
 }
 
-type UploadFileRequest struct {
+func (x *GetFileInfoRequest) GetIncludeVersions() bool {
+	if x != nil {
+		return x.IncludeVersions
+	}
+	return false
+}
+
+type GetFileInfoResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
-	Chunk         []byte                 `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
-	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
-	Compressed    bool                   `protobuf:"varint,4,opt,name=compressed,proto3" json:"compressed,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Etag          string                 `protobuf:"bytes,3,opt,name=etag,proto3" json:"etag,omitempty"`
+	ContentType   string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	LastModified  int64                  `protobuf:"varint,5,opt,name=last_modified,json=lastModified,proto3" json:"last_modified,omitempty"`                                              // unix seconds
+	Metadata      map[string]string      `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // user metadata stored with the object
+	VersionId     string                 `protobuf:"bytes,7,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Versions      []*FileVersion         `protobuf:"bytes,8,rep,name=versions,proto3" json:"versions,omitempty"` // newest first, when include_versions is set
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadFileRequest) Reset() {
-	*x = UploadFileRequest{}
+func (x *GetFileInfoResponse) Reset() {
+	*x = GetFileInfoResponse{}
 	mi := &file_proto_v1_transfer_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadFileRequest) String() string {
+func (x *GetFileInfoResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadFileRequest) ProtoMessage() {}
+func (*GetFileInfoResponse) ProtoMessage() {}
 
-func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
+func (x *GetFileInfoResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_v1_transfer_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -272,64 +357,154 @@ func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
-func (*UploadFileRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetFileInfoResponse.ProtoReflect.Descriptor instead.
+func (*GetFileInfoResponse) Descriptor() ([]byte, []int) {
 	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *UploadFileRequest) GetFileName() string {
+func (x *GetFileInfoResponse) GetFileName() string {
 	if x != nil {
 		return x.FileName
 	}
 	return ""
 }
 
-func (x *UploadFileRequest) GetChunk() []byte {
+func (x *GetFileInfoResponse) GetSize() int64 {
 	if x != nil {
-		return x.Chunk
+		return x.Size
 	}
-	return nil
+	return 0
 }
 
-func (x *UploadFileRequest) GetOffset() int64 {
+func (x *GetFileInfoResponse) GetEtag() string {
 	if x != nil {
-		return x.Offset
+		return x.Etag
+	}
+	return ""
+}
+
+func (x *GetFileInfoResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *GetFileInfoResponse) GetLastModified() int64 {
+	if x != nil {
+		return x.LastModified
 	}
 	return 0
 }
 
-func (x *UploadFileRequest) GetCompressed() bool {
+func (x *GetFileInfoResponse) GetMetadata() map[string]string {
 	if x != nil {
-		return x.Compressed
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *GetFileInfoResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *GetFileInfoResponse) GetVersions() []*FileVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
+
+type ListFilesRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Namespace       string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Prefix          string                 `protobuf:"bytes,2,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	IncludeVersions bool                   `protobuf:"varint,3,opt,name=include_versions,json=includeVersions,proto3" json:"include_versions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ListFilesRequest) Reset() {
+	*x = ListFilesRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesRequest) ProtoMessage() {}
+
+func (x *ListFilesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesRequest.ProtoReflect.Descriptor instead.
+func (*ListFilesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListFilesRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *ListFilesRequest) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *ListFilesRequest) GetIncludeVersions() bool {
+	if x != nil {
+		return x.IncludeVersions
 	}
 	return false
 }
 
-type UploadFileResponse struct {
+type FileEntry struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
-	BytesReceived int64                  `protobuf:"varint,2,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"` // server can send periodic progress updates
-	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	Size          int64                  `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
+	Etag          string                 `protobuf:"bytes,3,opt,name=etag,proto3" json:"etag,omitempty"`
+	LastModified  int64                  `protobuf:"varint,4,opt,name=last_modified,json=lastModified,proto3" json:"last_modified,omitempty"` // unix seconds
+	VersionId     string                 `protobuf:"bytes,5,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	Versions      []*FileVersion         `protobuf:"bytes,6,rep,name=versions,proto3" json:"versions,omitempty"` // newest first, when include_versions is set
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UploadFileResponse) Reset() {
-	*x = UploadFileResponse{}
-	mi := &file_proto_v1_transfer_proto_msgTypes[5]
+func (x *FileEntry) Reset() {
+	*x = FileEntry{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UploadFileResponse) String() string {
+func (x *FileEntry) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UploadFileResponse) ProtoMessage() {}
+func (*FileEntry) ProtoMessage() {}
 
-func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_v1_transfer_proto_msgTypes[5]
+func (x *FileEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -340,78 +515,1812 @@ func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
-func (*UploadFileResponse) Descriptor() ([]byte, []int) {
-	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use FileEntry.ProtoReflect.Descriptor instead.
+func (*FileEntry) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *UploadFileResponse) GetFileName() string {
+func (x *FileEntry) GetFileName() string {
 	if x != nil {
 		return x.FileName
 	}
 	return ""
 }
 
-func (x *UploadFileResponse) GetBytesReceived() int64 {
+func (x *FileEntry) GetSize() int64 {
 	if x != nil {
-		return x.BytesReceived
+		return x.Size
 	}
 	return 0
 }
 
-func (x *UploadFileResponse) GetSuccess() bool {
+func (x *FileEntry) GetEtag() string {
 	if x != nil {
-		return x.Success
+		return x.Etag
 	}
-	return false
+	return ""
 }
 
-func (x *UploadFileResponse) GetErrorMessage() string {
+func (x *FileEntry) GetLastModified() int64 {
 	if x != nil {
-		return x.ErrorMessage
+		return x.LastModified
+	}
+	return 0
+}
+
+func (x *FileEntry) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
 	}
 	return ""
 }
 
-var File_proto_v1_transfer_proto protoreflect.FileDescriptor
+func (x *FileEntry) GetVersions() []*FileVersion {
+	if x != nil {
+		return x.Versions
+	}
+	return nil
+}
 
-const file_proto_v1_transfer_proto_rawDesc = "" +
-	"\n" +
-	"\x17proto/v1/transfer.proto\x12\vtransfer.v1\"1\n" +
-	"\x12GetFileSizeRequest\x12\x1b\n" +
-	"\tfile_name\x18\x01 \x01(\tR\bfileName\")\n" +
-	"\x13GetFileSizeResponse\x12\x12\n" +
-	"\x04size\x18\x01 \x01(\x03R\x04size\"\x8c\x01\n" +
-	"\x11StreamFileRequest\x12\x1b\n" +
-	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x14\n" +
-	"\x05start\x18\x02 \x01(\x03R\x05start\x12\x1d\n" +
-	"\n" +
-	"chunk_size\x18\x03 \x01(\x03R\tchunkSize\x12%\n" +
-	"\x0ecan_decompress\x18\x04 \x01(\bR\rcanDecompress\"b\n" +
-	"\x12StreamFileResponse\x12\x14\n" +
-	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12\x1e\n" +
-	"\n" +
-	"compressed\x18\x02 \x01(\bR\n" +
-	"compressed\x12\x16\n" +
-	"\x06offset\x18\x03 \x01(\x03R\x06offset\"~\n" +
-	"\x11UploadFileRequest\x12\x1b\n" +
-	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x14\n" +
-	"\x05chunk\x18\x02 \x01(\fR\x05chunk\x12\x16\n" +
-	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x1e\n" +
-	"\n" +
-	"compressed\x18\x04 \x01(\bR\n" +
-	"compressed\"\x97\x01\n" +
-	"\x12UploadFileResponse\x12\x1b\n" +
-	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12%\n" +
-	"\x0ebytes_received\x18\x02 \x01(\x03R\rbytesReceived\x12\x18\n" +
-	"\asuccess\x18\x03 \x01(\bR\asuccess\x12#\n" +
-	"\rerror_message\x18\x04 \x01(\tR\ferrorMessage2\x87\x02\n" +
-	"\x0fTransferService\x12P\n" +
-	"\vGetFileSize\x12\x1f.transfer.v1.GetFileSizeRequest\x1a .transfer.v1.GetFileSizeResponse\x12O\n" +
-	"\n" +
-	"StreamFile\x12\x1e.transfer.v1.StreamFileRequest\x1a\x1f.transfer.v1.StreamFileResponse0\x01\x12Q\n" +
+type ListFilesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Files         []*FileEntry           `protobuf:"bytes,1,rep,name=files,proto3" json:"files,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListFilesResponse) Reset() {
+	*x = ListFilesResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListFilesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListFilesResponse) ProtoMessage() {}
+
+func (x *ListFilesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListFilesResponse.ProtoReflect.Descriptor instead.
+func (*ListFilesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListFilesResponse) GetFiles() []*FileEntry {
+	if x != nil {
+		return x.Files
+	}
+	return nil
+}
+
+type StreamFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Start         int64                  `protobuf:"varint,2,opt,name=start,proto3" json:"start,omitempty"`
+	ChunkSize     int64                  `protobuf:"varint,3,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"`
+	CanDecompress bool                   `protobuf:"varint,4,opt,name=can_decompress,json=canDecompress,proto3" json:"can_decompress,omitempty"`
+	VersionId     string                 `protobuf:"bytes,5,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"` // empty for the latest version
+	Namespace     string                 `protobuf:"bytes,6,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFileRequest) Reset() {
+	*x = StreamFileRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFileRequest) ProtoMessage() {}
+
+func (x *StreamFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFileRequest.ProtoReflect.Descriptor instead.
+func (*StreamFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *StreamFileRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *StreamFileRequest) GetStart() int64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *StreamFileRequest) GetChunkSize() int64 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *StreamFileRequest) GetCanDecompress() bool {
+	if x != nil {
+		return x.CanDecompress
+	}
+	return false
+}
+
+func (x *StreamFileRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *StreamFileRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type StreamFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Chunk         []byte                 `protobuf:"bytes,1,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	Compressed    bool                   `protobuf:"varint,2,opt,name=compressed,proto3" json:"compressed,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamFileResponse) Reset() {
+	*x = StreamFileResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamFileResponse) ProtoMessage() {}
+
+func (x *StreamFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamFileResponse.ProtoReflect.Descriptor instead.
+func (*StreamFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *StreamFileResponse) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *StreamFileResponse) GetCompressed() bool {
+	if x != nil {
+		return x.Compressed
+	}
+	return false
+}
+
+func (x *StreamFileResponse) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type UploadFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Chunk         []byte                 `protobuf:"bytes,2,opt,name=chunk,proto3" json:"chunk,omitempty"`
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	Compressed    bool                   `protobuf:"varint,4,opt,name=compressed,proto3" json:"compressed,omitempty"`
+	Metadata      map[string]string      `protobuf:"bytes,5,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // only read from the first message
+	Namespace     string                 `protobuf:"bytes,6,opt,name=namespace,proto3" json:"namespace,omitempty"`                                                                         // only read from the first message
+	Tags          map[string]string      `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`         // only read from the first message
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFileRequest) Reset() {
+	*x = UploadFileRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileRequest) ProtoMessage() {}
+
+func (x *UploadFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileRequest.ProtoReflect.Descriptor instead.
+func (*UploadFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UploadFileRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetChunk() []byte {
+	if x != nil {
+		return x.Chunk
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *UploadFileRequest) GetCompressed() bool {
+	if x != nil {
+		return x.Compressed
+	}
+	return false
+}
+
+func (x *UploadFileRequest) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *UploadFileRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *UploadFileRequest) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type UploadFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	BytesReceived int64                  `protobuf:"varint,2,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"` // server can send periodic progress updates
+	Success       bool                   `protobuf:"varint,3,opt,name=success,proto3" json:"success,omitempty"`
+	ErrorMessage  string                 `protobuf:"bytes,4,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	VersionId     string                 `protobuf:"bytes,5,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"` // set on the final response in versioned namespaces
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadFileResponse) Reset() {
+	*x = UploadFileResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadFileResponse) ProtoMessage() {}
+
+func (x *UploadFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadFileResponse.ProtoReflect.Descriptor instead.
+func (*UploadFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UploadFileResponse) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *UploadFileResponse) GetBytesReceived() int64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+func (x *UploadFileResponse) GetSuccess() bool {
+	if x != nil {
+		return x.Success
+	}
+	return false
+}
+
+func (x *UploadFileResponse) GetErrorMessage() string {
+	if x != nil {
+		return x.ErrorMessage
+	}
+	return ""
+}
+
+func (x *UploadFileResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type RestoreVersionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	VersionId     string                 `protobuf:"bytes,3,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreVersionRequest) Reset() {
+	*x = RestoreVersionRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreVersionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreVersionRequest) ProtoMessage() {}
+
+func (x *RestoreVersionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreVersionRequest.ProtoReflect.Descriptor instead.
+func (*RestoreVersionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *RestoreVersionRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *RestoreVersionRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RestoreVersionRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type RestoreVersionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"` // the new latest version
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreVersionResponse) Reset() {
+	*x = RestoreVersionResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreVersionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreVersionResponse) ProtoMessage() {}
+
+func (x *RestoreVersionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreVersionResponse.ProtoReflect.Descriptor instead.
+func (*RestoreVersionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *RestoreVersionResponse) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *RestoreVersionResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type TrashEntry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrashId       string                 `protobuf:"bytes,1,opt,name=trash_id,json=trashId,proto3" json:"trash_id,omitempty"`
+	FileName      string                 `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Size          int64                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	DeletedAt     int64                  `protobuf:"varint,4,opt,name=deleted_at,json=deletedAt,proto3" json:"deleted_at,omitempty"` // unix seconds
+	ExpiresAt     int64                  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrashEntry) Reset() {
+	*x = TrashEntry{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrashEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrashEntry) ProtoMessage() {}
+
+func (x *TrashEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrashEntry.ProtoReflect.Descriptor instead.
+func (*TrashEntry) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TrashEntry) GetTrashId() string {
+	if x != nil {
+		return x.TrashId
+	}
+	return ""
+}
+
+func (x *TrashEntry) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *TrashEntry) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *TrashEntry) GetDeletedAt() int64 {
+	if x != nil {
+		return x.DeletedAt
+	}
+	return 0
+}
+
+func (x *TrashEntry) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type DeleteFileRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileRequest) Reset() {
+	*x = DeleteFileRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileRequest) ProtoMessage() {}
+
+func (x *DeleteFileRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileRequest.ProtoReflect.Descriptor instead.
+func (*DeleteFileRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *DeleteFileRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *DeleteFileRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type DeleteFileResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entry         *TrashEntry            `protobuf:"bytes,1,opt,name=entry,proto3" json:"entry,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteFileResponse) Reset() {
+	*x = DeleteFileResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteFileResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteFileResponse) ProtoMessage() {}
+
+func (x *DeleteFileResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteFileResponse.ProtoReflect.Descriptor instead.
+func (*DeleteFileResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DeleteFileResponse) GetEntry() *TrashEntry {
+	if x != nil {
+		return x.Entry
+	}
+	return nil
+}
+
+type ListTrashRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashRequest) Reset() {
+	*x = ListTrashRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashRequest) ProtoMessage() {}
+
+func (x *ListTrashRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashRequest.ProtoReflect.Descriptor instead.
+func (*ListTrashRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListTrashRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type ListTrashResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*TrashEntry          `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"` // most recently deleted first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrashResponse) Reset() {
+	*x = ListTrashResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrashResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrashResponse) ProtoMessage() {}
+
+func (x *ListTrashResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrashResponse.ProtoReflect.Descriptor instead.
+func (*ListTrashResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListTrashResponse) GetEntries() []*TrashEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type UndeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Namespace     string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	TrashId       string                 `protobuf:"bytes,3,opt,name=trash_id,json=trashId,proto3" json:"trash_id,omitempty"` // empty restores the most recent deletion
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteRequest) Reset() {
+	*x = UndeleteRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteRequest) ProtoMessage() {}
+
+func (x *UndeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteRequest.ProtoReflect.Descriptor instead.
+func (*UndeleteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UndeleteRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *UndeleteRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *UndeleteRequest) GetTrashId() string {
+	if x != nil {
+		return x.TrashId
+	}
+	return ""
+}
+
+type UndeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	FileName      string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	VersionId     string                 `protobuf:"bytes,2,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UndeleteResponse) Reset() {
+	*x = UndeleteResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UndeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UndeleteResponse) ProtoMessage() {}
+
+func (x *UndeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UndeleteResponse.ProtoReflect.Descriptor instead.
+func (*UndeleteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UndeleteResponse) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *UndeleteResponse) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+type ShareLink struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Id                string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	FileName          string                 `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	VersionId         string                 `protobuf:"bytes,3,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`           // empty when the link follows the latest version
+	CreatedAt         int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`          // unix seconds
+	ExpiresAt         int64                  `protobuf:"varint,5,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`          // unix seconds
+	MaxDownloads      int64                  `protobuf:"varint,6,opt,name=max_downloads,json=maxDownloads,proto3" json:"max_downloads,omitempty"` // 0 means unlimited
+	Downloads         int64                  `protobuf:"varint,7,opt,name=downloads,proto3" json:"downloads,omitempty"`
+	PasswordProtected bool                   `protobuf:"varint,8,opt,name=password_protected,json=passwordProtected,proto3" json:"password_protected,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ShareLink) Reset() {
+	*x = ShareLink{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShareLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShareLink) ProtoMessage() {}
+
+func (x *ShareLink) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShareLink.ProtoReflect.Descriptor instead.
+func (*ShareLink) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ShareLink) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ShareLink) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *ShareLink) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *ShareLink) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ShareLink) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *ShareLink) GetMaxDownloads() int64 {
+	if x != nil {
+		return x.MaxDownloads
+	}
+	return 0
+}
+
+func (x *ShareLink) GetDownloads() int64 {
+	if x != nil {
+		return x.Downloads
+	}
+	return 0
+}
+
+func (x *ShareLink) GetPasswordProtected() bool {
+	if x != nil {
+		return x.PasswordProtected
+	}
+	return false
+}
+
+type CreateShareLinkRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FileName         string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Namespace        string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	VersionId        string                 `protobuf:"bytes,3,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`
+	ExpiresInSeconds int64                  `protobuf:"varint,4,opt,name=expires_in_seconds,json=expiresInSeconds,proto3" json:"expires_in_seconds,omitempty"` // 0 uses the server default
+	MaxDownloads     int64                  `protobuf:"varint,5,opt,name=max_downloads,json=maxDownloads,proto3" json:"max_downloads,omitempty"`               // 0 means unlimited
+	Password         string                 `protobuf:"bytes,6,opt,name=password,proto3" json:"password,omitempty"`                                            // optional
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *CreateShareLinkRequest) Reset() {
+	*x = CreateShareLinkRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShareLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShareLinkRequest) ProtoMessage() {}
+
+func (x *CreateShareLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShareLinkRequest.ProtoReflect.Descriptor instead.
+func (*CreateShareLinkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateShareLinkRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *CreateShareLinkRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *CreateShareLinkRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *CreateShareLinkRequest) GetExpiresInSeconds() int64 {
+	if x != nil {
+		return x.ExpiresInSeconds
+	}
+	return 0
+}
+
+func (x *CreateShareLinkRequest) GetMaxDownloads() int64 {
+	if x != nil {
+		return x.MaxDownloads
+	}
+	return 0
+}
+
+func (x *CreateShareLinkRequest) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+type CreateShareLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Link          *ShareLink             `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	Token         string                 `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	Url           string                 `protobuf:"bytes,3,opt,name=url,proto3" json:"url,omitempty"` // absolute when the server knows its public URL
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateShareLinkResponse) Reset() {
+	*x = CreateShareLinkResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateShareLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateShareLinkResponse) ProtoMessage() {}
+
+func (x *CreateShareLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateShareLinkResponse.ProtoReflect.Descriptor instead.
+func (*CreateShareLinkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *CreateShareLinkResponse) GetLink() *ShareLink {
+	if x != nil {
+		return x.Link
+	}
+	return nil
+}
+
+func (x *CreateShareLinkResponse) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *CreateShareLinkResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+type ListShareLinksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShareLinksRequest) Reset() {
+	*x = ListShareLinksRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShareLinksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShareLinksRequest) ProtoMessage() {}
+
+func (x *ListShareLinksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShareLinksRequest.ProtoReflect.Descriptor instead.
+func (*ListShareLinksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListShareLinksRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+type ListShareLinksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Links         []*ShareLink           `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"` // active links, most recently created first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListShareLinksResponse) Reset() {
+	*x = ListShareLinksResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListShareLinksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListShareLinksResponse) ProtoMessage() {}
+
+func (x *ListShareLinksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListShareLinksResponse.ProtoReflect.Descriptor instead.
+func (*ListShareLinksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ListShareLinksResponse) GetLinks() []*ShareLink {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+type RevokeShareLinkRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Namespace     string                 `protobuf:"bytes,1,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Id            string                 `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareLinkRequest) Reset() {
+	*x = RevokeShareLinkRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareLinkRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareLinkRequest) ProtoMessage() {}
+
+func (x *RevokeShareLinkRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareLinkRequest.ProtoReflect.Descriptor instead.
+func (*RevokeShareLinkRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *RevokeShareLinkRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *RevokeShareLinkRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type RevokeShareLinkResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeShareLinkResponse) Reset() {
+	*x = RevokeShareLinkResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeShareLinkResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeShareLinkResponse) ProtoMessage() {}
+
+func (x *RevokeShareLinkResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeShareLinkResponse.ProtoReflect.Descriptor instead.
+func (*RevokeShareLinkResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{27}
+}
+
+type GetPresignedURLRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	FileName         string                 `protobuf:"bytes,1,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	Namespace        string                 `protobuf:"bytes,2,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Method           PresignMethod          `protobuf:"varint,3,opt,name=method,proto3,enum=transfer.v1.PresignMethod" json:"method,omitempty"`
+	ExpiresInSeconds int64                  `protobuf:"varint,4,opt,name=expires_in_seconds,json=expiresInSeconds,proto3" json:"expires_in_seconds,omitempty"` // 0 uses the server default
+	VersionId        string                 `protobuf:"bytes,5,opt,name=version_id,json=versionId,proto3" json:"version_id,omitempty"`                         // GET only
+	VerifyCompletion bool                   `protobuf:"varint,6,opt,name=verify_completion,json=verifyCompletion,proto3" json:"verify_completion,omitempty"`   // PUT only; the server audits whether the upload arrived
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *GetPresignedURLRequest) Reset() {
+	*x = GetPresignedURLRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPresignedURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPresignedURLRequest) ProtoMessage() {}
+
+func (x *GetPresignedURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPresignedURLRequest.ProtoReflect.Descriptor instead.
+func (*GetPresignedURLRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *GetPresignedURLRequest) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *GetPresignedURLRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *GetPresignedURLRequest) GetMethod() PresignMethod {
+	if x != nil {
+		return x.Method
+	}
+	return PresignMethod_PRESIGN_METHOD_UNSPECIFIED
+}
+
+func (x *GetPresignedURLRequest) GetExpiresInSeconds() int64 {
+	if x != nil {
+		return x.ExpiresInSeconds
+	}
+	return 0
+}
+
+func (x *GetPresignedURLRequest) GetVersionId() string {
+	if x != nil {
+		return x.VersionId
+	}
+	return ""
+}
+
+func (x *GetPresignedURLRequest) GetVerifyCompletion() bool {
+	if x != nil {
+		return x.VerifyCompletion
+	}
+	return false
+}
+
+type GetPresignedURLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	Method        PresignMethod          `protobuf:"varint,2,opt,name=method,proto3,enum=transfer.v1.PresignMethod" json:"method,omitempty"`
+	ExpiresAt     int64                  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"` // unix seconds
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPresignedURLResponse) Reset() {
+	*x = GetPresignedURLResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPresignedURLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPresignedURLResponse) ProtoMessage() {}
+
+func (x *GetPresignedURLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPresignedURLResponse.ProtoReflect.Descriptor instead.
+func (*GetPresignedURLResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetPresignedURLResponse) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *GetPresignedURLResponse) GetMethod() PresignMethod {
+	if x != nil {
+		return x.Method
+	}
+	return PresignMethod_PRESIGN_METHOD_UNSPECIFIED
+}
+
+func (x *GetPresignedURLResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type CheckAccessRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Principal     string                 `protobuf:"bytes,1,opt,name=principal,proto3" json:"principal,omitempty"` // empty checks the caller
+	Groups        []string               `protobuf:"bytes,2,rep,name=groups,proto3" json:"groups,omitempty"`       // groups carried by the principal's credentials
+	Namespace     string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	Path          string                 `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`
+	Action        string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"` // read, write, delete, list or admin
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckAccessRequest) Reset() {
+	*x = CheckAccessRequest{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckAccessRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckAccessRequest) ProtoMessage() {}
+
+func (x *CheckAccessRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckAccessRequest.ProtoReflect.Descriptor instead.
+func (*CheckAccessRequest) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CheckAccessRequest) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *CheckAccessRequest) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+func (x *CheckAccessRequest) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *CheckAccessRequest) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *CheckAccessRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+type CheckAccessResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Allowed       bool                   `protobuf:"varint,1,opt,name=allowed,proto3" json:"allowed,omitempty"`
+	RuleId        string                 `protobuf:"bytes,2,opt,name=rule_id,json=ruleId,proto3" json:"rule_id,omitempty"` // empty when the policy default applied
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Principal     string                 `protobuf:"bytes,4,opt,name=principal,proto3" json:"principal,omitempty"`
+	Groups        []string               `protobuf:"bytes,5,rep,name=groups,proto3" json:"groups,omitempty"` // every group the principal was evaluated with
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckAccessResponse) Reset() {
+	*x = CheckAccessResponse{}
+	mi := &file_proto_v1_transfer_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckAccessResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckAccessResponse) ProtoMessage() {}
+
+func (x *CheckAccessResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_v1_transfer_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckAccessResponse.ProtoReflect.Descriptor instead.
+func (*CheckAccessResponse) Descriptor() ([]byte, []int) {
+	return file_proto_v1_transfer_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *CheckAccessResponse) GetAllowed() bool {
+	if x != nil {
+		return x.Allowed
+	}
+	return false
+}
+
+func (x *CheckAccessResponse) GetRuleId() string {
+	if x != nil {
+		return x.RuleId
+	}
+	return ""
+}
+
+func (x *CheckAccessResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CheckAccessResponse) GetPrincipal() string {
+	if x != nil {
+		return x.Principal
+	}
+	return ""
+}
+
+func (x *CheckAccessResponse) GetGroups() []string {
+	if x != nil {
+		return x.Groups
+	}
+	return nil
+}
+
+var File_proto_v1_transfer_proto protoreflect.FileDescriptor
+
+const file_proto_v1_transfer_proto_rawDesc = "" +
+	"\n" +
+	"\x17proto/v1/transfer.proto\x12\vtransfer.v1\"O\n" +
+	"\x12GetFileSizeRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\")\n" +
+	"\x13GetFileSizeResponse\x12\x12\n" +
+	"\x04size\x18\x01 \x01(\x03R\x04size\"\xc0\x01\n" +
+	"\vFileVersion\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x01 \x01(\tR\tversionId\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04etag\x18\x03 \x01(\tR\x04etag\x12#\n" +
+	"\rlast_modified\x18\x04 \x01(\x03R\flastModified\x12\x1b\n" +
+	"\tis_latest\x18\x05 \x01(\bR\bisLatest\x12(\n" +
+	"\x10is_delete_marker\x18\x06 \x01(\bR\x0eisDeleteMarker\"\x99\x01\n" +
+	"\x12GetFileInfoRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x03 \x01(\tR\tversionId\x12)\n" +
+	"\x10include_versions\x18\x04 \x01(\bR\x0fincludeVersions\"\x80\x03\n" +
+	"\x13GetFileInfoResponse\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04etag\x18\x03 \x01(\tR\x04etag\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12#\n" +
+	"\rlast_modified\x18\x05 \x01(\x03R\flastModified\x12J\n" +
+	"\bmetadata\x18\x06 \x03(\v2..transfer.v1.GetFileInfoResponse.MetadataEntryR\bmetadata\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\a \x01(\tR\tversionId\x124\n" +
+	"\bversions\x18\b \x03(\v2\x18.transfer.v1.FileVersionR\bversions\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"s\n" +
+	"\x10ListFilesRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x16\n" +
+	"\x06prefix\x18\x02 \x01(\tR\x06prefix\x12)\n" +
+	"\x10include_versions\x18\x03 \x01(\bR\x0fincludeVersions\"\xca\x01\n" +
+	"\tFileEntry\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x12\n" +
+	"\x04size\x18\x02 \x01(\x03R\x04size\x12\x12\n" +
+	"\x04etag\x18\x03 \x01(\tR\x04etag\x12#\n" +
+	"\rlast_modified\x18\x04 \x01(\x03R\flastModified\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x05 \x01(\tR\tversionId\x124\n" +
+	"\bversions\x18\x06 \x03(\v2\x18.transfer.v1.FileVersionR\bversions\"A\n" +
+	"\x11ListFilesResponse\x12,\n" +
+	"\x05files\x18\x01 \x03(\v2\x16.transfer.v1.FileEntryR\x05files\"\xc9\x01\n" +
+	"\x11StreamFileRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x03R\x05start\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x03 \x01(\x03R\tchunkSize\x12%\n" +
+	"\x0ecan_decompress\x18\x04 \x01(\bR\rcanDecompress\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x05 \x01(\tR\tversionId\x12\x1c\n" +
+	"\tnamespace\x18\x06 \x01(\tR\tnamespace\"b\n" +
+	"\x12StreamFileResponse\x12\x14\n" +
+	"\x05chunk\x18\x01 \x01(\fR\x05chunk\x12\x1e\n" +
+	"\n" +
+	"compressed\x18\x02 \x01(\bR\n" +
+	"compressed\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\"\x9a\x03\n" +
+	"\x11UploadFileRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x14\n" +
+	"\x05chunk\x18\x02 \x01(\fR\x05chunk\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x03R\x06offset\x12\x1e\n" +
+	"\n" +
+	"compressed\x18\x04 \x01(\bR\n" +
+	"compressed\x12H\n" +
+	"\bmetadata\x18\x05 \x03(\v2,.transfer.v1.UploadFileRequest.MetadataEntryR\bmetadata\x12\x1c\n" +
+	"\tnamespace\x18\x06 \x01(\tR\tnamespace\x12<\n" +
+	"\x04tags\x18\a \x03(\v2(.transfer.v1.UploadFileRequest.TagsEntryR\x04tags\x1a;\n" +
+	"\rMetadataEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xb6\x01\n" +
+	"\x12UploadFileResponse\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12%\n" +
+	"\x0ebytes_received\x18\x02 \x01(\x03R\rbytesReceived\x12\x18\n" +
+	"\asuccess\x18\x03 \x01(\bR\asuccess\x12#\n" +
+	"\rerror_message\x18\x04 \x01(\tR\ferrorMessage\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x05 \x01(\tR\tversionId\"q\n" +
+	"\x15RestoreVersionRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x03 \x01(\tR\tversionId\"T\n" +
+	"\x16RestoreVersionResponse\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\"\x96\x01\n" +
+	"\n" +
+	"TrashEntry\x12\x19\n" +
+	"\btrash_id\x18\x01 \x01(\tR\atrashId\x12\x1b\n" +
+	"\tfile_name\x18\x02 \x01(\tR\bfileName\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x03R\x04size\x12\x1d\n" +
+	"\n" +
+	"deleted_at\x18\x04 \x01(\x03R\tdeletedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\"N\n" +
+	"\x11DeleteFileRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\"C\n" +
+	"\x12DeleteFileResponse\x12-\n" +
+	"\x05entry\x18\x01 \x01(\v2\x17.transfer.v1.TrashEntryR\x05entry\"0\n" +
+	"\x10ListTrashRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\"F\n" +
+	"\x11ListTrashResponse\x121\n" +
+	"\aentries\x18\x01 \x03(\v2\x17.transfer.v1.TrashEntryR\aentries\"g\n" +
+	"\x0fUndeleteRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x19\n" +
+	"\btrash_id\x18\x03 \x01(\tR\atrashId\"N\n" +
+	"\x10UndeleteResponse\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x02 \x01(\tR\tversionId\"\x87\x02\n" +
+	"\tShareLink\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tfile_name\x18\x02 \x01(\tR\bfileName\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x03 \x01(\tR\tversionId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x05 \x01(\x03R\texpiresAt\x12#\n" +
+	"\rmax_downloads\x18\x06 \x01(\x03R\fmaxDownloads\x12\x1c\n" +
+	"\tdownloads\x18\a \x01(\x03R\tdownloads\x12-\n" +
+	"\x12password_protected\x18\b \x01(\bR\x11passwordProtected\"\xe1\x01\n" +
+	"\x16CreateShareLinkRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x03 \x01(\tR\tversionId\x12,\n" +
+	"\x12expires_in_seconds\x18\x04 \x01(\x03R\x10expiresInSeconds\x12#\n" +
+	"\rmax_downloads\x18\x05 \x01(\x03R\fmaxDownloads\x12\x1a\n" +
+	"\bpassword\x18\x06 \x01(\tR\bpassword\"m\n" +
+	"\x17CreateShareLinkResponse\x12*\n" +
+	"\x04link\x18\x01 \x01(\v2\x16.transfer.v1.ShareLinkR\x04link\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x10\n" +
+	"\x03url\x18\x03 \x01(\tR\x03url\"5\n" +
+	"\x15ListShareLinksRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\"F\n" +
+	"\x16ListShareLinksResponse\x12,\n" +
+	"\x05links\x18\x01 \x03(\v2\x16.transfer.v1.ShareLinkR\x05links\"F\n" +
+	"\x16RevokeShareLinkRequest\x12\x1c\n" +
+	"\tnamespace\x18\x01 \x01(\tR\tnamespace\x12\x0e\n" +
+	"\x02id\x18\x02 \x01(\tR\x02id\"\x19\n" +
+	"\x17RevokeShareLinkResponse\"\x81\x02\n" +
+	"\x16GetPresignedURLRequest\x12\x1b\n" +
+	"\tfile_name\x18\x01 \x01(\tR\bfileName\x12\x1c\n" +
+	"\tnamespace\x18\x02 \x01(\tR\tnamespace\x122\n" +
+	"\x06method\x18\x03 \x01(\x0e2\x1a.transfer.v1.PresignMethodR\x06method\x12,\n" +
+	"\x12expires_in_seconds\x18\x04 \x01(\x03R\x10expiresInSeconds\x12\x1d\n" +
+	"\n" +
+	"version_id\x18\x05 \x01(\tR\tversionId\x12+\n" +
+	"\x11verify_completion\x18\x06 \x01(\bR\x10verifyCompletion\"~\n" +
+	"\x17GetPresignedURLResponse\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x122\n" +
+	"\x06method\x18\x02 \x01(\x0e2\x1a.transfer.v1.PresignMethodR\x06method\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"\x94\x01\n" +
+	"\x12CheckAccessRequest\x12\x1c\n" +
+	"\tprincipal\x18\x01 \x01(\tR\tprincipal\x12\x16\n" +
+	"\x06groups\x18\x02 \x03(\tR\x06groups\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12\x12\n" +
+	"\x04path\x18\x04 \x01(\tR\x04path\x12\x16\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\"\x96\x01\n" +
+	"\x13CheckAccessResponse\x12\x18\n" +
+	"\aallowed\x18\x01 \x01(\bR\aallowed\x12\x17\n" +
+	"\arule_id\x18\x02 \x01(\tR\x06ruleId\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1c\n" +
+	"\tprincipal\x18\x04 \x01(\tR\tprincipal\x12\x16\n" +
+	"\x06groups\x18\x05 \x03(\tR\x06groups*_\n" +
+	"\rPresignMethod\x12\x1e\n" +
+	"\x1aPRESIGN_METHOD_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12PRESIGN_METHOD_GET\x10\x01\x12\x16\n" +
+	"\x12PRESIGN_METHOD_PUT\x10\x022\xab\t\n" +
+	"\x0fTransferService\x12P\n" +
+	"\vGetFileSize\x12\x1f.transfer.v1.GetFileSizeRequest\x1a .transfer.v1.GetFileSizeResponse\x12P\n" +
+	"\vGetFileInfo\x12\x1f.transfer.v1.GetFileInfoRequest\x1a .transfer.v1.GetFileInfoResponse\x12J\n" +
+	"\tListFiles\x12\x1d.transfer.v1.ListFilesRequest\x1a\x1e.transfer.v1.ListFilesResponse\x12O\n" +
+	"\n" +
+	"StreamFile\x12\x1e.transfer.v1.StreamFileRequest\x1a\x1f.transfer.v1.StreamFileResponse0\x01\x12Q\n" +
+	"\n" +
+	"UploadFile\x12\x1e.transfer.v1.UploadFileRequest\x1a\x1f.transfer.v1.UploadFileResponse(\x010\x01\x12Y\n" +
+	"\x0eRestoreVersion\x12\".transfer.v1.RestoreVersionRequest\x1a#.transfer.v1.RestoreVersionResponse\x12M\n" +
 	"\n" +
-	"UploadFile\x12\x1e.transfer.v1.UploadFileRequest\x1a\x1f.transfer.v1.UploadFileResponse(\x010\x01B\xb2\x01\n" +
+	"DeleteFile\x12\x1e.transfer.v1.DeleteFileRequest\x1a\x1f.transfer.v1.DeleteFileResponse\x12J\n" +
+	"\tListTrash\x12\x1d.transfer.v1.ListTrashRequest\x1a\x1e.transfer.v1.ListTrashResponse\x12G\n" +
+	"\bUndelete\x12\x1c.transfer.v1.UndeleteRequest\x1a\x1d.transfer.v1.UndeleteResponse\x12\\\n" +
+	"\x0fCreateShareLink\x12#.transfer.v1.CreateShareLinkRequest\x1a$.transfer.v1.CreateShareLinkResponse\x12Y\n" +
+	"\x0eListShareLinks\x12\".transfer.v1.ListShareLinksRequest\x1a#.transfer.v1.ListShareLinksResponse\x12\\\n" +
+	"\x0fRevokeShareLink\x12#.transfer.v1.RevokeShareLinkRequest\x1a$.transfer.v1.RevokeShareLinkResponse\x12\\\n" +
+	"\x0fGetPresignedURL\x12#.transfer.v1.GetPresignedURLRequest\x1a$.transfer.v1.GetPresignedURLResponse\x12P\n" +
+	"\vCheckAccess\x12\x1f.transfer.v1.CheckAccessRequest\x1a .transfer.v1.CheckAccessResponseB\xb2\x01\n" +
 	"\x0fcom.transfer.v1B\rTransferProtoP\x01ZCgithub.com/gilwong00/file-streamer/internal/gen/proto/v1;transferv1\xa2\x02\x03TXX\xaa\x02\vTransfer.V1\xca\x02\vTransfer\\V1\xe2\x02\x17Transfer\\V1\\GPBMetadata\xea\x02\fTransfer::V1b\x06proto3"
 
 var (
@@ -426,27 +2335,92 @@ func file_proto_v1_transfer_proto_rawDescGZIP() []byte {
 	return file_proto_v1_transfer_proto_rawDescData
 }
 
-var file_proto_v1_transfer_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
+var file_proto_v1_transfer_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_proto_v1_transfer_proto_msgTypes = make([]protoimpl.MessageInfo, 35)
 var file_proto_v1_transfer_proto_goTypes = []any{
-	(*GetFileSizeRequest)(nil),  // 0: transfer.v1.GetFileSizeRequest
-	(*GetFileSizeResponse)(nil), // 1: transfer.v1.GetFileSizeResponse
-	(*StreamFileRequest)(nil),   // 2: transfer.v1.StreamFileRequest
-	(*StreamFileResponse)(nil),  // 3: transfer.v1.StreamFileResponse
-	(*UploadFileRequest)(nil),   // 4: transfer.v1.UploadFileRequest
-	(*UploadFileResponse)(nil),  // 5: transfer.v1.UploadFileResponse
+	(PresignMethod)(0),              // 0: transfer.v1.PresignMethod
+	(*GetFileSizeRequest)(nil),      // 1: transfer.v1.GetFileSizeRequest
+	(*GetFileSizeResponse)(nil),     // 2: transfer.v1.GetFileSizeResponse
+	(*FileVersion)(nil),             // 3: transfer.v1.FileVersion
+	(*GetFileInfoRequest)(nil),      // 4: transfer.v1.GetFileInfoRequest
+	(*GetFileInfoResponse)(nil),     // 5: transfer.v1.GetFileInfoResponse
+	(*ListFilesRequest)(nil),        // 6: transfer.v1.ListFilesRequest
+	(*FileEntry)(nil),               // 7: transfer.v1.FileEntry
+	(*ListFilesResponse)(nil),       // 8: transfer.v1.ListFilesResponse
+	(*StreamFileRequest)(nil),       // 9: transfer.v1.StreamFileRequest
+	(*StreamFileResponse)(nil),      // 10: transfer.v1.StreamFileResponse
+	(*UploadFileRequest)(nil),       // 11: transfer.v1.UploadFileRequest
+	(*UploadFileResponse)(nil),      // 12: transfer.v1.UploadFileResponse
+	(*RestoreVersionRequest)(nil),   // 13: transfer.v1.RestoreVersionRequest
+	(*RestoreVersionResponse)(nil),  // 14: transfer.v1.RestoreVersionResponse
+	(*TrashEntry)(nil),              // 15: transfer.v1.TrashEntry
+	(*DeleteFileRequest)(nil),       // 16: transfer.v1.DeleteFileRequest
+	(*DeleteFileResponse)(nil),      // 17: transfer.v1.DeleteFileResponse
+	(*ListTrashRequest)(nil),        // 18: transfer.v1.ListTrashRequest
+	(*ListTrashResponse)(nil),       // 19: transfer.v1.ListTrashResponse
+	(*UndeleteRequest)(nil),         // 20: transfer.v1.UndeleteRequest
+	(*UndeleteResponse)(nil),        // 21: transfer.v1.UndeleteResponse
+	(*ShareLink)(nil),               // 22: transfer.v1.ShareLink
+	(*CreateShareLinkRequest)(nil),  // 23: transfer.v1.CreateShareLinkRequest
+	(*CreateShareLinkResponse)(nil), // 24: transfer.v1.CreateShareLinkResponse
+	(*ListShareLinksRequest)(nil),   // 25: transfer.v1.ListShareLinksRequest
+	(*ListShareLinksResponse)(nil),  // 26: transfer.v1.ListShareLinksResponse
+	(*RevokeShareLinkRequest)(nil),  // 27: transfer.v1.RevokeShareLinkRequest
+	(*RevokeShareLinkResponse)(nil), // 28: transfer.v1.RevokeShareLinkResponse
+	(*GetPresignedURLRequest)(nil),  // 29: transfer.v1.GetPresignedURLRequest
+	(*GetPresignedURLResponse)(nil), // 30: transfer.v1.GetPresignedURLResponse
+	(*CheckAccessRequest)(nil),      // 31: transfer.v1.CheckAccessRequest
+	(*CheckAccessResponse)(nil),     // 32: transfer.v1.CheckAccessResponse
+	nil,                             // 33: transfer.v1.GetFileInfoResponse.MetadataEntry
+	nil,                             // 34: transfer.v1.UploadFileRequest.MetadataEntry
+	nil,                             // 35: transfer.v1.UploadFileRequest.TagsEntry
 }
 var file_proto_v1_transfer_proto_depIdxs = []int32{
-	0, // 0: transfer.v1.TransferService.GetFileSize:input_type -> transfer.v1.GetFileSizeRequest
-	2, // 1: transfer.v1.TransferService.StreamFile:input_type -> transfer.v1.StreamFileRequest
-	4, // 2: transfer.v1.TransferService.UploadFile:input_type -> transfer.v1.UploadFileRequest
-	1, // 3: transfer.v1.TransferService.GetFileSize:output_type -> transfer.v1.GetFileSizeResponse
-	3, // 4: transfer.v1.TransferService.StreamFile:output_type -> transfer.v1.StreamFileResponse
-	5, // 5: transfer.v1.TransferService.UploadFile:output_type -> transfer.v1.UploadFileResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	33, // 0: transfer.v1.GetFileInfoResponse.metadata:type_name -> transfer.v1.GetFileInfoResponse.MetadataEntry
+	3,  // 1: transfer.v1.GetFileInfoResponse.versions:type_name -> transfer.v1.FileVersion
+	3,  // 2: transfer.v1.FileEntry.versions:type_name -> transfer.v1.FileVersion
+	7,  // 3: transfer.v1.ListFilesResponse.files:type_name -> transfer.v1.FileEntry
+	34, // 4: transfer.v1.UploadFileRequest.metadata:type_name -> transfer.v1.UploadFileRequest.MetadataEntry
+	35, // 5: transfer.v1.UploadFileRequest.tags:type_name -> transfer.v1.UploadFileRequest.TagsEntry
+	15, // 6: transfer.v1.DeleteFileResponse.entry:type_name -> transfer.v1.TrashEntry
+	15, // 7: transfer.v1.ListTrashResponse.entries:type_name -> transfer.v1.TrashEntry
+	22, // 8: transfer.v1.CreateShareLinkResponse.link:type_name -> transfer.v1.ShareLink
+	22, // 9: transfer.v1.ListShareLinksResponse.links:type_name -> transfer.v1.ShareLink
+	0,  // 10: transfer.v1.GetPresignedURLRequest.method:type_name -> transfer.v1.PresignMethod
+	0,  // 11: transfer.v1.GetPresignedURLResponse.method:type_name -> transfer.v1.PresignMethod
+	1,  // 12: transfer.v1.TransferService.GetFileSize:input_type -> transfer.v1.GetFileSizeRequest
+	4,  // 13: transfer.v1.TransferService.GetFileInfo:input_type -> transfer.v1.GetFileInfoRequest
+	6,  // 14: transfer.v1.TransferService.ListFiles:input_type -> transfer.v1.ListFilesRequest
+	9,  // 15: transfer.v1.TransferService.StreamFile:input_type -> transfer.v1.StreamFileRequest
+	11, // 16: transfer.v1.TransferService.UploadFile:input_type -> transfer.v1.UploadFileRequest
+	13, // 17: transfer.v1.TransferService.RestoreVersion:input_type -> transfer.v1.RestoreVersionRequest
+	16, // 18: transfer.v1.TransferService.DeleteFile:input_type -> transfer.v1.DeleteFileRequest
+	18, // 19: transfer.v1.TransferService.ListTrash:input_type -> transfer.v1.ListTrashRequest
+	20, // 20: transfer.v1.TransferService.Undelete:input_type -> transfer.v1.UndeleteRequest
+	23, // 21: transfer.v1.TransferService.CreateShareLink:input_type -> transfer.v1.CreateShareLinkRequest
+	25, // 22: transfer.v1.TransferService.ListShareLinks:input_type -> transfer.v1.ListShareLinksRequest
+	27, // 23: transfer.v1.TransferService.RevokeShareLink:input_type -> transfer.v1.RevokeShareLinkRequest
+	29, // 24: transfer.v1.TransferService.GetPresignedURL:input_type -> transfer.v1.GetPresignedURLRequest
+	31, // 25: transfer.v1.TransferService.CheckAccess:input_type -> transfer.v1.CheckAccessRequest
+	2,  // 26: transfer.v1.TransferService.GetFileSize:output_type -> transfer.v1.GetFileSizeResponse
+	5,  // 27: transfer.v1.TransferService.GetFileInfo:output_type -> transfer.v1.GetFileInfoResponse
+	8,  // 28: transfer.v1.TransferService.ListFiles:output_type -> transfer.v1.ListFilesResponse
+	10, // 29: transfer.v1.TransferService.StreamFile:output_type -> transfer.v1.StreamFileResponse
+	12, // 30: transfer.v1.TransferService.UploadFile:output_type -> transfer.v1.UploadFileResponse
+	14, // 31: transfer.v1.TransferService.RestoreVersion:output_type -> transfer.v1.RestoreVersionResponse
+	17, // 32: transfer.v1.TransferService.DeleteFile:output_type -> transfer.v1.DeleteFileResponse
+	19, // 33: transfer.v1.TransferService.ListTrash:output_type -> transfer.v1.ListTrashResponse
+	21, // 34: transfer.v1.TransferService.Undelete:output_type -> transfer.v1.UndeleteResponse
+	24, // 35: transfer.v1.TransferService.CreateShareLink:output_type -> transfer.v1.CreateShareLinkResponse
+	26, // 36: transfer.v1.TransferService.ListShareLinks:output_type -> transfer.v1.ListShareLinksResponse
+	28, // 37: transfer.v1.TransferService.RevokeShareLink:output_type -> transfer.v1.RevokeShareLinkResponse
+	30, // 38: transfer.v1.TransferService.GetPresignedURL:output_type -> transfer.v1.GetPresignedURLResponse
+	32, // 39: transfer.v1.TransferService.CheckAccess:output_type -> transfer.v1.CheckAccessResponse
+	26, // [26:40] is the sub-list for method output_type
+	12, // [12:26] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_proto_v1_transfer_proto_init() }
@@ -459,13 +2433,14 @@ func file_proto_v1_transfer_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_v1_transfer_proto_rawDesc), len(file_proto_v1_transfer_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   6,
+			NumEnums:      1,
+			NumMessages:   35,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_v1_transfer_proto_goTypes,
 		DependencyIndexes: file_proto_v1_transfer_proto_depIdxs,
+		EnumInfos:         file_proto_v1_transfer_proto_enumTypes,
 		MessageInfos:      file_proto_v1_transfer_proto_msgTypes,
 	}.Build()
 	File_proto_v1_transfer_proto = out.File
@@ -36,20 +36,74 @@ const (
 	// TransferServiceGetFileSizeProcedure is the fully-qualified name of the TransferService's
 	// GetFileSize RPC.
 	TransferServiceGetFileSizeProcedure = "/transfer.v1.TransferService/GetFileSize"
+	// TransferServiceGetFileInfoProcedure is the fully-qualified name of the TransferService's
+	// GetFileInfo RPC.
+	TransferServiceGetFileInfoProcedure = "/transfer.v1.TransferService/GetFileInfo"
+	// TransferServiceListFilesProcedure is the fully-qualified name of the TransferService's ListFiles
+	// RPC.
+	TransferServiceListFilesProcedure = "/transfer.v1.TransferService/ListFiles"
 	// TransferServiceStreamFileProcedure is the fully-qualified name of the TransferService's
 	// StreamFile RPC.
 	TransferServiceStreamFileProcedure = "/transfer.v1.TransferService/StreamFile"
 	// TransferServiceUploadFileProcedure is the fully-qualified name of the TransferService's
 	// UploadFile RPC.
 	TransferServiceUploadFileProcedure = "/transfer.v1.TransferService/UploadFile"
+	// TransferServiceRestoreVersionProcedure is the fully-qualified name of the TransferService's
+	// RestoreVersion RPC.
+	TransferServiceRestoreVersionProcedure = "/transfer.v1.TransferService/RestoreVersion"
+	// TransferServiceDeleteFileProcedure is the fully-qualified name of the TransferService's
+	// DeleteFile RPC.
+	TransferServiceDeleteFileProcedure = "/transfer.v1.TransferService/DeleteFile"
+	// TransferServiceListTrashProcedure is the fully-qualified name of the TransferService's ListTrash
+	// RPC.
+	TransferServiceListTrashProcedure = "/transfer.v1.TransferService/ListTrash"
+	// TransferServiceUndeleteProcedure is the fully-qualified name of the TransferService's Undelete
+	// RPC.
+	TransferServiceUndeleteProcedure = "/transfer.v1.TransferService/Undelete"
+	// TransferServiceCreateShareLinkProcedure is the fully-qualified name of the TransferService's
+	// CreateShareLink RPC.
+	TransferServiceCreateShareLinkProcedure = "/transfer.v1.TransferService/CreateShareLink"
+	// TransferServiceListShareLinksProcedure is the fully-qualified name of the TransferService's
+	// ListShareLinks RPC.
+	TransferServiceListShareLinksProcedure = "/transfer.v1.TransferService/ListShareLinks"
+	// TransferServiceRevokeShareLinkProcedure is the fully-qualified name of the TransferService's
+	// RevokeShareLink RPC.
+	TransferServiceRevokeShareLinkProcedure = "/transfer.v1.TransferService/RevokeShareLink"
+	// TransferServiceGetPresignedURLProcedure is the fully-qualified name of the TransferService's
+	// GetPresignedURL RPC.
+	TransferServiceGetPresignedURLProcedure = "/transfer.v1.TransferService/GetPresignedURL"
+	// TransferServiceCheckAccessProcedure is the fully-qualified name of the TransferService's
+	// CheckAccess RPC.
+	TransferServiceCheckAccessProcedure = "/transfer.v1.TransferService/CheckAccess"
 )
 
 // TransferServiceClient is a client for the transfer.v1.TransferService service.
 type TransferServiceClient interface {
 	GetFileSize(context.Context, *connect.Request[v1.GetFileSizeRequest]) (*connect.Response[v1.GetFileSizeResponse], error)
+	GetFileInfo(context.Context, *connect.Request[v1.GetFileInfoRequest]) (*connect.Response[v1.GetFileInfoResponse], error)
+	ListFiles(context.Context, *connect.Request[v1.ListFilesRequest]) (*connect.Response[v1.ListFilesResponse], error)
 	StreamFile(context.Context, *connect.Request[v1.StreamFileRequest]) (*connect.ServerStreamForClient[v1.StreamFileResponse], error)
 	// Bi-directional streaming for uploads
 	UploadFile(context.Context) *connect.BidiStreamForClient[v1.UploadFileRequest, v1.UploadFileResponse]
+	// Promotes an old version of a file in a versioned namespace to be the latest
+	RestoreVersion(context.Context, *connect.Request[v1.RestoreVersionRequest]) (*connect.Response[v1.RestoreVersionResponse], error)
+	// Moves a file into its namespace's trash, from which it can be undeleted
+	// until the retention window passes
+	DeleteFile(context.Context, *connect.Request[v1.DeleteFileRequest]) (*connect.Response[v1.DeleteFileResponse], error)
+	ListTrash(context.Context, *connect.Request[v1.ListTrashRequest]) (*connect.Response[v1.ListTrashResponse], error)
+	Undelete(context.Context, *connect.Request[v1.UndeleteRequest]) (*connect.Response[v1.UndeleteResponse], error)
+	// Share links grant credential-free downloads of a single file through
+	// the HTTP server's /s/{token} endpoint.
+	CreateShareLink(context.Context, *connect.Request[v1.CreateShareLinkRequest]) (*connect.Response[v1.CreateShareLinkResponse], error)
+	ListShareLinks(context.Context, *connect.Request[v1.ListShareLinksRequest]) (*connect.Response[v1.ListShareLinksResponse], error)
+	RevokeShareLink(context.Context, *connect.Request[v1.RevokeShareLinkRequest]) (*connect.Response[v1.RevokeShareLinkResponse], error)
+	// GetPresignedURL returns a time-limited URL for transferring a file
+	// directly to or from the storage backend.
+	GetPresignedURL(context.Context, *connect.Request[v1.GetPresignedURLRequest]) (*connect.Response[v1.GetPresignedURLResponse], error)
+	// CheckAccess explains whether a principal may perform an action. Checking
+	// another principal than the caller, or the caller with other groups than
+	// its own, requires the admin action.
+	CheckAccess(context.Context, *connect.Request[v1.CheckAccessRequest]) (*connect.Response[v1.CheckAccessResponse], error)
 }
 
 // NewTransferServiceClient constructs a client for the transfer.v1.TransferService service. By
@@ -69,6 +123,18 @@ func NewTransferServiceClient(httpClient connect.HTTPClient, baseURL string, opt
 			connect.WithSchema(transferServiceMethods.ByName("GetFileSize")),
 			connect.WithClientOptions(opts...),
 		),
+		getFileInfo: connect.NewClient[v1.GetFileInfoRequest, v1.GetFileInfoResponse](
+			httpClient,
+			baseURL+TransferServiceGetFileInfoProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("GetFileInfo")),
+			connect.WithClientOptions(opts...),
+		),
+		listFiles: connect.NewClient[v1.ListFilesRequest, v1.ListFilesResponse](
+			httpClient,
+			baseURL+TransferServiceListFilesProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("ListFiles")),
+			connect.WithClientOptions(opts...),
+		),
 		streamFile: connect.NewClient[v1.StreamFileRequest, v1.StreamFileResponse](
 			httpClient,
 			baseURL+TransferServiceStreamFileProcedure,
@@ -81,14 +147,79 @@ func NewTransferServiceClient(httpClient connect.HTTPClient, baseURL string, opt
 			connect.WithSchema(transferServiceMethods.ByName("UploadFile")),
 			connect.WithClientOptions(opts...),
 		),
+		restoreVersion: connect.NewClient[v1.RestoreVersionRequest, v1.RestoreVersionResponse](
+			httpClient,
+			baseURL+TransferServiceRestoreVersionProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("RestoreVersion")),
+			connect.WithClientOptions(opts...),
+		),
+		deleteFile: connect.NewClient[v1.DeleteFileRequest, v1.DeleteFileResponse](
+			httpClient,
+			baseURL+TransferServiceDeleteFileProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("DeleteFile")),
+			connect.WithClientOptions(opts...),
+		),
+		listTrash: connect.NewClient[v1.ListTrashRequest, v1.ListTrashResponse](
+			httpClient,
+			baseURL+TransferServiceListTrashProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("ListTrash")),
+			connect.WithClientOptions(opts...),
+		),
+		undelete: connect.NewClient[v1.UndeleteRequest, v1.UndeleteResponse](
+			httpClient,
+			baseURL+TransferServiceUndeleteProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("Undelete")),
+			connect.WithClientOptions(opts...),
+		),
+		createShareLink: connect.NewClient[v1.CreateShareLinkRequest, v1.CreateShareLinkResponse](
+			httpClient,
+			baseURL+TransferServiceCreateShareLinkProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("CreateShareLink")),
+			connect.WithClientOptions(opts...),
+		),
+		listShareLinks: connect.NewClient[v1.ListShareLinksRequest, v1.ListShareLinksResponse](
+			httpClient,
+			baseURL+TransferServiceListShareLinksProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("ListShareLinks")),
+			connect.WithClientOptions(opts...),
+		),
+		revokeShareLink: connect.NewClient[v1.RevokeShareLinkRequest, v1.RevokeShareLinkResponse](
+			httpClient,
+			baseURL+TransferServiceRevokeShareLinkProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("RevokeShareLink")),
+			connect.WithClientOptions(opts...),
+		),
+		getPresignedURL: connect.NewClient[v1.GetPresignedURLRequest, v1.GetPresignedURLResponse](
+			httpClient,
+			baseURL+TransferServiceGetPresignedURLProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("GetPresignedURL")),
+			connect.WithClientOptions(opts...),
+		),
+		checkAccess: connect.NewClient[v1.CheckAccessRequest, v1.CheckAccessResponse](
+			httpClient,
+			baseURL+TransferServiceCheckAccessProcedure,
+			connect.WithSchema(transferServiceMethods.ByName("CheckAccess")),
+			connect.WithClientOptions(opts...),
+		),
 	}
 }
 
 // transferServiceClient implements TransferServiceClient.
 type transferServiceClient struct {
-	getFileSize *connect.Client[v1.GetFileSizeRequest, v1.GetFileSizeResponse]
-	streamFile  *connect.Client[v1.StreamFileRequest, v1.StreamFileResponse]
-	uploadFile  *connect.Client[v1.UploadFileRequest, v1.UploadFileResponse]
+	getFileSize     *connect.Client[v1.GetFileSizeRequest, v1.GetFileSizeResponse]
+	getFileInfo     *connect.Client[v1.GetFileInfoRequest, v1.GetFileInfoResponse]
+	listFiles       *connect.Client[v1.ListFilesRequest, v1.ListFilesResponse]
+	streamFile      *connect.Client[v1.StreamFileRequest, v1.StreamFileResponse]
+	uploadFile      *connect.Client[v1.UploadFileRequest, v1.UploadFileResponse]
+	restoreVersion  *connect.Client[v1.RestoreVersionRequest, v1.RestoreVersionResponse]
+	deleteFile      *connect.Client[v1.DeleteFileRequest, v1.DeleteFileResponse]
+	listTrash       *connect.Client[v1.ListTrashRequest, v1.ListTrashResponse]
+	undelete        *connect.Client[v1.UndeleteRequest, v1.UndeleteResponse]
+	createShareLink *connect.Client[v1.CreateShareLinkRequest, v1.CreateShareLinkResponse]
+	listShareLinks  *connect.Client[v1.ListShareLinksRequest, v1.ListShareLinksResponse]
+	revokeShareLink *connect.Client[v1.RevokeShareLinkRequest, v1.RevokeShareLinkResponse]
+	getPresignedURL *connect.Client[v1.GetPresignedURLRequest, v1.GetPresignedURLResponse]
+	checkAccess     *connect.Client[v1.CheckAccessRequest, v1.CheckAccessResponse]
 }
 
 // GetFileSize calls transfer.v1.TransferService.GetFileSize.
@@ -96,6 +227,16 @@ func (c *transferServiceClient) GetFileSize(ctx context.Context, req *connect.Re
 	return c.getFileSize.CallUnary(ctx, req)
 }
 
+// GetFileInfo calls transfer.v1.TransferService.GetFileInfo.
+func (c *transferServiceClient) GetFileInfo(ctx context.Context, req *connect.Request[v1.GetFileInfoRequest]) (*connect.Response[v1.GetFileInfoResponse], error) {
+	return c.getFileInfo.CallUnary(ctx, req)
+}
+
+// ListFiles calls transfer.v1.TransferService.ListFiles.
+func (c *transferServiceClient) ListFiles(ctx context.Context, req *connect.Request[v1.ListFilesRequest]) (*connect.Response[v1.ListFilesResponse], error) {
+	return c.listFiles.CallUnary(ctx, req)
+}
+
 // StreamFile calls transfer.v1.TransferService.StreamFile.
 func (c *transferServiceClient) StreamFile(ctx context.Context, req *connect.Request[v1.StreamFileRequest]) (*connect.ServerStreamForClient[v1.StreamFileResponse], error) {
 	return c.streamFile.CallServerStream(ctx, req)
@@ -106,12 +247,78 @@ func (c *transferServiceClient) UploadFile(ctx context.Context) *connect.BidiStr
 	return c.uploadFile.CallBidiStream(ctx)
 }
 
+// RestoreVersion calls transfer.v1.TransferService.RestoreVersion.
+func (c *transferServiceClient) RestoreVersion(ctx context.Context, req *connect.Request[v1.RestoreVersionRequest]) (*connect.Response[v1.RestoreVersionResponse], error) {
+	return c.restoreVersion.CallUnary(ctx, req)
+}
+
+// DeleteFile calls transfer.v1.TransferService.DeleteFile.
+func (c *transferServiceClient) DeleteFile(ctx context.Context, req *connect.Request[v1.DeleteFileRequest]) (*connect.Response[v1.DeleteFileResponse], error) {
+	return c.deleteFile.CallUnary(ctx, req)
+}
+
+// ListTrash calls transfer.v1.TransferService.ListTrash.
+func (c *transferServiceClient) ListTrash(ctx context.Context, req *connect.Request[v1.ListTrashRequest]) (*connect.Response[v1.ListTrashResponse], error) {
+	return c.listTrash.CallUnary(ctx, req)
+}
+
+// Undelete calls transfer.v1.TransferService.Undelete.
+func (c *transferServiceClient) Undelete(ctx context.Context, req *connect.Request[v1.UndeleteRequest]) (*connect.Response[v1.UndeleteResponse], error) {
+	return c.undelete.CallUnary(ctx, req)
+}
+
+// CreateShareLink calls transfer.v1.TransferService.CreateShareLink.
+func (c *transferServiceClient) CreateShareLink(ctx context.Context, req *connect.Request[v1.CreateShareLinkRequest]) (*connect.Response[v1.CreateShareLinkResponse], error) {
+	return c.createShareLink.CallUnary(ctx, req)
+}
+
+// ListShareLinks calls transfer.v1.TransferService.ListShareLinks.
+func (c *transferServiceClient) ListShareLinks(ctx context.Context, req *connect.Request[v1.ListShareLinksRequest]) (*connect.Response[v1.ListShareLinksResponse], error) {
+	return c.listShareLinks.CallUnary(ctx, req)
+}
+
+// RevokeShareLink calls transfer.v1.TransferService.RevokeShareLink.
+func (c *transferServiceClient) RevokeShareLink(ctx context.Context, req *connect.Request[v1.RevokeShareLinkRequest]) (*connect.Response[v1.RevokeShareLinkResponse], error) {
+	return c.revokeShareLink.CallUnary(ctx, req)
+}
+
+// GetPresignedURL calls transfer.v1.TransferService.GetPresignedURL.
+func (c *transferServiceClient) GetPresignedURL(ctx context.Context, req *connect.Request[v1.GetPresignedURLRequest]) (*connect.Response[v1.GetPresignedURLResponse], error) {
+	return c.getPresignedURL.CallUnary(ctx, req)
+}
+
+// CheckAccess calls transfer.v1.TransferService.CheckAccess.
+func (c *transferServiceClient) CheckAccess(ctx context.Context, req *connect.Request[v1.CheckAccessRequest]) (*connect.Response[v1.CheckAccessResponse], error) {
+	return c.checkAccess.CallUnary(ctx, req)
+}
+
 // TransferServiceHandler is an implementation of the transfer.v1.TransferService service.
 type TransferServiceHandler interface {
 	GetFileSize(context.Context, *connect.Request[v1.GetFileSizeRequest]) (*connect.Response[v1.GetFileSizeResponse], error)
+	GetFileInfo(context.Context, *connect.Request[v1.GetFileInfoRequest]) (*connect.Response[v1.GetFileInfoResponse], error)
+	ListFiles(context.Context, *connect.Request[v1.ListFilesRequest]) (*connect.Response[v1.ListFilesResponse], error)
 	StreamFile(context.Context, *connect.Request[v1.StreamFileRequest], *connect.ServerStream[v1.StreamFileResponse]) error
 	// Bi-directional streaming for uploads
 	UploadFile(context.Context, *connect.BidiStream[v1.UploadFileRequest, v1.UploadFileResponse]) error
+	// Promotes an old version of a file in a versioned namespace to be the latest
+	RestoreVersion(context.Context, *connect.Request[v1.RestoreVersionRequest]) (*connect.Response[v1.RestoreVersionResponse], error)
+	// Moves a file into its namespace's trash, from which it can be undeleted
+	// until the retention window passes
+	DeleteFile(context.Context, *connect.Request[v1.DeleteFileRequest]) (*connect.Response[v1.DeleteFileResponse], error)
+	ListTrash(context.Context, *connect.Request[v1.ListTrashRequest]) (*connect.Response[v1.ListTrashResponse], error)
+	Undelete(context.Context, *connect.Request[v1.UndeleteRequest]) (*connect.Response[v1.UndeleteResponse], error)
+	// Share links grant credential-free downloads of a single file through
+	// the HTTP server's /s/{token} endpoint.
+	CreateShareLink(context.Context, *connect.Request[v1.CreateShareLinkRequest]) (*connect.Response[v1.CreateShareLinkResponse], error)
+	ListShareLinks(context.Context, *connect.Request[v1.ListShareLinksRequest]) (*connect.Response[v1.ListShareLinksResponse], error)
+	RevokeShareLink(context.Context, *connect.Request[v1.RevokeShareLinkRequest]) (*connect.Response[v1.RevokeShareLinkResponse], error)
+	// GetPresignedURL returns a time-limited URL for transferring a file
+	// directly to or from the storage backend.
+	GetPresignedURL(context.Context, *connect.Request[v1.GetPresignedURLRequest]) (*connect.Response[v1.GetPresignedURLResponse], error)
+	// CheckAccess explains whether a principal may perform an action. Checking
+	// another principal than the caller, or the caller with other groups than
+	// its own, requires the admin action.
+	CheckAccess(context.Context, *connect.Request[v1.CheckAccessRequest]) (*connect.Response[v1.CheckAccessResponse], error)
 }
 
 // NewTransferServiceHandler builds an HTTP handler from the service implementation. It returns the
@@ -127,6 +334,18 @@ func NewTransferServiceHandler(svc TransferServiceHandler, opts ...connect.Handl
 		connect.WithSchema(transferServiceMethods.ByName("GetFileSize")),
 		connect.WithHandlerOptions(opts...),
 	)
+	transferServiceGetFileInfoHandler := connect.NewUnaryHandler(
+		TransferServiceGetFileInfoProcedure,
+		svc.GetFileInfo,
+		connect.WithSchema(transferServiceMethods.ByName("GetFileInfo")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceListFilesHandler := connect.NewUnaryHandler(
+		TransferServiceListFilesProcedure,
+		svc.ListFiles,
+		connect.WithSchema(transferServiceMethods.ByName("ListFiles")),
+		connect.WithHandlerOptions(opts...),
+	)
 	transferServiceStreamFileHandler := connect.NewServerStreamHandler(
 		TransferServiceStreamFileProcedure,
 		svc.StreamFile,
@@ -139,14 +358,90 @@ func NewTransferServiceHandler(svc TransferServiceHandler, opts ...connect.Handl
 		connect.WithSchema(transferServiceMethods.ByName("UploadFile")),
 		connect.WithHandlerOptions(opts...),
 	)
+	transferServiceRestoreVersionHandler := connect.NewUnaryHandler(
+		TransferServiceRestoreVersionProcedure,
+		svc.RestoreVersion,
+		connect.WithSchema(transferServiceMethods.ByName("RestoreVersion")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceDeleteFileHandler := connect.NewUnaryHandler(
+		TransferServiceDeleteFileProcedure,
+		svc.DeleteFile,
+		connect.WithSchema(transferServiceMethods.ByName("DeleteFile")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceListTrashHandler := connect.NewUnaryHandler(
+		TransferServiceListTrashProcedure,
+		svc.ListTrash,
+		connect.WithSchema(transferServiceMethods.ByName("ListTrash")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceUndeleteHandler := connect.NewUnaryHandler(
+		TransferServiceUndeleteProcedure,
+		svc.Undelete,
+		connect.WithSchema(transferServiceMethods.ByName("Undelete")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceCreateShareLinkHandler := connect.NewUnaryHandler(
+		TransferServiceCreateShareLinkProcedure,
+		svc.CreateShareLink,
+		connect.WithSchema(transferServiceMethods.ByName("CreateShareLink")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceListShareLinksHandler := connect.NewUnaryHandler(
+		TransferServiceListShareLinksProcedure,
+		svc.ListShareLinks,
+		connect.WithSchema(transferServiceMethods.ByName("ListShareLinks")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceRevokeShareLinkHandler := connect.NewUnaryHandler(
+		TransferServiceRevokeShareLinkProcedure,
+		svc.RevokeShareLink,
+		connect.WithSchema(transferServiceMethods.ByName("RevokeShareLink")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceGetPresignedURLHandler := connect.NewUnaryHandler(
+		TransferServiceGetPresignedURLProcedure,
+		svc.GetPresignedURL,
+		connect.WithSchema(transferServiceMethods.ByName("GetPresignedURL")),
+		connect.WithHandlerOptions(opts...),
+	)
+	transferServiceCheckAccessHandler := connect.NewUnaryHandler(
+		TransferServiceCheckAccessProcedure,
+		svc.CheckAccess,
+		connect.WithSchema(transferServiceMethods.ByName("CheckAccess")),
+		connect.WithHandlerOptions(opts...),
+	)
 	return "/transfer.v1.TransferService/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		switch r.URL.Path {
 		case TransferServiceGetFileSizeProcedure:
 			transferServiceGetFileSizeHandler.ServeHTTP(w, r)
+		case TransferServiceGetFileInfoProcedure:
+			transferServiceGetFileInfoHandler.ServeHTTP(w, r)
+		case TransferServiceListFilesProcedure:
+			transferServiceListFilesHandler.ServeHTTP(w, r)
 		case TransferServiceStreamFileProcedure:
 			transferServiceStreamFileHandler.ServeHTTP(w, r)
 		case TransferServiceUploadFileProcedure:
 			transferServiceUploadFileHandler.ServeHTTP(w, r)
+		case TransferServiceRestoreVersionProcedure:
+			transferServiceRestoreVersionHandler.ServeHTTP(w, r)
+		case TransferServiceDeleteFileProcedure:
+			transferServiceDeleteFileHandler.ServeHTTP(w, r)
+		case TransferServiceListTrashProcedure:
+			transferServiceListTrashHandler.ServeHTTP(w, r)
+		case TransferServiceUndeleteProcedure:
+			transferServiceUndeleteHandler.ServeHTTP(w, r)
+		case TransferServiceCreateShareLinkProcedure:
+			transferServiceCreateShareLinkHandler.ServeHTTP(w, r)
+		case TransferServiceListShareLinksProcedure:
+			transferServiceListShareLinksHandler.ServeHTTP(w, r)
+		case TransferServiceRevokeShareLinkProcedure:
+			transferServiceRevokeShareLinkHandler.ServeHTTP(w, r)
+		case TransferServiceGetPresignedURLProcedure:
+			transferServiceGetPresignedURLHandler.ServeHTTP(w, r)
+		case TransferServiceCheckAccessProcedure:
+			transferServiceCheckAccessHandler.ServeHTTP(w, r)
 		default:
 			http.NotFound(w, r)
 		}
@@ -160,6 +455,14 @@ func (UnimplementedTransferServiceHandler) GetFileSize(context.Context, *connect
 	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.GetFileSize is not implemented"))
 }
 
+func (UnimplementedTransferServiceHandler) GetFileInfo(context.Context, *connect.Request[v1.GetFileInfoRequest]) (*connect.Response[v1.GetFileInfoResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.GetFileInfo is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) ListFiles(context.Context, *connect.Request[v1.ListFilesRequest]) (*connect.Response[v1.ListFilesResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.ListFiles is not implemented"))
+}
+
 func (UnimplementedTransferServiceHandler) StreamFile(context.Context, *connect.Request[v1.StreamFileRequest], *connect.ServerStream[v1.StreamFileResponse]) error {
 	return connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.StreamFile is not implemented"))
 }
@@ -167,3 +470,39 @@ func (UnimplementedTransferServiceHandler) StreamFile(context.Context, *connect.
 func (UnimplementedTransferServiceHandler) UploadFile(context.Context, *connect.BidiStream[v1.UploadFileRequest, v1.UploadFileResponse]) error {
 	return connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.UploadFile is not implemented"))
 }
+
+func (UnimplementedTransferServiceHandler) RestoreVersion(context.Context, *connect.Request[v1.RestoreVersionRequest]) (*connect.Response[v1.RestoreVersionResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.RestoreVersion is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) DeleteFile(context.Context, *connect.Request[v1.DeleteFileRequest]) (*connect.Response[v1.DeleteFileResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.DeleteFile is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) ListTrash(context.Context, *connect.Request[v1.ListTrashRequest]) (*connect.Response[v1.ListTrashResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.ListTrash is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) Undelete(context.Context, *connect.Request[v1.UndeleteRequest]) (*connect.Response[v1.UndeleteResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.Undelete is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) CreateShareLink(context.Context, *connect.Request[v1.CreateShareLinkRequest]) (*connect.Response[v1.CreateShareLinkResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.CreateShareLink is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) ListShareLinks(context.Context, *connect.Request[v1.ListShareLinksRequest]) (*connect.Response[v1.ListShareLinksResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.ListShareLinks is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) RevokeShareLink(context.Context, *connect.Request[v1.RevokeShareLinkRequest]) (*connect.Response[v1.RevokeShareLinkResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.RevokeShareLink is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) GetPresignedURL(context.Context, *connect.Request[v1.GetPresignedURLRequest]) (*connect.Response[v1.GetPresignedURLResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.GetPresignedURL is not implemented"))
+}
+
+func (UnimplementedTransferServiceHandler) CheckAccess(context.Context, *connect.Request[v1.CheckAccessRequest]) (*connect.Response[v1.CheckAccessResponse], error) {
+	return nil, connect.NewError(connect.CodeUnimplemented, errors.New("transfer.v1.TransferService.CheckAccess is not implemented"))
+}
@@ -0,0 +1,241 @@
+// Package admission caps the number of concurrent transfers and metadata
+// calls. Requests beyond a class's limit wait in a bounded queue, where
+// interactive requests are admitted before batch ones; requests are shed
+// when the queue is full or they have waited too long.
+package admission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+)
+
+// Classes of requests, each with its own limit and queue.
+type Class string
+
+const (
+	ClassUpload   Class = "upload"
+	ClassDownload Class = "download"
+	ClassMetadata Class = "metadata"
+)
+
+// Priorities of queued requests.
+type Priority int
+
+const (
+	// PriorityInteractive requests are admitted before any batch request.
+	PriorityInteractive Priority = iota
+	PriorityBatch
+)
+
+// PriorityHeader is the request header (or Connect metadata key) selecting
+// a request's priority: "interactive", the default, or "batch".
+const PriorityHeader = "X-Priority"
+
+// ParsePriority returns the priority named in header.
+func ParsePriority(header http.Header) Priority {
+	if strings.EqualFold(header.Get(PriorityHeader), "batch") {
+		return PriorityBatch
+	}
+	return PriorityInteractive
+}
+
+func (p Priority) String() string {
+	if p == PriorityBatch {
+		return "batch"
+	}
+	return "interactive"
+}
+
+var (
+	// ErrQueueFull is returned when a request arrives while its class's
+	// queue is full.
+	ErrQueueFull = errors.New("server is busy: queue is full")
+	// ErrWaitTimeout is returned when a request waited in the queue for
+	// longer than the maximum wait.
+	ErrWaitTimeout = errors.New("server is busy: timed out waiting in queue")
+)
+
+// Limits configures one class. Zero MaxConcurrent means unlimited.
+type Limits struct {
+	MaxConcurrent int
+	// MaxQueue is how many requests may wait for a slot; zero rejects
+	// requests as soon as every slot is taken.
+	MaxQueue int
+	// MaxWait is how long a request may wait for a slot; zero waits until
+	// the request is canceled.
+	MaxWait time.Duration
+}
+
+// Controller admits requests of every class.
+type Controller struct {
+	mu    sync.RWMutex
+	pools map[Class]*pool
+}
+
+// New returns a Controller enforcing limits. Classes without limits are
+// admitted immediately.
+func New(limits map[Class]Limits) *Controller {
+	c := &Controller{pools: make(map[Class]*pool)}
+	c.SetLimits(limits)
+	return c
+}
+
+// SetLimits replaces the limits of every class. Requests already admitted
+// keep their slot; raising a limit admits queued requests right away.
+func (c *Controller) SetLimits(limits map[Class]Limits) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for class, p := range c.pools {
+		if _, ok := limits[class]; !ok {
+			p.setLimits(Limits{})
+		}
+	}
+	for class, l := range limits {
+		p, ok := c.pools[class]
+		if !ok {
+			p = &pool{class: class}
+			c.pools[class] = p
+		}
+		p.setLimits(l)
+	}
+}
+
+// Acquire waits for a slot of class and returns the function releasing it.
+// It fails with ErrQueueFull or ErrWaitTimeout when the request is shed, or
+// with the context's error when it is canceled while queued. A nil
+// Controller admits everything.
+func (c *Controller) Acquire(ctx context.Context, class Class, priority Priority) (func(), error) {
+	if c == nil {
+		return func() {}, nil
+	}
+	c.mu.RLock()
+	p, ok := c.pools[class]
+	c.mu.RUnlock()
+	if !ok {
+		return func() {}, nil
+	}
+	return p.acquire(ctx, priority)
+}
+
+// pool is the set of slots of one class.
+type pool struct {
+	class  Class
+	limits Limits
+
+	mu       sync.Mutex
+	inFlight int
+	// queues holds the waiters of each priority in arrival order.
+	queues [2][]*waiter
+}
+
+type waiter struct {
+	// ready is closed once the waiter has been handed a slot.
+	ready chan struct{}
+}
+
+func (p *pool) acquire(ctx context.Context, priority Priority) (func(), error) {
+	p.mu.Lock()
+	if p.hasSlot() && p.queued() == 0 {
+		p.inFlight++
+		p.mu.Unlock()
+		metrics.AddAdmissionInFlight(string(p.class), 1)
+		return p.release, nil
+	}
+	limits := p.limits
+	if p.queued() >= limits.MaxQueue {
+		p.mu.Unlock()
+		metrics.RecordAdmissionShed(string(p.class), "rejected")
+		return nil, fmt.Errorf("%w for %s requests", ErrQueueFull, p.class)
+	}
+	w := &waiter{ready: make(chan struct{})}
+	p.queues[priority] = append(p.queues[priority], w)
+	p.mu.Unlock()
+	metrics.AddAdmissionQueued(string(p.class), priority.String(), 1)
+	defer metrics.AddAdmissionQueued(string(p.class), priority.String(), -1)
+
+	var timeout <-chan time.Time
+	if limits.MaxWait > 0 {
+		timer := time.NewTimer(limits.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-w.ready:
+		return p.release, nil
+	case <-ctx.Done():
+		if p.abandon(w, priority) {
+			return nil, ctx.Err()
+		}
+		// The slot was handed over as the request gave up.
+		p.release()
+		return nil, ctx.Err()
+	case <-timeout:
+		if !p.abandon(w, priority) {
+			return p.release, nil
+		}
+		metrics.RecordAdmissionShed(string(p.class), "timed_out")
+		return nil, fmt.Errorf("%w for a %s slot after %s", ErrWaitTimeout, p.class, limits.MaxWait)
+	}
+}
+
+// release frees a slot, handing it to the next waiter if any.
+func (p *pool) release() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inFlight--
+	metrics.AddAdmissionInFlight(string(p.class), -1)
+	p.dispatch()
+}
+
+// setLimits replaces the limits of the pool.
+func (p *pool) setLimits(limits Limits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.limits = limits
+	p.dispatch()
+}
+
+// dispatch hands free slots to waiters in priority order. p.mu must be
+// held.
+func (p *pool) dispatch() {
+	for priority := range p.queues {
+		for len(p.queues[priority]) > 0 && p.hasSlot() {
+			w := p.queues[priority][0]
+			p.queues[priority] = p.queues[priority][1:]
+			p.inFlight++
+			metrics.AddAdmissionInFlight(string(p.class), 1)
+			close(w.ready)
+		}
+	}
+}
+
+// hasSlot reports whether a request can be admitted. p.mu must be held.
+func (p *pool) hasSlot() bool {
+	return p.limits.MaxConcurrent == 0 || p.inFlight < p.limits.MaxConcurrent
+}
+
+// abandon removes w from its queue, reporting false if it was already
+// handed a slot.
+func (p *pool) abandon(w *waiter, priority Priority) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, queued := range p.queues[priority] {
+		if queued == w {
+			p.queues[priority] = append(p.queues[priority][:i], p.queues[priority][i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// queued returns the number of waiters. p.mu must be held.
+func (p *pool) queued() int {
+	return len(p.queues[PriorityInteractive]) + len(p.queues[PriorityBatch])
+}
@@ -0,0 +1,91 @@
+package admission
+
+import (
+	"context"
+	"net/http"
+
+	"connectrpc.com/connect"
+)
+
+// retryAfter is the Retry-After hint sent with shed requests, in seconds.
+const retryAfter = "1"
+
+// Middleware admits requests to next as class, holding the slot until next
+// returns. Shed requests get 503 Service Unavailable with a Retry-After
+// header.
+func Middleware(c *Controller, class Class, next http.Handler) http.Handler {
+	if c == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		release, err := c.Acquire(r.Context(), class, ParsePriority(r.Header))
+		if err != nil {
+			w.Header().Set("Retry-After", retryAfter)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		defer release()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Interceptor admits RPCs, unary and streaming, holding the slot until the
+// handler returns. Shed RPCs fail with Unavailable and a Retry-After header.
+type Interceptor struct {
+	controller *Controller
+	classify   func(procedure string) Class
+}
+
+// Compile-time check to ensure Interceptor implements connect.Interceptor.
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor returns an Interceptor admitting RPCs through c. classify
+// returns the class of a procedure.
+func NewInterceptor(c *Controller, classify func(procedure string) Class) *Interceptor {
+	return &Interceptor{controller: c, classify: classify}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		release, err := i.acquire(ctx, req.Spec().Procedure, req.Header())
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return next(ctx, req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		release, err := i.acquire(ctx, conn.Spec().Procedure, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		defer release()
+		return next(ctx, conn)
+	}
+}
+
+func (i *Interceptor) acquire(ctx context.Context, procedure string, header http.Header) (func(), error) {
+	release, err := i.controller.Acquire(ctx, i.classify(procedure), ParsePriority(header))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, connect.NewError(connect.CodeCanceled, err)
+		}
+		connectErr := connect.NewError(connect.CodeUnavailable, err)
+		connectErr.Meta().Set("Retry-After", retryAfter)
+		return nil, connectErr
+	}
+	return release, nil
+}
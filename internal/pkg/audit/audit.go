@@ -0,0 +1,64 @@
+// Package audit records security-relevant operations as JSON lines, one
+// event per line, so they can be shipped to and queried by a log pipeline.
+package audit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+)
+
+// Event is a single audited operation.
+type Event struct {
+	// Action names the operation, e.g. "presign.issue".
+	Action    string
+	Namespace string
+	FileName  string
+	// Principal is the subject of the authenticated identity that requested
+	// the operation. When empty it is taken from the context, if present.
+	Principal string
+	// Remote is the address of the client that requested the operation.
+	Remote string
+	// Details holds action-specific attributes.
+	Details map[string]any
+}
+
+// Logger writes audit events.
+type Logger struct {
+	logger *slog.Logger
+}
+
+// New returns a Logger writing JSON events to w.
+func New(w io.Writer) *Logger {
+	return &Logger{logger: slog.New(slog.NewJSONHandler(w, nil))}
+}
+
+// Record writes event to the audit trail.
+func (l *Logger) Record(ctx context.Context, event Event) {
+	attrs := []slog.Attr{
+		slog.String("action", event.Action),
+		slog.String("namespace", event.Namespace),
+		slog.String("file_name", event.FileName),
+	}
+	if event.Principal == "" {
+		if identity, ok := auth.FromContext(ctx); ok {
+			event.Principal = identity.Subject
+		}
+	}
+	if event.Principal != "" {
+		attrs = append(attrs, slog.String("principal", event.Principal))
+	}
+	if event.Remote != "" {
+		attrs = append(attrs, slog.String("remote", event.Remote))
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	for key, value := range event.Details {
+		attrs = append(attrs, slog.Any(key, value))
+	}
+	l.logger.LogAttrs(ctx, slog.LevelInfo, "audit", attrs...)
+}
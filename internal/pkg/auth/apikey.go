@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+)
+
+// APIKeyHeader carries a static API key. Keys may also be sent as
+// "Authorization: Bearer <key>" when they do not look like a JWT.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeyAuthenticator authenticates requests carrying a static API key.
+type APIKeyAuthenticator struct {
+	// keys maps the SHA-256 of each key to the subject it belongs to, so
+	// that keys are compared in constant time and not kept in clear.
+	keys map[[sha256.Size]byte]string
+}
+
+// NewAPIKeyAuthenticator returns an authenticator for entries of the form
+// "subject=key".
+func NewAPIKeyAuthenticator(entries []string) (*APIKeyAuthenticator, error) {
+	a := &APIKeyAuthenticator{keys: make(map[[sha256.Size]byte]string, len(entries))}
+	for _, entry := range entries {
+		subject, key, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || subject == "" || key == "" {
+			return nil, fmt.Errorf("invalid API key entry %q, want subject=key", entry)
+		}
+		a.keys[sha256.Sum256([]byte(key))] = subject
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *APIKeyAuthenticator) Authenticate(_ context.Context, creds Credentials) (Identity, error) {
+	key := creds.Header.Get(APIKeyHeader)
+	if key == "" {
+		token, ok := bearerToken(creds.Header)
+		if !ok || looksLikeJWT(token) {
+			return Identity{}, ErrNoCredentials
+		}
+		key = token
+	}
+	sum := sha256.Sum256([]byte(key))
+	for candidate, subject := range a.keys {
+		if subtle.ConstantTimeCompare(candidate[:], sum[:]) == 1 {
+			return Identity{Subject: subject, Method: MethodAPIKey}, nil
+		}
+	}
+	return Identity{}, invalid("unknown API key")
+}
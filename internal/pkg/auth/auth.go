@@ -0,0 +1,131 @@
+// Package auth authenticates requests to both transports. Credentials are
+// checked by a chain of authenticators (static API keys, JWTs verified
+// against a local JWKS file and mTLS client certificates), and the resulting
+// Identity is stored in the request context for the handlers and RPCs.
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Authentication methods reported in Identity.Method.
+const (
+	MethodAPIKey    = "api_key"
+	MethodJWT       = "jwt"
+	MethodMTLS      = "mtls"
+	MethodAnonymous = "anonymous"
+)
+
+var (
+	// ErrNoCredentials is returned when a request carries no credentials
+	// that any authenticator understands.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when credentials are present but
+	// cannot be verified.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Identity is the authenticated principal behind a request.
+type Identity struct {
+	// Subject identifies the principal, e.g. an API key name, the JWT "sub"
+	// claim or the client certificate's common name.
+	Subject string
+	// Method is the authentication method that produced the identity.
+	Method string
+	// Groups lists the groups the principal belongs to, when the
+	// credential carries them.
+	Groups []string
+}
+
+// Anonymous is the identity of unauthenticated requests when anonymous
+// access is allowed.
+var Anonymous = Identity{Subject: "anonymous", Method: MethodAnonymous}
+
+// Credentials holds everything a request may authenticate with.
+type Credentials struct {
+	Header http.Header
+	// TLS is the state of the request's TLS connection, or nil.
+	TLS *tls.ConnectionState
+}
+
+// Authenticator verifies one kind of credential.
+type Authenticator interface {
+	// Authenticate returns the identity proven by creds. It returns
+	// ErrNoCredentials when creds holds nothing it understands, so that the
+	// next authenticator can be tried.
+	Authenticate(ctx context.Context, creds Credentials) (Identity, error)
+}
+
+// Chain tries each authenticator in order and returns the first identity
+// established. Invalid credentials fail immediately rather than falling
+// through, so a bad token is never silently treated as anonymous.
+type Chain struct {
+	authenticators []Authenticator
+	allowAnonymous bool
+}
+
+// NewChain returns a Chain of authenticators. When allowAnonymous is set,
+// requests without credentials are let through as Anonymous.
+func NewChain(allowAnonymous bool, authenticators ...Authenticator) *Chain {
+	return &Chain{authenticators: authenticators, allowAnonymous: allowAnonymous}
+}
+
+// Authenticate returns the identity proven by creds.
+func (c *Chain) Authenticate(ctx context.Context, creds Credentials) (Identity, error) {
+	for _, a := range c.authenticators {
+		identity, err := a.Authenticate(ctx, creds)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		if err != nil {
+			return Identity{}, err
+		}
+		return identity, nil
+	}
+	if c.allowAnonymous {
+		return Anonymous, nil
+	}
+	return Identity{}, ErrNoCredentials
+}
+
+// bearerToken returns the token of an "Authorization: Bearer" header.
+func bearerToken(header http.Header) (string, bool) {
+	scheme, token, ok := strings.Cut(header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// invalid wraps reason as an ErrInvalidCredentials error.
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", ErrInvalidCredentials, fmt.Sprintf(format, args...))
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying identity.
+func WithIdentity(ctx context.Context, identity Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity stored in ctx, if any.
+func FromContext(ctx context.Context) (Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(Identity)
+	return identity, ok
+}
+
+// Caller returns the identity stored in ctx, or Anonymous when the request
+// was not authenticated.
+func Caller(ctx context.Context) Identity {
+	if identity, ok := FromContext(ctx); ok {
+		return identity
+	}
+	return Anonymous
+}
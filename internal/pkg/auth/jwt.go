@@ -0,0 +1,243 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// clockSkew is the leeway allowed when checking token timestamps.
+const clockSkew = time.Minute
+
+// JWTConfig configures JWT verification.
+type JWTConfig struct {
+	// JWKSFile is a JSON Web Key Set holding the verification keys. HMAC
+	// ("oct") and RSA keys are supported.
+	JWKSFile string
+	// Issuer, when set, must match the "iss" claim.
+	Issuer string
+	// Audience, when set, must be listed in the "aud" claim.
+	Audience string
+	// GroupsClaim names the claim listing the principal's groups; it
+	// defaults to "groups".
+	GroupsClaim string
+}
+
+// JWTAuthenticator authenticates requests carrying a signed JWT as a bearer
+// token.
+type JWTAuthenticator struct {
+	config JWTConfig
+	keys   []jwk
+	now    func() time.Time
+}
+
+// jwk is a verification key from the JWKS file.
+type jwk struct {
+	kid string
+	alg string
+	// key is a []byte for HMAC keys or an *rsa.PublicKey.
+	key any
+}
+
+// NewJWTAuthenticator loads the key set named by config and returns an
+// authenticator verifying tokens against it.
+func NewJWTAuthenticator(config JWTConfig) (*JWTAuthenticator, error) {
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = "groups"
+	}
+	keys, err := loadJWKS(config.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+	return &JWTAuthenticator{config: config, keys: keys, now: time.Now}, nil
+}
+
+// loadJWKS reads and parses a JSON Web Key Set.
+func loadJWKS(path string) ([]jwk, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS: %w", err)
+	}
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			K   string `json:"k"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parsing JWKS: %w", err)
+	}
+	keys := make([]jwk, 0, len(set.Keys))
+	for i, k := range set.Keys {
+		key := jwk{kid: k.Kid, alg: k.Alg}
+		switch k.Kty {
+		case "oct":
+			secret, err := base64.RawURLEncoding.DecodeString(k.K)
+			if err != nil || len(secret) == 0 {
+				return nil, fmt.Errorf("JWKS key %d: invalid symmetric key", i)
+			}
+			key.key = secret
+		case "RSA":
+			n, errN := base64.RawURLEncoding.DecodeString(k.N)
+			e, errE := base64.RawURLEncoding.DecodeString(k.E)
+			if errN != nil || errE != nil || len(n) == 0 || len(e) == 0 {
+				return nil, fmt.Errorf("JWKS key %d: invalid RSA key", i)
+			}
+			key.key = &rsa.PublicKey{
+				N: new(big.Int).SetBytes(n),
+				E: int(new(big.Int).SetBytes(e).Int64()),
+			}
+		default:
+			return nil, fmt.Errorf("JWKS key %d: unsupported key type %q", i, k.Kty)
+		}
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS holds no keys")
+	}
+	return keys, nil
+}
+
+// looksLikeJWT reports whether token has the three dot-separated parts of a
+// compact JWT.
+func looksLikeJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
+
+// Authenticate implements Authenticator.
+func (a *JWTAuthenticator) Authenticate(_ context.Context, creds Credentials) (Identity, error) {
+	token, ok := bearerToken(creds.Header)
+	if !ok || !looksLikeJWT(token) {
+		return Identity{}, ErrNoCredentials
+	}
+	parts := strings.Split(token, ".")
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Identity{}, invalid("malformed token header")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Identity{}, invalid("malformed token signature")
+	}
+	if err := a.verify(header.Alg, header.Kid, parts[0]+"."+parts[1], signature); err != nil {
+		return Identity{}, err
+	}
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Identity{}, invalid("malformed token claims")
+	}
+	return a.identity(claims)
+}
+
+// verify checks the token signature with the matching key.
+func (a *JWTAuthenticator) verify(alg, kid, signed string, signature []byte) error {
+	hash, ok := map[string]crypto.Hash{
+		"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+		"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+	}[alg]
+	if !ok {
+		return invalid("unsupported algorithm %q", alg)
+	}
+	for _, key := range a.keys {
+		if kid != "" && key.kid != kid {
+			continue
+		}
+		if key.alg != "" && key.alg != alg {
+			continue
+		}
+		switch k := key.key.(type) {
+		case []byte:
+			if !strings.HasPrefix(alg, "HS") {
+				continue
+			}
+			mac := hmac.New(hash.New, k)
+			mac.Write([]byte(signed))
+			if hmac.Equal(mac.Sum(nil), signature) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if !strings.HasPrefix(alg, "RS") {
+				continue
+			}
+			h := hash.New()
+			h.Write([]byte(signed))
+			if rsa.VerifyPKCS1v15(k, hash, h.Sum(nil), signature) == nil {
+				return nil
+			}
+		}
+	}
+	return invalid("bad token signature")
+}
+
+// identity checks the registered claims and builds the token's identity.
+func (a *JWTAuthenticator) identity(claims map[string]any) (Identity, error) {
+	now := a.now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return Identity{}, invalid("token has no expiry")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockSkew)) {
+		return Identity{}, invalid("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(clockSkew).Before(time.Unix(int64(nbf), 0)) {
+		return Identity{}, invalid("token not yet valid")
+	}
+	if a.config.Issuer != "" && claims["iss"] != a.config.Issuer {
+		return Identity{}, invalid("unexpected issuer")
+	}
+	if a.config.Audience != "" && !slices.Contains(stringList(claims["aud"]), a.config.Audience) {
+		return Identity{}, invalid("unexpected audience")
+	}
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return Identity{}, invalid("token has no subject")
+	}
+	return Identity{
+		Subject: subject,
+		Method:  MethodJWT,
+		Groups:  stringList(claims[a.config.GroupsClaim]),
+	}, nil
+}
+
+// decodeSegment decodes a base64url-encoded JSON token segment into v.
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// stringList converts a claim holding a string or a list of strings.
+func stringList(claim any) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"connectrpc.com/connect"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+)
+
+// Middleware authenticates every request before passing it to next with the
+// identity stored in its context. Requests that fail authentication are
+// rejected with 401 Unauthorized.
+func Middleware(authenticator Authenticator, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		identity, err := authenticator.Authenticate(r.Context(), Credentials{
+			Header: r.Header,
+			TLS:    r.TLS,
+		})
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="file-streamer"`)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		logging.SetUser(r.Context(), identity.Subject)
+		next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), identity)))
+	})
+}
+
+type tlsStateKey struct{}
+
+// WithConnectionState makes the TLS state of each request available to the
+// Connect interceptor, which only sees request headers.
+func WithConnectionState(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil {
+			r = r.WithContext(context.WithValue(r.Context(), tlsStateKey{}, r.TLS))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Interceptor authenticates every Connect RPC, unary and streaming, and
+// stores the identity in the handler's context.
+type Interceptor struct {
+	authenticator Authenticator
+}
+
+// Compile-time check to ensure Interceptor implements connect.Interceptor.
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor returns an Interceptor using authenticator.
+func NewInterceptor(authenticator Authenticator) *Interceptor {
+	return &Interceptor{authenticator: authenticator}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		ctx, err := i.authenticate(ctx, req.Header())
+		if err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, err := i.authenticate(ctx, conn.RequestHeader())
+		if err != nil {
+			return err
+		}
+		return next(ctx, conn)
+	}
+}
+
+// authenticate returns ctx carrying the identity proven by header and the
+// connection's TLS state.
+func (i *Interceptor) authenticate(ctx context.Context, header http.Header) (context.Context, error) {
+	state, _ := ctx.Value(tlsStateKey{}).(*tls.ConnectionState)
+	identity, err := i.authenticator.Authenticate(ctx, Credentials{Header: header, TLS: state})
+	if err != nil {
+		return nil, connect.NewError(connect.CodeUnauthenticated, err)
+	}
+	logging.SetUser(ctx, identity.Subject)
+	return WithIdentity(ctx, identity), nil
+}
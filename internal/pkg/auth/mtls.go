@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// MTLSAuthenticator authenticates requests by the client certificate
+// presented on their TLS connection. The certificate's common name is the
+// subject and its organizational units are the groups.
+//
+// It only has an effect on listeners that terminate TLS and request client
+// certificates.
+type MTLSAuthenticator struct {
+	roots *x509.CertPool
+}
+
+// NewMTLSAuthenticator returns an authenticator accepting client
+// certificates issued by the CAs in the PEM file caFile.
+func NewMTLSAuthenticator(caFile string) (*MTLSAuthenticator, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading client CA: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, errors.New("client CA file holds no certificates")
+	}
+	return &MTLSAuthenticator{roots: roots}, nil
+}
+
+// Authenticate implements Authenticator.
+func (a *MTLSAuthenticator) Authenticate(_ context.Context, creds Credentials) (Identity, error) {
+	if creds.TLS == nil || len(creds.TLS.PeerCertificates) == 0 {
+		return Identity{}, ErrNoCredentials
+	}
+	leaf := creds.TLS.PeerCertificates[0]
+	intermediates := x509.NewCertPool()
+	for _, cert := range creds.TLS.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	// The TLS layer may only request certificates without verifying them,
+	// so the chain is always verified here.
+	if _, err := leaf.Verify(x509.VerifyOptions{
+		Roots:         a.roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return Identity{}, invalid("client certificate: %v", err)
+	}
+	if leaf.Subject.CommonName == "" {
+		return Identity{}, invalid("client certificate has no common name")
+	}
+	return Identity{
+		Subject: leaf.Subject.CommonName,
+		Method:  MethodMTLS,
+		Groups:  leaf.Subject.OrganizationalUnit,
+	}, nil
+}
@@ -0,0 +1,166 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+)
+
+// ErrDenied is returned when a request is not authorized.
+var ErrDenied = errors.New("permission denied")
+
+// Request is an access request to evaluate.
+type Request struct {
+	Identity  auth.Identity
+	Namespace string
+	// Path is the file name, or the listed prefix for list actions.
+	Path   string
+	Action string
+}
+
+// Decision is the outcome of evaluating a request, with an explanation.
+type Decision struct {
+	Allowed bool
+	// RuleID is the rule that decided, empty when the default applied.
+	RuleID string
+	// Reason explains the decision in a sentence.
+	Reason string
+	// Groups is the full set of groups the principal was evaluated with.
+	Groups []string
+}
+
+// Err returns nil for an allowed decision, or an error wrapping ErrDenied
+// that carries the reason.
+func (d Decision) Err() error {
+	if d.Allowed {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrDenied, d.Reason)
+}
+
+// Authorizer evaluates requests against a policy.
+type Authorizer struct {
+	policy *Policy
+	// members maps each principal to the policy groups it belongs to.
+	members map[string][]string
+}
+
+// New returns an Authorizer enforcing policy.
+func New(policy *Policy) *Authorizer {
+	members := make(map[string][]string)
+	for group, principals := range policy.Groups {
+		for _, principal := range principals {
+			members[principal] = append(members[principal], group)
+		}
+	}
+	return &Authorizer{policy: policy, members: members}
+}
+
+// Authorize evaluates req. Rules are evaluated in order; a matching deny
+// rule wins over any allow rule, and the first matching allow rule is
+// reported otherwise. A nil Authorizer allows everything.
+func (a *Authorizer) Authorize(req Request) Decision {
+	if a == nil {
+		return Decision{Allowed: true, Reason: "authorization is disabled", Groups: req.Identity.Groups}
+	}
+	groups := a.groups(req.Identity)
+	var allow *Rule
+	for i := range a.policy.Rules {
+		rule := &a.policy.Rules[i]
+		if !rule.matches(req, groups) {
+			continue
+		}
+		if rule.Effect == EffectDeny {
+			return Decision{
+				RuleID: rule.ID,
+				Reason: fmt.Sprintf("rule %q denies %s on %q in namespace %q to %s", rule.ID, req.Action, req.Path, req.Namespace, req.Identity.Subject),
+				Groups: groups,
+			}
+		}
+		if allow == nil {
+			allow = rule
+		}
+	}
+	if allow != nil {
+		return Decision{
+			Allowed: true,
+			RuleID:  allow.ID,
+			Reason:  fmt.Sprintf("rule %q allows %s on %q in namespace %q to %s", allow.ID, req.Action, req.Path, req.Namespace, req.Identity.Subject),
+			Groups:  groups,
+		}
+	}
+	return Decision{
+		Allowed: a.policy.Default == EffectAllow,
+		Reason:  fmt.Sprintf("no rule matches %s on %q in namespace %q for %s; default is %s", req.Action, req.Path, req.Namespace, req.Identity.Subject, a.policy.Default),
+		Groups:  groups,
+	}
+}
+
+// Check explains the decision for req on behalf of caller. When
+// req.Identity has no subject, or only the caller's without groups, the
+// caller's own access is checked, which is always permitted; checking
+// another principal, or the caller with other groups than its own, requires
+// the admin action on the namespace.
+func (a *Authorizer) Check(caller auth.Identity, req Request) (Decision, error) {
+	if req.Identity.Subject == "" || (req.Identity.Subject == caller.Subject && len(req.Identity.Groups) == 0) {
+		req.Identity = caller
+	}
+	if req.Identity.Subject != caller.Subject || !sameGroups(req.Identity.Groups, caller.Groups) {
+		admin := a.Authorize(Request{Identity: caller, Namespace: req.Namespace, Action: ActionAdmin})
+		if err := admin.Err(); err != nil {
+			return Decision{}, err
+		}
+	}
+	if req.Action != wildcard && !slices.Contains(actions, req.Action) {
+		return Decision{}, fmt.Errorf("unknown action %q", req.Action)
+	}
+	return a.Authorize(req), nil
+}
+
+// sameGroups reports whether a and b hold the same groups, in any order.
+func sameGroups(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(slices.Compact(a), slices.Compact(b))
+}
+
+// groups returns the groups carried by identity together with the policy
+// groups that list it.
+func (a *Authorizer) groups(identity auth.Identity) []string {
+	groups := append([]string{}, identity.Groups...)
+	for _, group := range a.members[identity.Subject] {
+		if !slices.Contains(groups, group) {
+			groups = append(groups, group)
+		}
+	}
+	slices.Sort(groups)
+	return groups
+}
+
+// matches reports whether the rule applies to req for a principal in groups.
+func (r *Rule) matches(req Request, groups []string) bool {
+	principal := slices.Contains(r.Principals, wildcard) || slices.Contains(r.Principals, req.Identity.Subject)
+	if !principal {
+		principal = slices.ContainsFunc(r.Groups, func(group string) bool {
+			return group == wildcard || slices.Contains(groups, group)
+		})
+	}
+	if !principal {
+		return false
+	}
+	if len(r.Namespaces) > 0 && !slices.Contains(r.Namespaces, wildcard) && !slices.Contains(r.Namespaces, req.Namespace) {
+		return false
+	}
+	if !slices.Contains(r.Actions, wildcard) && !slices.Contains(r.Actions, req.Action) {
+		return false
+	}
+	if len(r.Paths) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(r.Paths, func(glob string) bool {
+		return matchGlob(glob, req.Path)
+	})
+}
@@ -0,0 +1,151 @@
+// Package authz decides whether an authenticated principal may perform an
+// action on a path in a namespace. Decisions follow a policy file of allow
+// and deny rules; a matching deny rule always wins, and requests no rule
+// matches get the policy's default effect.
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Actions that rules grant or deny.
+const (
+	ActionRead   = "read"
+	ActionWrite  = "write"
+	ActionDelete = "delete"
+	ActionList   = "list"
+	// ActionAdmin covers managing share links and inspecting the access of
+	// other principals.
+	ActionAdmin = "admin"
+)
+
+// Rule effects.
+const (
+	EffectAllow = "allow"
+	EffectDeny  = "deny"
+)
+
+// wildcard matches any principal, group, namespace or action.
+const wildcard = "*"
+
+var actions = []string{ActionRead, ActionWrite, ActionDelete, ActionList, ActionAdmin}
+
+// Rule grants or denies actions to principals on paths. A rule applies to a
+// request when the principal is listed in Principals or belongs to one of
+// Groups, and the namespace, path and action all match.
+type Rule struct {
+	ID     string `mapstructure:"id"`
+	Effect string `mapstructure:"effect"`
+	// Principals lists subjects; "*" matches everyone, including anonymous.
+	Principals []string `mapstructure:"principals"`
+	Groups     []string `mapstructure:"groups"`
+	// Namespaces lists the namespaces the rule covers; empty means all.
+	Namespaces []string `mapstructure:"namespaces"`
+	// Paths lists globs matched against file names: "*" matches within one
+	// path segment and "**" across segments. Empty means all paths.
+	Paths   []string `mapstructure:"paths"`
+	Actions []string `mapstructure:"actions"`
+}
+
+// Policy is the set of rules evaluated for every request.
+type Policy struct {
+	// Default is the effect applied when no rule matches: "deny"
+	// (deny-by-default) or "allow".
+	Default string `mapstructure:"default"`
+	// Groups defines groups by listing their members, in addition to the
+	// groups carried by credentials such as JWTs.
+	Groups map[string][]string `mapstructure:"groups"`
+	Rules  []Rule              `mapstructure:"rules"`
+}
+
+// LoadPolicy reads a policy from a YAML, JSON or TOML file.
+func LoadPolicy(path string) (*Policy, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetDefault("default", EffectDeny)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading authorization policy: %w", err)
+	}
+	var policy Policy
+	if err := v.Unmarshal(&policy); err != nil {
+		return nil, fmt.Errorf("authorization policy unmarshal error: %w", err)
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid authorization policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// Validate checks the policy for malformed rules.
+func (p *Policy) Validate() error {
+	var errs []error
+	if p.Default != EffectAllow && p.Default != EffectDeny {
+		errs = append(errs, fmt.Errorf("default must be %q or %q", EffectAllow, EffectDeny))
+	}
+	for i, rule := range p.Rules {
+		name := fmt.Sprintf("rule %d (%s)", i, rule.ID)
+		if rule.Effect != EffectAllow && rule.Effect != EffectDeny {
+			errs = append(errs, fmt.Errorf("%s: effect must be %q or %q", name, EffectAllow, EffectDeny))
+		}
+		if len(rule.Principals) == 0 && len(rule.Groups) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no principals or groups", name))
+		}
+		if len(rule.Actions) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no actions", name))
+		}
+		for _, action := range rule.Actions {
+			if action != wildcard && !slices.Contains(actions, action) {
+				errs = append(errs, fmt.Errorf("%s: unknown action %q", name, action))
+			}
+		}
+		for _, glob := range rule.Paths {
+			if err := validGlob(glob); err != nil {
+				errs = append(errs, fmt.Errorf("%s: path %q: %w", name, glob, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// validGlob checks that every segment of glob is a valid path.Match pattern.
+func validGlob(glob string) error {
+	for _, segment := range strings.Split(glob, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchGlob reports whether name matches glob, where "**" matches any
+// number of path segments, including none.
+func matchGlob(glob, name string) bool {
+	return matchSegments(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(glob, name []string) bool {
+	for len(glob) > 0 {
+		if glob[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(glob[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(glob[0], name[0]); !ok {
+			return false
+		}
+		glob, name = glob[1:], name[1:]
+	}
+	return len(name) == 0
+}
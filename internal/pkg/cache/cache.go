@@ -0,0 +1,429 @@
+// Package cache keeps the bytes of hot objects on local disk so that they
+// are not fetched from the storage backend on every request. Objects are
+// cached in fixed-size blocks keyed by their ETag, so ranged reads only
+// fetch the blocks they miss, and a stale block can never be served for a
+// newer version of an object.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// Options configures a caching storage client.
+type Options struct {
+	// Dir holds the cached blocks, which survive restarts.
+	Dir string
+	// MaxBytes caps the size of the cached blocks; the least recently used
+	// ones are evicted beyond it.
+	MaxBytes int64
+	// BlockSize is the size of the aligned blocks objects are cached in.
+	BlockSize int64
+}
+
+// cachingClient serves reads from the blocks cached on disk, filling the
+// cache from the wrapped client, and invalidates the cached blocks of the
+// objects written or removed through it.
+type cachingClient struct {
+	storage.Client
+	store     *store
+	blockSize int64
+	flights   flightGroup
+
+	mu sync.Mutex
+	// sizes holds the size of the versions of the objects seen, by object
+	// directory and ETag, so that reads pinned to an ETag need not stat
+	// the object first.
+	sizes map[string]map[string]int64
+}
+
+// NewStorageClient wraps client so that reads are served from a disk cache
+// configured by opts.
+func NewStorageClient(client storage.Client, opts Options) (storage.Client, error) {
+	if opts.BlockSize <= 0 {
+		return nil, errors.New("cache: block size must be positive")
+	}
+	store, err := openStore(opts.Dir, opts.MaxBytes)
+	if err != nil {
+		return nil, err
+	}
+	metrics.SetCacheSize(store.bytes)
+	return &cachingClient{
+		Client:    client,
+		store:     store,
+		blockSize: opts.BlockSize,
+		sizes:     make(map[string]map[string]int64),
+	}, nil
+}
+
+// Unwrap returns the wrapped client.
+func (c *cachingClient) Unwrap() storage.Client {
+	return c.Client
+}
+
+// objectDir returns the store directory of the object's blocks.
+func objectDir(bucketName, objectName string) string {
+	sum := sha256.Sum256([]byte(bucketName + "\x00" + objectName))
+	return hex.EncodeToString(sum[:16])
+}
+
+// blockName returns the store name of a block of a version of an object.
+func blockName(dir, etag string, index int64) string {
+	sum := sha256.Sum256([]byte(etag))
+	return dir + "/" + hex.EncodeToString(sum[:8]) + "-" + strconv.FormatInt(index, 10)
+}
+
+// maxSizes caps the number of objects whose sizes are remembered.
+const maxSizes = 1 << 16
+
+// remember records the size of a version of an object.
+func (c *cachingClient) remember(dir, etag string, size int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sizes[dir] == nil {
+		if len(c.sizes) >= maxSizes {
+			// Start over rather than grow without bound: forgotten sizes
+			// only cost a stat.
+			clear(c.sizes)
+		}
+		c.sizes[dir] = make(map[string]int64)
+	}
+	c.sizes[dir][etag] = size
+}
+
+// size returns the size of a version of an object, if seen.
+func (c *cachingClient) size(dir, etag string) (int64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	size, ok := c.sizes[dir][etag]
+	return size, ok
+}
+
+// invalidate forgets every cached version of an object.
+func (c *cachingClient) invalidate(bucketName, objectName string) {
+	dir := objectDir(bucketName, objectName)
+	c.mu.Lock()
+	delete(c.sizes, dir)
+	c.mu.Unlock()
+	c.store.removeDir(dir)
+}
+
+func (c *cachingClient) GetObjectInfo(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectInfoOptions,
+) (storage.ObjectInfo, error) {
+	info, err := c.Client.GetObjectInfo(ctx, bucketName, objectName, opts)
+	if err == nil && info.ETag != "" {
+		c.remember(objectDir(bucketName, objectName), info.ETag, info.Size)
+	}
+	return info, err
+}
+
+// GetObject serves the object, or the byte range of it selected by opts,
+// from the cache. Reads pinned to an ETag whose size is known are served
+// without calling the backend when their blocks are cached; others stat
+// the object first to learn its current ETag.
+func (c *cachingClient) GetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectOptions,
+) (io.ReadCloser, error) {
+	dir := objectDir(bucketName, objectName)
+	etag := opts.MatchETag
+	size, known := c.size(dir, etag)
+	if etag == "" || !known {
+		info, err := c.GetObjectInfo(ctx, bucketName, objectName, storage.GetObjectInfoOptions{
+			VersionID: opts.VersionID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if etag != "" && info.ETag != etag {
+			return nil, &storage.Error{Kind: storage.ErrModified, Op: "getting object", Err: fmt.Errorf("etag is %q", info.ETag)}
+		}
+		if info.ETag == "" {
+			return c.Client.GetObject(ctx, bucketName, objectName, opts)
+		}
+		etag, size = info.ETag, info.Size
+	}
+	start, end := opts.Start, opts.End
+	if start < 0 || end < 0 {
+		start, end = 0, size-1
+	} else {
+		end = min(end, size-1)
+		if start > end {
+			return nil, &storage.Error{
+				Kind: storage.ErrInvalidRange,
+				Op:   "getting object",
+				Err:  fmt.Errorf("range %d-%d of %d bytes", opts.Start, opts.End, size),
+			}
+		}
+	}
+	r := &blockReader{
+		ctx:        ctx,
+		client:     c,
+		bucketName: bucketName,
+		objectName: objectName,
+		versionID:  opts.VersionID,
+		dir:        dir,
+		etag:       etag,
+		size:       size,
+		pos:        start,
+		end:        end,
+	}
+	// Open the first block now, so that a missing or modified object fails
+	// the call rather than the first read.
+	if start <= end {
+		if err := r.next(); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// GetObjectWithRange serves the byte range from the cache. Ranges that are
+// not a plain start-end pair are passed through to the backend.
+func (c *cachingClient) GetObjectWithRange(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	start int64,
+	end int64,
+) (io.ReadCloser, error) {
+	if start < 0 || end < start {
+		return c.Client.GetObjectWithRange(ctx, bucketName, objectName, start, end)
+	}
+	return c.GetObject(ctx, bucketName, objectName, storage.GetObjectOptions{Start: start, End: end})
+}
+
+// block opens the block of a version of an object, fetching it into the
+// cache on a miss. Concurrent misses of the same block fetch it once.
+func (c *cachingClient) block(ctx context.Context, r *blockReader, index int64) (io.ReadCloser, error) {
+	name := blockName(r.dir, r.etag, index)
+	if f, ok := c.store.open(name); ok {
+		metrics.RecordCacheLookup(true)
+		return f, nil
+	}
+	metrics.RecordCacheLookup(false)
+	start := index * c.blockSize
+	opts := storage.GetObjectOptions{
+		Start:     start,
+		End:       min(start+c.blockSize, r.size) - 1,
+		VersionID: r.versionID,
+		MatchETag: r.etag,
+	}
+	var fillErr error
+	shared, err := c.flights.do(ctx, name, func() error {
+		// The fetch serves every caller waiting for the block: it must not
+		// fail because the first one went away.
+		body, err := c.Client.GetObject(context.WithoutCancel(ctx), r.bucketName, r.objectName, opts)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+		src := &errorReader{Reader: body}
+		var shortErr error
+		err = c.store.put(name, func(w io.Writer) error {
+			n, err := io.Copy(w, src)
+			if err == nil && n != opts.End-opts.Start+1 {
+				// A truncated response must not be cached as the block.
+				shortErr = &storage.Error{
+					Kind: storage.ErrUnavailable,
+					Op:   "reading object",
+					Err:  fmt.Errorf("block %d-%d ended after %d bytes", opts.Start, opts.End, n),
+				}
+				return shortErr
+			}
+			return err
+		})
+		if src.err != nil {
+			return storage.Translate("reading object", src.err)
+		}
+		if shortErr != nil {
+			return shortErr
+		}
+		fillErr = err
+		return nil
+	})
+	if shared {
+		metrics.RecordCacheCoalesced()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if f, ok := c.store.open(name); ok {
+		return f, nil
+	}
+	// The cache could not keep the block: read it from the backend.
+	if fillErr != nil {
+		metrics.RecordCacheFillError()
+		logging.FromContext(ctx).Warn("filling storage cache", "object", r.objectName, "error", fillErr)
+	}
+	return c.Client.GetObject(ctx, r.bucketName, r.objectName, opts)
+}
+
+func (c *cachingClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	size int64,
+	opts storage.PutObjectOptions,
+) (storage.ObjectInfo, error) {
+	info, err := c.Client.PutObject(ctx, bucketName, objectName, reader, size, opts)
+	if err == nil {
+		c.invalidate(bucketName, objectName)
+	}
+	return info, err
+}
+
+func (c *cachingClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts storage.CopyObjectOptions,
+) (storage.ObjectInfo, error) {
+	info, err := c.Client.CopyObject(ctx, bucketName, srcObjectName, dstObjectName, opts)
+	if err == nil {
+		c.invalidate(bucketName, dstObjectName)
+	}
+	return info, err
+}
+
+func (c *cachingClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.RemoveObjectOptions,
+) error {
+	err := c.Client.RemoveObject(ctx, bucketName, objectName, opts)
+	if err == nil {
+		c.invalidate(bucketName, objectName)
+	}
+	return err
+}
+
+// blockReader reads a byte range of a version of an object block by block.
+type blockReader struct {
+	ctx        context.Context
+	client     *cachingClient
+	bucketName string
+	objectName string
+	versionID  string
+	dir        string
+	etag       string
+	size       int64
+	// pos is the next byte to read and end the last one.
+	pos, end int64
+	// body reads the current block from pos to blockEnd, the end of the
+	// block or the range.
+	body     io.ReadCloser
+	blockEnd int64
+}
+
+func (r *blockReader) Read(p []byte) (int, error) {
+	for {
+		if r.pos > r.end {
+			return 0, io.EOF
+		}
+		if r.body == nil {
+			if err := r.next(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := r.body.Read(p)
+		r.pos += int64(n)
+		if errors.Is(err, io.EOF) {
+			r.body.Close()
+			r.body = nil
+			err = nil
+			if r.pos <= r.blockEnd {
+				// Reopening the block would end at the same place.
+				err = io.ErrUnexpectedEOF
+			}
+		}
+		if n > 0 || err != nil {
+			return n, err
+		}
+	}
+}
+
+// next opens the block holding pos, positioned at pos.
+func (r *blockReader) next() error {
+	blockSize := r.client.blockSize
+	index := r.pos / blockSize
+	body, err := r.client.block(r.ctx, r, index)
+	if err != nil {
+		return err
+	}
+	offset := r.pos - index*blockSize
+	length := min(r.end+1, (index+1)*blockSize) - r.pos
+	r.blockEnd = r.pos + length - 1
+	if f, ok := body.(*os.File); ok {
+		r.body = &sectionFile{Reader: io.NewSectionReader(f, offset, length), file: f}
+		return nil
+	}
+	// A block read from the backend starts at the beginning of the block.
+	if _, err := io.CopyN(io.Discard, body, offset); err != nil {
+		body.Close()
+		return storage.Translate("reading object", err)
+	}
+	r.body = &limitedBody{Reader: io.LimitReader(body, length), body: body}
+	return nil
+}
+
+func (r *blockReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
+
+// errorReader records the error of the reader, other than io.EOF.
+type errorReader struct {
+	io.Reader
+	err error
+}
+
+func (e *errorReader) Read(p []byte) (int, error) {
+	n, err := e.Reader.Read(p)
+	if err != nil && !errors.Is(err, io.EOF) {
+		e.err = err
+	}
+	return n, err
+}
+
+// sectionFile reads a section of a file and closes the file.
+type sectionFile struct {
+	io.Reader
+	file *os.File
+}
+
+func (s *sectionFile) Close() error {
+	return s.file.Close()
+}
+
+// limitedBody reads part of a body and closes the body.
+type limitedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup coalesces concurrent calls with the same key into one.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flight
+}
+
+type flight struct {
+	done chan struct{}
+	err  error
+}
+
+// do calls fn, unless a call with the same key is in flight, in which case
+// it waits for that call, or for ctx to be done, and returns its error.
+// shared reports whether the result came from another caller's call.
+func (g *flightGroup) do(ctx context.Context, key string, fn func() error) (shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flight)
+	}
+	if f, ok := g.calls[key]; ok {
+		g.mu.Unlock()
+		select {
+		case <-f.done:
+			return true, f.err
+		case <-ctx.Done():
+			return true, ctx.Err()
+		}
+	}
+	f := &flight{done: make(chan struct{})}
+	g.calls[key] = f
+	g.mu.Unlock()
+
+	f.err = fn()
+	g.mu.Lock()
+	delete(g.calls, key)
+	g.mu.Unlock()
+	close(f.done)
+	return false, f.err
+}
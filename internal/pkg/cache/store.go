@@ -0,0 +1,193 @@
+package cache
+
+import (
+	"container/list"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+)
+
+// store keeps cached blocks as files under its directory, one directory per
+// object, and evicts the least recently used blocks once they take more
+// than maxBytes.
+type store struct {
+	dir      string
+	maxBytes int64
+
+	mu      sync.Mutex
+	lru     *list.List // of *block, most recently used first
+	entries map[string]*list.Element
+	size    int64
+}
+
+// block is a cached block file, named by its path relative to the store.
+type block struct {
+	name string
+	size int64
+}
+
+const tmpDir = ".tmp"
+
+// openStore opens the store in dir, keeping the blocks cached by a previous
+// run, oldest first in line for eviction.
+func openStore(dir string, maxBytes int64) (*store, error) {
+	if err := os.RemoveAll(filepath.Join(dir, tmpDir)); err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, tmpDir), 0o755); err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	s := &store{
+		dir:      dir,
+		maxBytes: maxBytes,
+		lru:      list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+	type found struct {
+		block
+		modTime time.Time
+	}
+	var blocks []found
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if d.Name() == tmpDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		blocks = append(blocks, found{block{name: filepath.ToSlash(name), size: info.Size()}, info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cache: %w", err)
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i].modTime.Before(blocks[j].modTime) })
+	for _, b := range blocks {
+		s.add(&b.block)
+	}
+	s.mu.Lock()
+	s.evict()
+	s.mu.Unlock()
+	return s, nil
+}
+
+// add records a block as the most recently used. The caller holds s.mu,
+// unless the store is not shared yet.
+func (s *store) add(b *block) {
+	if el, ok := s.entries[b.name]; ok {
+		s.size -= el.Value.(*block).size
+		s.lru.Remove(el)
+	}
+	s.entries[b.name] = s.lru.PushFront(b)
+	s.size += b.size
+}
+
+// open opens a cached block, marking it as used. It returns false when the
+// block is not cached.
+func (s *store) open(name string) (*os.File, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[name]
+	if !ok {
+		return nil, false
+	}
+	f, err := os.Open(filepath.Join(s.dir, filepath.FromSlash(name)))
+	if err != nil {
+		s.remove(el)
+		return nil, false
+	}
+	s.lru.MoveToFront(el)
+	return f, true
+}
+
+// put caches the block written by write, evicting others as needed.
+func (s *store) put(name string, write func(w io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Join(s.dir, tmpDir), "block-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	err = write(tmp)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(s.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+	s.add(&block{name: name, size: info.Size()})
+	s.evict()
+	return nil
+}
+
+// evict removes the least recently used blocks until the store fits in
+// maxBytes. The caller holds s.mu.
+func (s *store) evict() {
+	for s.size > s.maxBytes && s.lru.Len() > 0 {
+		s.remove(s.lru.Back())
+		metrics.RecordCacheEviction()
+	}
+}
+
+// remove deletes a block. The caller holds s.mu.
+func (s *store) remove(el *list.Element) {
+	b := el.Value.(*block)
+	s.lru.Remove(el)
+	delete(s.entries, b.name)
+	s.size -= b.size
+	// A file that cannot be removed is accounted for again on restart.
+	os.Remove(filepath.Join(s.dir, filepath.FromSlash(b.name)))
+}
+
+// removeDir deletes every block under the directory dir of the store.
+func (s *store) removeDir(dir string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	prefix := dir + "/"
+	for el := s.lru.Front(); el != nil; {
+		next := el.Next()
+		if strings.HasPrefix(el.Value.(*block).name, prefix) {
+			s.remove(el)
+		}
+		el = next
+	}
+	os.RemoveAll(filepath.Join(s.dir, dir))
+}
+
+// bytes returns the size of the cached blocks.
+func (s *store) bytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.size
+}
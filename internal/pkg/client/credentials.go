@@ -0,0 +1,53 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"connectrpc.com/connect"
+)
+
+// WithAPIKey authenticates every request with a static API key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.header.Set("X-API-Key", key)
+	}
+}
+
+// WithBearerToken authenticates every request with a bearer token, e.g. a JWT.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// headerInterceptor adds fixed headers, such as credentials, to every
+// outgoing request.
+type headerInterceptor struct {
+	header http.Header
+}
+
+func (i headerInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		i.apply(req.Header())
+		return next(ctx, req)
+	}
+}
+
+func (i headerInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		i.apply(conn.RequestHeader())
+		return conn
+	}
+}
+
+func (i headerInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+func (i headerInterceptor) apply(header http.Header) {
+	for key, values := range i.header {
+		header[key] = values
+	}
+}
@@ -0,0 +1,131 @@
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+)
+
+// Download writes length bytes of fileName starting at offset to w and returns
+// the number of bytes written. A negative length reads to the end of the file.
+//
+// Offsets always refer to the plaintext. For encrypted files only the
+// segments covering the requested range are streamed and decrypted.
+func (c *Client) Download(ctx context.Context, fileName string, w io.Writer, offset, length int64) (int64, error) {
+	info, cipher, err := c.stat(ctx, fileName)
+	if err != nil {
+		return 0, err
+	}
+	if info.Encrypted && cipher == nil {
+		return 0, errors.New("file is encrypted: an encryption key is required")
+	}
+	if offset < 0 || offset > info.Size {
+		return 0, fmt.Errorf("offset %d is outside of file size %d", offset, info.Size)
+	}
+	if length < 0 || offset+length > info.Size {
+		length = info.Size - offset
+	}
+	if length == 0 {
+		return 0, nil
+	}
+	// Cancelling stops the server stream once the requested range is read.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if cipher == nil {
+		src, err := c.openStream(ctx, fileName, offset)
+		if err != nil {
+			return 0, err
+		}
+		defer src.Close()
+		return copyN(w, src, length)
+	}
+	ctStart, _, skip, err := cipher.CiphertextRange(offset, offset+length-1, info.StoredSize)
+	if err != nil {
+		return 0, err
+	}
+	src, err := c.openStream(ctx, fileName, ctStart)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+	plain, err := cipher.NewReader(src, ctStart, info.StoredSize)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := io.CopyN(io.Discard, plain, skip); err != nil {
+		return 0, err
+	}
+	return copyN(w, plain, length)
+}
+
+// copyN copies length bytes from src to w, failing with
+// io.ErrUnexpectedEOF if src ends first.
+func copyN(w io.Writer, src io.Reader, length int64) (int64, error) {
+	n, err := io.Copy(w, io.LimitReader(src, length))
+	if err == nil && n < length {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+// streamReader adapts a StreamFile response stream to an io.Reader.
+type streamReader struct {
+	stream *connect.ServerStreamForClient[transferv1.StreamFileResponse]
+	buf    []byte
+	offset int64
+}
+
+// openStream starts a StreamFile call at the given byte offset.
+func (c *Client) openStream(ctx context.Context, fileName string, offset int64) (*streamReader, error) {
+	stream, err := c.rpc.StreamFile(ctx, connect.NewRequest(&transferv1.StreamFileRequest{
+		FileName:      fileName,
+		Namespace:     c.namespace,
+		Start:         offset,
+		ChunkSize:     int64(c.chunkSize),
+		CanDecompress: true,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return &streamReader{stream: stream, offset: offset}, nil
+}
+
+func (r *streamReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if !r.stream.Receive() {
+			if err := r.stream.Err(); err != nil {
+				return 0, err
+			}
+			return 0, io.EOF
+		}
+		msg := r.stream.Msg()
+		if msg.Offset != r.offset {
+			return 0, fmt.Errorf("unexpected chunk offset %d, expected %d", msg.Offset, r.offset)
+		}
+		chunk := msg.Chunk
+		if msg.Compressed {
+			gz, err := gzip.NewReader(bytes.NewReader(chunk))
+			if err != nil {
+				return 0, fmt.Errorf("decompressing chunk: %w", err)
+			}
+			if chunk, err = io.ReadAll(gz); err != nil {
+				return 0, fmt.Errorf("decompressing chunk: %w", err)
+			}
+		}
+		r.buf = chunk
+		r.offset += int64(len(chunk))
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *streamReader) Close() error {
+	return r.stream.Close()
+}
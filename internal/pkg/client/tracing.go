@@ -0,0 +1,32 @@
+package client
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// traceInterceptor propagates the trace context of each call to the server,
+// using the globally configured OpenTelemetry propagator.
+type traceInterceptor struct{}
+
+func (traceInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header()))
+		return next(ctx, req)
+	}
+}
+
+func (traceInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(conn.RequestHeader()))
+		return conn
+	}
+}
+
+func (traceInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
@@ -0,0 +1,66 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+)
+
+// TrashEntry describes a deleted file that can still be undeleted.
+type TrashEntry struct {
+	ID        string
+	FileName  string
+	Size      int64
+	DeletedAt time.Time
+	ExpiresAt time.Time
+}
+
+func toTrashEntry(entry *transferv1.TrashEntry) TrashEntry {
+	return TrashEntry{
+		ID:        entry.TrashId,
+		FileName:  entry.FileName,
+		Size:      entry.Size,
+		DeletedAt: time.Unix(entry.DeletedAt, 0),
+		ExpiresAt: time.Unix(entry.ExpiresAt, 0),
+	}
+}
+
+// Delete moves a file into the trash and returns its trash entry.
+func (c *Client) Delete(ctx context.Context, fileName string) (TrashEntry, error) {
+	res, err := c.rpc.DeleteFile(ctx, connect.NewRequest(&transferv1.DeleteFileRequest{
+		FileName:  fileName,
+		Namespace: c.namespace,
+	}))
+	if err != nil {
+		return TrashEntry{}, err
+	}
+	return toTrashEntry(res.Msg.Entry), nil
+}
+
+// ListTrash returns the deleted files in the namespace, most recent first.
+func (c *Client) ListTrash(ctx context.Context) ([]TrashEntry, error) {
+	res, err := c.rpc.ListTrash(ctx, connect.NewRequest(&transferv1.ListTrashRequest{
+		Namespace: c.namespace,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]TrashEntry, 0, len(res.Msg.Entries))
+	for _, entry := range res.Msg.Entries {
+		entries = append(entries, toTrashEntry(entry))
+	}
+	return entries, nil
+}
+
+// Undelete restores a file from the trash. An empty trashID restores the
+// most recent deletion of the file.
+func (c *Client) Undelete(ctx context.Context, fileName, trashID string) error {
+	_, err := c.rpc.Undelete(ctx, connect.NewRequest(&transferv1.UndeleteRequest{
+		FileName:  fileName,
+		Namespace: c.namespace,
+		TrashId:   trashID,
+	}))
+	return err
+}
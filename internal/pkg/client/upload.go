@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/envelope"
+)
+
+// UploadOption configures a single upload.
+type UploadOption func(*uploadOptions)
+
+type uploadOptions struct {
+	tags map[string]string
+}
+
+// WithUploadTags attaches tags to the uploaded object, e.g. to select it in
+// the server's lifecycle rules.
+func WithUploadTags(tags map[string]string) UploadOption {
+	return func(o *uploadOptions) {
+		o.tags = tags
+	}
+}
+
+// Upload streams the contents of r to the server as fileName and returns the
+// number of bytes stored.
+//
+// When an encryption key is configured the contents are encrypted before they
+// leave the process, and only the wrapped file key is sent as object metadata.
+// The returned size is then the size of the ciphertext.
+func (c *Client) Upload(ctx context.Context, fileName string, r io.Reader, opts ...UploadOption) (int64, error) {
+	var uploadOpts uploadOptions
+	for _, opt := range opts {
+		opt(&uploadOpts)
+	}
+	metadata := map[string]string{}
+	if c.key != nil {
+		encrypted, header, err := encrypt(*c.key, r)
+		if err != nil {
+			return 0, err
+		}
+		defer encrypted.Close()
+		metadata[envelope.MetadataKey] = header
+		r = encrypted
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream := c.rpc.UploadFile(ctx)
+	type uploadResult struct {
+		received int64
+		err      error
+	}
+	done := make(chan uploadResult, 1)
+	// Responses are drained concurrently so progress updates from the server
+	// never block it while we are still sending.
+	go func() {
+		for {
+			res, err := stream.Receive()
+			if errors.Is(err, io.EOF) {
+				done <- uploadResult{err: errors.New("upload stream closed without a result")}
+				return
+			}
+			if err != nil {
+				done <- uploadResult{err: err}
+				return
+			}
+			if res.Success {
+				done <- uploadResult{received: res.BytesReceived}
+				return
+			}
+			if res.ErrorMessage != "" {
+				done <- uploadResult{err: errors.New(res.ErrorMessage)}
+				return
+			}
+		}
+	}()
+	if err := c.sendChunks(stream.Send, fileName, metadata, uploadOpts.tags, r); err != nil {
+		cancel()
+		return 0, err
+	}
+	if err := stream.CloseRequest(); err != nil {
+		return 0, err
+	}
+	res := <-done
+	if err := stream.CloseResponse(); err != nil && res.err == nil {
+		res.err = err
+	}
+	return res.received, res.err
+}
+
+// sendChunks reads r in chunks and sends them with send. The first message
+// always carries the file name, metadata and tags, even for an empty file.
+func (c *Client) sendChunks(
+	send func(*transferv1.UploadFileRequest) error,
+	fileName string,
+	metadata map[string]string,
+	tags map[string]string,
+	r io.Reader,
+) error {
+	buf := make([]byte, c.chunkSize)
+	var offset int64
+	for first := true; ; first = false {
+		n, readErr := io.ReadFull(r, buf)
+		if readErr != nil && !errors.Is(readErr, io.EOF) && !errors.Is(readErr, io.ErrUnexpectedEOF) {
+			return fmt.Errorf("reading upload: %w", readErr)
+		}
+		if n > 0 || first {
+			msg := &transferv1.UploadFileRequest{
+				Chunk:  buf[:n],
+				Offset: offset,
+			}
+			if first {
+				msg.FileName = fileName
+				msg.Metadata = metadata
+				msg.Tags = tags
+				msg.Namespace = c.namespace
+			}
+			// A send error means the server closed the stream; the cause is
+			// reported by the receiving side.
+			if err := send(msg); err != nil {
+				return nil
+			}
+			offset += int64(n)
+		}
+		if readErr != nil {
+			return nil
+		}
+	}
+}
+
+// encrypt returns a reader producing the encryption of r and the encoded
+// envelope header. Closing the reader stops the encryption goroutine.
+func encrypt(key envelope.Key, r io.Reader) (io.ReadCloser, string, error) {
+	cipher, header, err := envelope.NewCipher(key)
+	if err != nil {
+		return nil, "", err
+	}
+	pr, pw := io.Pipe()
+	go func() {
+		w, err := cipher.NewWriter(pw)
+		if err == nil {
+			_, err = io.Copy(w, r)
+		}
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, header, nil
+}
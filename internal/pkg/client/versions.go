@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+)
+
+// Version describes one stored version of a file.
+type Version struct {
+	ID             string
+	Size           int64
+	ETag           string
+	LastModified   time.Time
+	IsLatest       bool
+	IsDeleteMarker bool
+}
+
+// Versions returns the version history of a file, newest first.
+func (c *Client) Versions(ctx context.Context, fileName string) ([]Version, error) {
+	res, err := c.rpc.GetFileInfo(ctx, connect.NewRequest(&transferv1.GetFileInfoRequest{
+		FileName:        fileName,
+		Namespace:       c.namespace,
+		IncludeVersions: true,
+	}))
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]Version, 0, len(res.Msg.Versions))
+	for _, v := range res.Msg.Versions {
+		versions = append(versions, Version{
+			ID:             v.VersionId,
+			Size:           v.Size,
+			ETag:           v.Etag,
+			LastModified:   time.Unix(v.LastModified, 0),
+			IsLatest:       v.IsLatest,
+			IsDeleteMarker: v.IsDeleteMarker,
+		})
+	}
+	return versions, nil
+}
+
+// RestoreVersion makes versionID the latest version of the file and returns
+// the ID of the newly created version.
+func (c *Client) RestoreVersion(ctx context.Context, fileName, versionID string) (string, error) {
+	res, err := c.rpc.RestoreVersion(ctx, connect.NewRequest(&transferv1.RestoreVersionRequest{
+		FileName:  fileName,
+		Namespace: c.namespace,
+		VersionId: versionID,
+	}))
+	if err != nil {
+		return "", err
+	}
+	return res.Msg.VersionId, nil
+}
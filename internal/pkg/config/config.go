@@ -1,17 +1,21 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
 // Config holds configuration values required to connect to MinIO.
-// Each field is populated from environment variables.
+// Each field is populated from environment variables, which override the
+// settings of an optional configuration file.
 type Config struct {
 	HTTPServerPort          int    `mapstructure:"HTTP_SERVER_PORT"`
 	ConnectRPCServerAddress int    `mapstructure:"CONNECT_RPC_SERVER_PORT"`
@@ -20,15 +24,230 @@ type Config struct {
 	MinioAccessKeyID        string `mapstructure:"MINIO_ACCESS_KEY_ID"`
 	MinioAccessKey          string `mapstructure:"MINIO_ACCESS_KEY"`
 	MinioUseSSL             bool   `mapstructure:"MINIO_USE_SSL"`
+	// MinioReplicaHosts lists MinIO endpoints holding replicas of the
+	// buckets. Reads go to the healthiest, fastest endpoint; writes go to
+	// MINIO_HOST and fail over to the replicas when it is unreachable.
+	MinioReplicaHosts []string `mapstructure:"MINIO_REPLICA_HOSTS"`
+	// MinioReplicaAccessKeyID and MinioReplicaAccessKey are the replicas'
+	// credentials, when they differ from the primary's.
+	MinioReplicaAccessKeyID string `mapstructure:"MINIO_REPLICA_ACCESS_KEY_ID"`
+	MinioReplicaAccessKey   string `mapstructure:"MINIO_REPLICA_ACCESS_KEY"`
 	BucketName              string `mapstructure:"BUCKET_NAME"`
+	// StorageTimeout bounds every storage call but uploads, and the opening
+	// of downloads.
+	StorageTimeout time.Duration `mapstructure:"STORAGE_TIMEOUT"`
+	// StorageRetryMaxAttempts is how many times an idempotent storage call
+	// is attempted when it fails transiently.
+	StorageRetryMaxAttempts int `mapstructure:"STORAGE_RETRY_MAX_ATTEMPTS"`
+	// StorageRetryBaseDelay and StorageRetryMaxDelay bound the jittered,
+	// exponential backoff between attempts.
+	StorageRetryBaseDelay time.Duration `mapstructure:"STORAGE_RETRY_BASE_DELAY"`
+	StorageRetryMaxDelay  time.Duration `mapstructure:"STORAGE_RETRY_MAX_DELAY"`
+	// StorageBreakerThreshold is how many consecutive transient failures of
+	// a storage backend make calls to it fail fast for
+	// StorageBreakerCooldown. Zero disables the circuit breaker.
+	StorageBreakerThreshold int           `mapstructure:"STORAGE_BREAKER_THRESHOLD"`
+	StorageBreakerCooldown  time.Duration `mapstructure:"STORAGE_BREAKER_COOLDOWN"`
+	// StorageMaxReconnects is how many times a download is resumed from
+	// the last delivered byte after the storage backend failed mid-stream.
+	StorageMaxReconnects int `mapstructure:"STORAGE_MAX_RECONNECTS"`
+	// StorageHedgeDelay is how long a read may wait for a storage endpoint
+	// before it is duplicated to the next one, with replicas. Zero disables
+	// hedging.
+	StorageHedgeDelay time.Duration `mapstructure:"STORAGE_HEDGE_DELAY"`
+	// Namespaces lists additional namespaces (one bucket each) clients may
+	// address. The BucketName namespace is always available and is the default.
+	Namespaces []string `mapstructure:"NAMESPACES"`
+	// VersionedNamespaces lists the namespaces that keep every uploaded version.
+	VersionedNamespaces []string `mapstructure:"VERSIONED_NAMESPACES"`
+	// TrashRetention is how long deleted files are kept before being purged.
+	TrashRetention time.Duration `mapstructure:"TRASH_RETENTION"`
+	// TrashPurgeInterval is how often expired trash entries are purged.
+	TrashPurgeInterval time.Duration `mapstructure:"TRASH_PURGE_INTERVAL"`
+	// LifecycleConfigFile points to the lifecycle rules file; lifecycle
+	// management is disabled when empty.
+	LifecycleConfigFile string `mapstructure:"LIFECYCLE_CONFIG_FILE"`
+	// ReplicationNamespaces lists the namespaces mirrored to the replication
+	// target; replication is disabled when empty.
+	ReplicationNamespaces []string `mapstructure:"REPLICATION_NAMESPACES"`
+	// ReplicationMinioHost and its credentials select a MinIO replication
+	// target; ReplicationDirectory selects a local directory instead.
+	ReplicationMinioHost        string `mapstructure:"REPLICATION_MINIO_HOST"`
+	ReplicationMinioAccessKeyID string `mapstructure:"REPLICATION_MINIO_ACCESS_KEY_ID"`
+	ReplicationMinioAccessKey   string `mapstructure:"REPLICATION_MINIO_ACCESS_KEY"`
+	ReplicationMinioUseSSL      bool   `mapstructure:"REPLICATION_MINIO_USE_SSL"`
+	ReplicationDirectory        string `mapstructure:"REPLICATION_DIRECTORY"`
+	// ReplicationQueueDir holds the objects still to replicate, so that
+	// they survive a restart.
+	ReplicationQueueDir string `mapstructure:"REPLICATION_QUEUE_DIR"`
+	// ReplicationWorkers is how many objects are replicated concurrently.
+	ReplicationWorkers int `mapstructure:"REPLICATION_WORKERS"`
+	// ReplicationRetryMaxDelay caps the backoff between attempts to
+	// replicate an object.
+	ReplicationRetryMaxDelay time.Duration `mapstructure:"REPLICATION_RETRY_MAX_DELAY"`
+	// ReplicationReconcileInterval is how often the replicas are compared
+	// with the source to repair divergence. Zero disables reconciliation.
+	// It bounds the replication lag of objects uploaded through presigned
+	// URLs, which the server only watches for while it has capacity.
+	ReplicationReconcileInterval time.Duration `mapstructure:"REPLICATION_RECONCILE_INTERVAL"`
+	// CacheDir holds the local disk cache of object bytes; caching is
+	// disabled when empty.
+	CacheDir string `mapstructure:"CACHE_DIR"`
+	// CacheMaxSizeMB caps the size of the cache; the least recently used
+	// blocks are evicted beyond it.
+	CacheMaxSizeMB int `mapstructure:"CACHE_MAX_SIZE_MB"`
+	// CacheBlockSizeKB is the size of the aligned blocks objects are
+	// cached in, so that ranged reads only fetch the blocks they miss.
+	CacheBlockSizeKB int `mapstructure:"CACHE_BLOCK_SIZE_KB"`
+	// ReadaheadDepth caps the number of chunks of a download fetched from
+	// storage concurrently, ahead of the client. Zero disables read-ahead.
+	ReadaheadDepth int `mapstructure:"READAHEAD_DEPTH"`
+	// ReadaheadChunkSizeKB is the size of the chunks downloads are fetched
+	// in with read-ahead.
+	ReadaheadChunkSizeKB int `mapstructure:"READAHEAD_CHUNK_SIZE_KB"`
+	// ReadaheadMaxMemoryMB caps the memory of the chunks fetched ahead by
+	// every download together; downloads fetch no further ahead beyond it.
+	ReadaheadMaxMemoryMB int `mapstructure:"READAHEAD_MAX_MEMORY_MB"`
+	// UpstreamURL is the HTTP API of another file-streamer this instance
+	// serves files from, read-only, instead of MinIO: an edge in front of
+	// an origin. Set CacheDir along with it to keep hot files at the edge.
+	UpstreamURL string `mapstructure:"UPSTREAM_URL"`
+	// UpstreamConnectURL is the origin's ConnectRPC server, used to list
+	// files. It defaults to UpstreamURL.
+	UpstreamConnectURL string `mapstructure:"UPSTREAM_CONNECT_URL"`
+	// UpstreamAPIKey authenticates the edge with the origin.
+	UpstreamAPIKey string `mapstructure:"UPSTREAM_API_KEY"`
+	// UpstreamMetadataTTL is how long the metadata of a file is served
+	// before being revalidated with the origin by its ETag.
+	UpstreamMetadataTTL time.Duration `mapstructure:"UPSTREAM_METADATA_TTL"`
+	// UpstreamShieldURL is the HTTP API of an origin shield the edge reads
+	// through, falling back to UpstreamURL when it is unreachable.
+	UpstreamShieldURL string `mapstructure:"UPSTREAM_SHIELD_URL"`
+	// ShareLinkSecret signs share link tokens. When empty a random secret is
+	// generated at startup, so links stop working after a restart.
+	ShareLinkSecret string `mapstructure:"SHARE_LINK_SECRET"`
+	// PublicURL is the externally reachable URL of the HTTP server, used to
+	// build absolute share link URLs.
+	PublicURL string `mapstructure:"PUBLIC_URL"`
+	// AuditLogFile is the file audit events are appended to; they are
+	// written to stderr when empty.
+	AuditLogFile string `mapstructure:"AUDIT_LOG_FILE"`
+	// AuthAPIKeys lists static API keys as "subject=key" entries.
+	AuthAPIKeys []string `mapstructure:"AUTH_API_KEYS"`
+	// AuthJWKSFile is a JSON Web Key Set used to verify bearer JWTs.
+	AuthJWKSFile string `mapstructure:"AUTH_JWKS_FILE"`
+	// AuthJWTIssuer and AuthJWTAudience, when set, must match the token's
+	// "iss" and "aud" claims.
+	AuthJWTIssuer   string `mapstructure:"AUTH_JWT_ISSUER"`
+	AuthJWTAudience string `mapstructure:"AUTH_JWT_AUDIENCE"`
+	// AuthJWTGroupsClaim names the JWT claim listing the principal's groups.
+	AuthJWTGroupsClaim string `mapstructure:"AUTH_JWT_GROUPS_CLAIM"`
+	// AuthMTLSCAFile holds the CAs whose client certificates are accepted.
+	AuthMTLSCAFile string `mapstructure:"AUTH_MTLS_CA_FILE"`
+	// AuthAllowAnonymous lets requests without credentials through when
+	// authentication is configured.
+	AuthAllowAnonymous bool `mapstructure:"AUTH_ALLOW_ANONYMOUS"`
+	// AuthzPolicyFile points to the authorization policy; every
+	// authenticated request is allowed when empty.
+	AuthzPolicyFile string `mapstructure:"AUTHZ_POLICY_FILE"`
+	// LimitsConfigFile points to the rate and bandwidth limits, which are
+	// reloaded when the file changes; nothing is limited when empty.
+	LimitsConfigFile string `mapstructure:"LIMITS_CONFIG_FILE"`
+	// AdmissionMaxUploads, AdmissionMaxDownloads and AdmissionMaxMetadata
+	// cap the concurrent uploads, downloads and metadata calls; zero means
+	// unlimited.
+	AdmissionMaxUploads   int `mapstructure:"ADMISSION_MAX_UPLOADS"`
+	AdmissionMaxDownloads int `mapstructure:"ADMISSION_MAX_DOWNLOADS"`
+	AdmissionMaxMetadata  int `mapstructure:"ADMISSION_MAX_METADATA"`
+	// AdmissionMaxQueue is how many requests of each kind may wait for a
+	// slot before new ones are rejected.
+	AdmissionMaxQueue int `mapstructure:"ADMISSION_MAX_QUEUE"`
+	// AdmissionMaxWait is how long a request may wait for a slot.
+	AdmissionMaxWait time.Duration `mapstructure:"ADMISSION_MAX_WAIT"`
+	// TracingExporter selects where trace spans are sent: "otlp", "stdout"
+	// or empty to disable tracing.
+	TracingExporter string `mapstructure:"TRACING_EXPORTER"`
+	// TracingOTLPEndpoint is the host:port of the OTLP/HTTP collector.
+	TracingOTLPEndpoint string `mapstructure:"TRACING_OTLP_ENDPOINT"`
+	// TracingOTLPInsecure sends spans to the collector without TLS.
+	TracingOTLPInsecure bool `mapstructure:"TRACING_OTLP_INSECURE"`
+	// TracingSampleRatio is the fraction of new traces recorded.
+	TracingSampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"`
+	// LogLevel is the minimum level logged: "debug", "info", "warn" or
+	// "error".
+	LogLevel string `mapstructure:"LOG_LEVEL"`
+	// LogFormat is "text" or "json".
+	LogFormat string `mapstructure:"LOG_FORMAT"`
+	// AccessLogFormat is the format of the access log written to stdout:
+	// "json", "combined" or "off".
+	AccessLogFormat string `mapstructure:"ACCESS_LOG_FORMAT"`
+	// ShutdownGracePeriod is how long in-flight requests and streams may
+	// run after shutdown starts before they are aborted.
+	ShutdownGracePeriod time.Duration `mapstructure:"SHUTDOWN_GRACE_PERIOD"`
+	// ShutdownDrainDelay is how long the servers keep accepting requests
+	// after reporting not ready, so that load balancers stop routing to
+	// the process first.
+	ShutdownDrainDelay time.Duration `mapstructure:"SHUTDOWN_DRAIN_DELAY"`
+	// HealthCheckCacheTTL is how long the results of the dependency probes
+	// behind /healthz, /readyz and the gRPC health service are reused.
+	HealthCheckCacheTTL time.Duration `mapstructure:"HEALTH_CHECK_CACHE_TTL"`
+	// HealthCheckTimeout bounds each dependency probe.
+	HealthCheckTimeout time.Duration `mapstructure:"HEALTH_CHECK_TIMEOUT"`
+	// TLSCertFile and TLSKeyFile enable TLS on the HTTP and ConnectRPC
+	// listeners. The files are reloaded when they change.
+	TLSCertFile string `mapstructure:"TLS_CERT_FILE"`
+	TLSKeyFile  string `mapstructure:"TLS_KEY_FILE"`
+	// TLSMinVersion is the minimum TLS version accepted: "1.2" or "1.3".
+	TLSMinVersion string `mapstructure:"TLS_MIN_VERSION"`
+	// TLSCipherSuites restricts the TLS 1.2 cipher suites, by Go name.
+	TLSCipherSuites []string `mapstructure:"TLS_CIPHER_SUITES"`
+	// TLSClientCAFile holds the CAs whose client certificates are verified
+	// during the handshake, for mTLS.
+	TLSClientCAFile string `mapstructure:"TLS_CLIENT_CA_FILE"`
+	// HTTPListenAddresses lists the addresses the HTTP server is bound to:
+	// "host:port", "unix:/path/to.sock" or "systemd:<name or index>" for
+	// sockets passed by systemd. It defaults to ":<HTTP_SERVER_PORT>".
+	HTTPListenAddresses []string `mapstructure:"HTTP_LISTEN_ADDRESSES"`
+	// ConnectRPCListenAddresses lists the addresses the ConnectRPC server is
+	// bound to, as HTTPListenAddresses. It defaults to
+	// ":<CONNECT_RPC_SERVER_PORT>".
+	ConnectRPCListenAddresses []string `mapstructure:"CONNECT_RPC_LISTEN_ADDRESSES"`
+	// SinglePort serves the ConnectRPC services on the HTTP server's
+	// addresses too, routing requests by content type, instead of running a
+	// separate ConnectRPC server.
+	SinglePort bool `mapstructure:"SINGLE_PORT"`
+
+	// File is the configuration file the settings were read from, if any.
+	File string `mapstructure:"-"`
+}
+
+// secretSettings are the settings that may instead be read from a file named
+// by the setting suffixed with "_FILE", e.g. MINIO_ACCESS_KEY_FILE, so that
+// secrets need not be passed in the environment.
+var secretSettings = []string{
+	"MINIO_ACCESS_KEY_ID",
+	"MINIO_ACCESS_KEY",
+	"MINIO_REPLICA_ACCESS_KEY_ID",
+	"MINIO_REPLICA_ACCESS_KEY",
+	"REPLICATION_MINIO_ACCESS_KEY_ID",
+	"REPLICATION_MINIO_ACCESS_KEY",
+	"UPSTREAM_API_KEY",
+	"SHARE_LINK_SECRET",
+	"AUTH_API_KEYS",
 }
 
-// NewConfig loads configuration from environment variables and optionally
-// a .env file (if present in the working directory).
+// Load loads the configuration from the file at path, when not empty,
+// environment variables, which override the file, and a .env file in the
+// working directory, if present. Secrets are read from the files named by
+// their "_FILE" settings.
 //
-// It returns a Config struct populated with the retrieved values.
-// If any error occurs during unmarshaling, the error is returned.
-func NewConfig() (*Config, error) {
+// The file may be YAML, TOML or JSON. Its settings are named after the
+// environment variables and may be grouped in sections, which are joined
+// with "_": "minio: {host: ...}" sets MINIO_HOST.
+//
+// Unknown settings and invalid values are reported together in the
+// returned error.
+func Load(path string) (*Config, error) {
 	// This checks if the application is running in a local development environment
 	// by looking for a `.env` file in the repo root (current working directory).
 	// If the file exists, it loads environment variables from it using godotenv.
@@ -39,7 +258,6 @@ func NewConfig() (*Config, error) {
 	}
 	// Assume repo root contains .env; adjust relative path as needed
 	envPath := filepath.Join(cwd, ".env")
-	fmt.Println(">>>> path", envPath)
 	// Check if .env exists
 	if _, err := os.Stat(envPath); err == nil {
 		if err := godotenv.Load(envPath); err != nil {
@@ -47,26 +265,166 @@ func NewConfig() (*Config, error) {
 		}
 	}
 
-	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	viper.SetDefault("MINIO_USE_SSL", false)
-	viper.SetDefault("HTTP_SERVER_PORT", 3333)
-	viper.SetDefault("CONNECT_RPC_SERVER_PORT", 5555)
-	viper.SetDefault("BUCKET_NAME", "files")
-	viper.AutomaticEnv()
-
-	viper.BindEnv("HTTP_SERVER_PORT")
-	viper.BindEnv("CONNECT_RPC_SERVER_PORT")
-	viper.BindEnv("FILE_DIRECTORY_NAME")
-	viper.BindEnv("MINIO_HOST")
-	viper.BindEnv("MINIO_ACCESS_KEY_ID")
-	viper.BindEnv("MINIO_ACCESS_KEY")
-	viper.BindEnv("MINIO_USE_SSL")
+	v := viper.New()
+	v.SetDefault("MINIO_USE_SSL", false)
+	v.SetDefault("HTTP_SERVER_PORT", 3333)
+	v.SetDefault("CONNECT_RPC_SERVER_PORT", 5555)
+	v.SetDefault("BUCKET_NAME", "files")
+	v.SetDefault("STORAGE_TIMEOUT", "10s")
+	v.SetDefault("STORAGE_RETRY_MAX_ATTEMPTS", 3)
+	v.SetDefault("STORAGE_RETRY_BASE_DELAY", "100ms")
+	v.SetDefault("STORAGE_RETRY_MAX_DELAY", "2s")
+	v.SetDefault("STORAGE_BREAKER_THRESHOLD", 5)
+	v.SetDefault("STORAGE_BREAKER_COOLDOWN", "30s")
+	v.SetDefault("STORAGE_MAX_RECONNECTS", 3)
+	v.SetDefault("STORAGE_HEDGE_DELAY", "0s")
+	v.SetDefault("TRASH_RETENTION", "168h")
+	v.SetDefault("TRASH_PURGE_INTERVAL", "1h")
+	v.SetDefault("REPLICATION_QUEUE_DIR", "replication-queue")
+	v.SetDefault("REPLICATION_WORKERS", 4)
+	v.SetDefault("REPLICATION_RETRY_MAX_DELAY", "5m")
+	v.SetDefault("REPLICATION_RECONCILE_INTERVAL", "24h")
+	v.SetDefault("CACHE_MAX_SIZE_MB", 1024)
+	v.SetDefault("CACHE_BLOCK_SIZE_KB", 1024)
+	v.SetDefault("READAHEAD_DEPTH", 4)
+	v.SetDefault("READAHEAD_CHUNK_SIZE_KB", 1024)
+	v.SetDefault("READAHEAD_MAX_MEMORY_MB", 256)
+	v.SetDefault("UPSTREAM_METADATA_TTL", "5s")
+	v.SetDefault("ADMISSION_MAX_QUEUE", 100)
+	v.SetDefault("ADMISSION_MAX_WAIT", "30s")
+	v.SetDefault("TRACING_SAMPLE_RATIO", 1.0)
+	v.SetDefault("LOG_LEVEL", "info")
+	v.SetDefault("LOG_FORMAT", "text")
+	v.SetDefault("ACCESS_LOG_FORMAT", "json")
+	v.SetDefault("SHUTDOWN_GRACE_PERIOD", "30s")
+	v.SetDefault("SHUTDOWN_DRAIN_DELAY", "0s")
+	v.SetDefault("HEALTH_CHECK_CACHE_TTL", "5s")
+	v.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	v.SetDefault("TLS_MIN_VERSION", "1.2")
 
-	viper.BindEnv("BUCKET_NAME")
+	var errs []error
+	if path != "" {
+		unknown, err := readFile(v, path)
+		if err != nil {
+			return nil, err
+		}
+		errs = append(errs, unknown...)
+	}
+	for _, name := range settings() {
+		v.BindEnv(name)
+	}
+	for _, name := range secretSettings {
+		v.BindEnv(name + "_FILE")
+		if err := readSecret(v, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
 	var cfg Config
-	if err := viper.Unmarshal(&cfg); err != nil {
-		return nil, fmt.Errorf("config unmarshal error: %w", err)
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, errors.Join(append(errs, fmt.Errorf("config unmarshal error: %w", err))...)
+	}
+	cfg.File = path
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
 	}
 	return &cfg, nil
 }
+
+// readFile applies the settings of the configuration file at path to v.
+// They take precedence over the defaults but not over the environment.
+// Settings it does not know are skipped and reported.
+func readFile(v *viper.Viper, path string) ([]error, error) {
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading config file: %w", err)
+	}
+	known := make(map[string]bool)
+	for _, name := range settings() {
+		known[name] = true
+	}
+	for _, name := range secretSettings {
+		known[name+"_FILE"] = true
+	}
+	var errs []error
+	for _, key := range file.AllKeys() {
+		name := strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+		if !known[name] {
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, key))
+			continue
+		}
+		v.SetDefault(name, file.Get(key))
+	}
+	return errs, nil
+}
+
+// readSecret sets the secret setting name from the file named by its
+// "_FILE" setting, if any. AUTH_API_KEYS files hold one key per line.
+func readSecret(v *viper.Viper, name string) error {
+	path := v.GetString(name + "_FILE")
+	if path == "" {
+		return nil
+	}
+	if v.IsSet(name) && !isEmpty(v.Get(name)) {
+		return fmt.Errorf("%s and %s_FILE are both set", name, name)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("%s_FILE: %w", name, err)
+	}
+	secret := strings.TrimSpace(string(b))
+	if name == "AUTH_API_KEYS" {
+		v.Set(name, strings.Fields(secret))
+		return nil
+	}
+	v.Set(name, secret)
+	return nil
+}
+
+func isEmpty(value any) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case []any:
+		return len(value) == 0
+	case []string:
+		return len(value) == 0
+	}
+	return false
+}
+
+// settings returns the names of every setting of Config.
+func settings() []string {
+	var names []string
+	t := reflect.TypeFor[Config]()
+	for i := range t.NumField() {
+		if name := t.Field(i).Tag.Get("mapstructure"); name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Changed returns the names of the settings whose values differ between
+// old and new.
+func Changed(old, new *Config) []string {
+	var names []string
+	t := reflect.TypeFor[Config]()
+	oldValue, newValue := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	for i := range t.NumField() {
+		name := t.Field(i).Tag.Get("mapstructure")
+		if name == "" || name == "-" {
+			continue
+		}
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
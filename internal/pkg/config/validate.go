@@ -0,0 +1,226 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Validate checks the settings for missing, malformed and conflicting
+// values, reporting every problem found rather than only the first.
+func (c *Config) Validate() error {
+	var v validator
+	if c.UpstreamURL == "" {
+		v.require("MINIO_HOST", c.MinioHost)
+	}
+	if (c.MinioAccessKeyID == "") != (c.MinioAccessKey == "") {
+		v.fail("MINIO_ACCESS_KEY_ID and MINIO_ACCESS_KEY must be set together")
+	}
+	if (c.MinioReplicaAccessKeyID == "") != (c.MinioReplicaAccessKey == "") {
+		v.fail("MINIO_REPLICA_ACCESS_KEY_ID and MINIO_REPLICA_ACCESS_KEY must be set together")
+	}
+	if slices.Contains(c.MinioReplicaHosts, c.MinioHost) {
+		v.fail("MINIO_REPLICA_HOSTS: %q is MINIO_HOST", c.MinioHost)
+	}
+	v.port("HTTP_SERVER_PORT", c.HTTPServerPort)
+	v.port("CONNECT_RPC_SERVER_PORT", c.ConnectRPCServerAddress)
+	if !c.SinglePort && len(c.HTTPListenAddresses) == 0 && len(c.ConnectRPCListenAddresses) == 0 &&
+		c.HTTPServerPort == c.ConnectRPCServerAddress {
+		v.fail("HTTP_SERVER_PORT and CONNECT_RPC_SERVER_PORT are both %d; set SINGLE_PORT to share a port", c.HTTPServerPort)
+	}
+
+	v.nonNegative("STORAGE_TIMEOUT", int(c.StorageTimeout))
+	if c.StorageRetryMaxAttempts < 1 {
+		v.fail("STORAGE_RETRY_MAX_ATTEMPTS: must be at least 1")
+	}
+	v.nonNegative("STORAGE_RETRY_BASE_DELAY", int(c.StorageRetryBaseDelay))
+	v.nonNegative("STORAGE_RETRY_MAX_DELAY", int(c.StorageRetryMaxDelay))
+	v.nonNegative("STORAGE_BREAKER_THRESHOLD", c.StorageBreakerThreshold)
+	if c.StorageBreakerThreshold > 0 {
+		v.positive("STORAGE_BREAKER_COOLDOWN", c.StorageBreakerCooldown)
+	}
+	v.nonNegative("STORAGE_MAX_RECONNECTS", c.StorageMaxReconnects)
+	v.nonNegative("STORAGE_HEDGE_DELAY", int(c.StorageHedgeDelay))
+
+	v.require("BUCKET_NAME", c.BucketName)
+	namespaces := append([]string{c.BucketName}, c.Namespaces...)
+	for _, name := range c.VersionedNamespaces {
+		if !slices.Contains(namespaces, name) {
+			v.fail("VERSIONED_NAMESPACES: %q is not a namespace", name)
+		}
+	}
+	for _, name := range c.ReplicationNamespaces {
+		if !slices.Contains(namespaces, name) {
+			v.fail("REPLICATION_NAMESPACES: %q is not a namespace", name)
+		}
+	}
+	if len(c.ReplicationNamespaces) > 0 {
+		if (c.ReplicationMinioHost == "") == (c.ReplicationDirectory == "") {
+			v.fail("exactly one of REPLICATION_MINIO_HOST and REPLICATION_DIRECTORY must be set with REPLICATION_NAMESPACES")
+		}
+		if c.ReplicationMinioHost != "" && (c.ReplicationMinioHost == c.MinioHost || slices.Contains(c.MinioReplicaHosts, c.ReplicationMinioHost)) {
+			v.fail("REPLICATION_MINIO_HOST: %q is already a storage endpoint", c.ReplicationMinioHost)
+		}
+		v.require("REPLICATION_QUEUE_DIR", c.ReplicationQueueDir)
+		if c.ReplicationWorkers < 1 {
+			v.fail("REPLICATION_WORKERS: must be at least 1")
+		}
+		v.positive("REPLICATION_RETRY_MAX_DELAY", c.ReplicationRetryMaxDelay)
+		v.nonNegative("REPLICATION_RECONCILE_INTERVAL", int(c.ReplicationReconcileInterval))
+	}
+	if (c.ReplicationMinioAccessKeyID == "") != (c.ReplicationMinioAccessKey == "") {
+		v.fail("REPLICATION_MINIO_ACCESS_KEY_ID and REPLICATION_MINIO_ACCESS_KEY must be set together")
+	}
+	if c.CacheDir != "" {
+		if c.CacheMaxSizeMB < 1 {
+			v.fail("CACHE_MAX_SIZE_MB: must be at least 1")
+		}
+		if c.CacheBlockSizeKB < 1 {
+			v.fail("CACHE_BLOCK_SIZE_KB: must be at least 1")
+		}
+	}
+	v.nonNegative("READAHEAD_DEPTH", c.ReadaheadDepth)
+	if c.ReadaheadDepth > 0 {
+		if c.ReadaheadChunkSizeKB < 1 {
+			v.fail("READAHEAD_CHUNK_SIZE_KB: must be at least 1")
+		}
+		if int64(c.ReadaheadMaxMemoryMB)<<10 < int64(c.ReadaheadChunkSizeKB) {
+			v.fail("READAHEAD_MAX_MEMORY_MB: must hold at least one chunk of READAHEAD_CHUNK_SIZE_KB")
+		}
+	}
+	if c.UpstreamURL != "" {
+		for _, setting := range []struct{ name, value string }{
+			{"UPSTREAM_URL", c.UpstreamURL},
+			{"UPSTREAM_CONNECT_URL", c.UpstreamConnectURL},
+			{"UPSTREAM_SHIELD_URL", c.UpstreamShieldURL},
+		} {
+			if setting.value == "" {
+				continue
+			}
+			if u, err := url.Parse(setting.value); err != nil || u.Scheme == "" || u.Host == "" {
+				v.fail("%s: %q is not an absolute URL", setting.name, setting.value)
+			}
+		}
+		v.nonNegative("UPSTREAM_METADATA_TTL", int(c.UpstreamMetadataTTL))
+		if len(c.ReplicationNamespaces) > 0 {
+			v.fail("REPLICATION_NAMESPACES: replication is not supported with UPSTREAM_URL")
+		}
+		if c.LifecycleConfigFile != "" {
+			v.fail("LIFECYCLE_CONFIG_FILE: lifecycle management is not supported with UPSTREAM_URL")
+		}
+	}
+	v.positive("TRASH_RETENTION", c.TrashRetention)
+	v.positive("TRASH_PURGE_INTERVAL", c.TrashPurgeInterval)
+	if c.PublicURL != "" {
+		if u, err := url.Parse(c.PublicURL); err != nil || u.Scheme == "" || u.Host == "" {
+			v.fail("PUBLIC_URL: %q is not an absolute URL", c.PublicURL)
+		}
+	}
+
+	for _, entry := range c.AuthAPIKeys {
+		if subject, key, ok := strings.Cut(entry, "="); !ok || subject == "" || key == "" {
+			v.fail("AUTH_API_KEYS: entries must be \"subject=key\"")
+			break
+		}
+	}
+	v.file("AUTH_JWKS_FILE", c.AuthJWKSFile)
+	v.file("AUTH_MTLS_CA_FILE", c.AuthMTLSCAFile)
+	v.file("AUTHZ_POLICY_FILE", c.AuthzPolicyFile)
+	v.file("LIMITS_CONFIG_FILE", c.LimitsConfigFile)
+	v.file("LIFECYCLE_CONFIG_FILE", c.LifecycleConfigFile)
+
+	v.nonNegative("ADMISSION_MAX_UPLOADS", c.AdmissionMaxUploads)
+	v.nonNegative("ADMISSION_MAX_DOWNLOADS", c.AdmissionMaxDownloads)
+	v.nonNegative("ADMISSION_MAX_METADATA", c.AdmissionMaxMetadata)
+	v.nonNegative("ADMISSION_MAX_QUEUE", c.AdmissionMaxQueue)
+	v.nonNegative("ADMISSION_MAX_WAIT", int(c.AdmissionMaxWait))
+
+	v.oneOf("TRACING_EXPORTER", c.TracingExporter, "", "otlp", "stdout")
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		v.fail("TRACING_SAMPLE_RATIO: must be between 0 and 1, got %v", c.TracingSampleRatio)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		v.fail("LOG_LEVEL: must be debug, info, warn or error, got %q", c.LogLevel)
+	}
+	v.oneOf("LOG_FORMAT", strings.ToLower(c.LogFormat), "", "text", "json")
+	v.oneOf("ACCESS_LOG_FORMAT", c.AccessLogFormat, "", "json", "combined", "off")
+
+	v.positive("SHUTDOWN_GRACE_PERIOD", c.ShutdownGracePeriod)
+	v.nonNegative("SHUTDOWN_DRAIN_DELAY", int(c.ShutdownDrainDelay))
+	v.nonNegative("HEALTH_CHECK_CACHE_TTL", int(c.HealthCheckCacheTTL))
+	v.positive("HEALTH_CHECK_TIMEOUT", c.HealthCheckTimeout)
+
+	if (c.TLSCertFile == "") != (c.TLSKeyFile == "") {
+		v.fail("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLSClientCAFile != "" && c.TLSCertFile == "" {
+		v.fail("TLS_CLIENT_CA_FILE requires TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+	v.file("TLS_CERT_FILE", c.TLSCertFile)
+	v.file("TLS_KEY_FILE", c.TLSKeyFile)
+	v.file("TLS_CLIENT_CA_FILE", c.TLSClientCAFile)
+	v.oneOf("TLS_MIN_VERSION", c.TLSMinVersion, "", "1.2", "1.3")
+	if c.SinglePort && len(c.ConnectRPCListenAddresses) > 0 {
+		v.fail("CONNECT_RPC_LISTEN_ADDRESSES cannot be set with SINGLE_PORT")
+	}
+
+	if len(v.errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(v.errs...))
+	}
+	return nil
+}
+
+// validator collects validation errors.
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf(format, args...))
+}
+
+func (v *validator) require(name, value string) {
+	if value == "" {
+		v.fail("%s: required", name)
+	}
+}
+
+func (v *validator) port(name string, port int) {
+	if port < 1 || port > 65535 {
+		v.fail("%s: %d is not a valid port", name, port)
+	}
+}
+
+func (v *validator) positive(name string, d time.Duration) {
+	if d <= 0 {
+		v.fail("%s: must be positive, got %s", name, d)
+	}
+}
+
+func (v *validator) nonNegative(name string, n int) {
+	if n < 0 {
+		v.fail("%s: must not be negative", name)
+	}
+}
+
+func (v *validator) oneOf(name, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		v.fail("%s: %q is not one of %s", name, value, strings.Join(slices.DeleteFunc(allowed, func(s string) bool { return s == "" }), ", "))
+	}
+}
+
+// file checks that the file named by the setting exists, when set.
+func (v *validator) file(name, path string) {
+	if path == "" {
+		return
+	}
+	if _, err := os.Stat(path); err != nil {
+		v.fail("%s: %v", name, err)
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay batches the file events of a single save, which editors
+// often perform as several writes or a rename.
+const reloadDelay = 500 * time.Millisecond
+
+// Watch reloads the configuration on SIGHUP and, when it was read from a
+// file, whenever that file changes, until ctx is canceled. onReload is
+// called with every configuration that loads and validates; one that does
+// not is logged and ignored.
+func Watch(ctx context.Context, current *Config, onReload func(*Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events <-chan fsnotify.Event
+	if current.File != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err == nil {
+			err = watcher.Add(filepath.Dir(current.File))
+		}
+		if err != nil {
+			slog.Error("watching config file; reload with SIGHUP", "path", current.File, "error", err)
+		} else {
+			defer watcher.Close()
+			events = watcher.Events
+		}
+	}
+
+	file := filepath.Clean(current.File)
+	reload := func(reason string) {
+		next, err := Load(current.File)
+		if err != nil {
+			slog.Error("keeping previous configuration", "reason", reason, "error", err)
+			return
+		}
+		slog.Info("configuration reloaded", "reason", reason, "changed", Changed(current, next))
+		current = next
+		onReload(next)
+	}
+	var pending <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			reload("SIGHUP")
+		case event := <-events:
+			// Kubernetes updates mounted ConfigMaps by swapping the
+			// "..data" symlink rather than writing the file.
+			name := filepath.Clean(event.Name)
+			if (name == file || filepath.Base(name) == "..data") && !event.Has(fsnotify.Chmod) {
+				pending = time.After(reloadDelay)
+			}
+		case <-pending:
+			pending = nil
+			reload("file changed")
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Package envelope implements the client-side encryption format used for
+// end-to-end encrypted transfers.
+//
+// Plaintext is split into fixed-size segments that are sealed independently
+// with ChaCha20-Poly1305 under a random per-object file key, following the
+// STREAM construction used by age. Because every segment has the same
+// ciphertext size, any plaintext byte range maps to a segment-aligned
+// ciphertext range, so encrypted objects can still be read partially through
+// StreamFile or HTTP range requests.
+//
+// The file key is wrapped with the caller's key and stored, base64 encoded,
+// in the object's metadata under MetadataKey. The server only ever sees the
+// wrapped key and the ciphertext.
+package envelope
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const (
+	// SegmentSize is the plaintext size of every segment except the last.
+	SegmentSize = 64 * 1024
+	// Overhead is the authentication tag added to each sealed segment.
+	Overhead = chacha20poly1305.Overhead
+	// EncryptedSegmentSize is the ciphertext size of every segment except the last.
+	EncryptedSegmentSize = SegmentSize + Overhead
+	// MetadataKey is the object metadata key holding the encoded header.
+	MetadataKey = "Fs-Encryption"
+	// KeySize is the size of a Key in bytes.
+	KeySize = chacha20poly1305.KeySize
+
+	headerVersion byte = 1
+	// headerSize is version(1) + segment size(4) + nonce(24) + wrapped key(32+16).
+	headerSize = 1 + 4 + chacha20poly1305.NonceSizeX + KeySize + Overhead
+)
+
+var (
+	// ErrInvalidHeader is returned when an encoded header cannot be parsed.
+	ErrInvalidHeader = errors.New("envelope: invalid header")
+	// ErrWrongKey is returned when the header cannot be unwrapped with the given key.
+	ErrWrongKey = errors.New("envelope: wrong key or corrupted header")
+	// ErrCorrupted is returned when a segment fails authentication.
+	ErrCorrupted = errors.New("envelope: corrupted or truncated ciphertext")
+)
+
+// Key is a symmetric key used to wrap per-object file keys.
+type Key [KeySize]byte
+
+// GenerateKey returns a new random Key.
+func GenerateKey() (Key, error) {
+	var k Key
+	if _, err := rand.Read(k[:]); err != nil {
+		return Key{}, fmt.Errorf("generating key: %w", err)
+	}
+	return k, nil
+}
+
+// ParseKey decodes a base64 encoded Key as produced by Key.String.
+func ParseKey(s string) (Key, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return Key{}, fmt.Errorf("decoding key: %w", err)
+	}
+	if len(b) != KeySize {
+		return Key{}, fmt.Errorf("key must be %d bytes, got %d", KeySize, len(b))
+	}
+	var k Key
+	copy(k[:], b)
+	return k, nil
+}
+
+// LoadKeyFile reads a base64 encoded Key from the file at path.
+func LoadKeyFile(path string) (Key, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Key{}, fmt.Errorf("reading key file: %w", err)
+	}
+	return ParseKey(string(b))
+}
+
+// String returns the base64 encoding of the key.
+func (k Key) String() string {
+	return base64.StdEncoding.EncodeToString(k[:])
+}
+
+// Cipher encrypts and decrypts the segments of a single object.
+type Cipher struct {
+	fileKey     [KeySize]byte
+	segmentSize int64
+}
+
+// NewCipher generates a fresh file key for a new object and returns the
+// Cipher together with the encoded header to store in the object metadata.
+func NewCipher(key Key) (*Cipher, string, error) {
+	c := &Cipher{segmentSize: SegmentSize}
+	if _, err := rand.Read(c.fileKey[:]); err != nil {
+		return nil, "", fmt.Errorf("generating file key: %w", err)
+	}
+	wrap, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, "", err
+	}
+	header := make([]byte, 5+chacha20poly1305.NonceSizeX, headerSize)
+	header[0] = headerVersion
+	binary.BigEndian.PutUint32(header[1:5], uint32(c.segmentSize))
+	nonce := header[5:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, "", fmt.Errorf("generating nonce: %w", err)
+	}
+	// The version and segment size are authenticated as additional data so
+	// they cannot be altered without invalidating the wrapped key.
+	header = wrap.Seal(header, nonce, c.fileKey[:], header[:5])
+	return c, base64.RawURLEncoding.EncodeToString(header), nil
+}
+
+// OpenCipher unwraps the file key from an encoded header using key.
+func OpenCipher(key Key, encodedHeader string) (*Cipher, error) {
+	header, err := base64.RawURLEncoding.DecodeString(encodedHeader)
+	if err != nil || len(header) != headerSize || header[0] != headerVersion {
+		return nil, ErrInvalidHeader
+	}
+	segmentSize := int64(binary.BigEndian.Uint32(header[1:5]))
+	if segmentSize != SegmentSize {
+		return nil, ErrInvalidHeader
+	}
+	wrap, err := chacha20poly1305.NewX(key[:])
+	if err != nil {
+		return nil, err
+	}
+	nonce := header[5 : 5+chacha20poly1305.NonceSizeX]
+	fileKey, err := wrap.Open(nil, nonce, header[5+chacha20poly1305.NonceSizeX:], header[:5])
+	if err != nil {
+		return nil, ErrWrongKey
+	}
+	c := &Cipher{segmentSize: segmentSize}
+	copy(c.fileKey[:], fileKey)
+	return c, nil
+}
+
+// encryptedSegmentSize returns the ciphertext size of a full segment.
+func (c *Cipher) encryptedSegmentSize() int64 {
+	return c.segmentSize + Overhead
+}
+
+// CiphertextSize returns the ciphertext size for plaintextSize bytes.
+func (c *Cipher) CiphertextSize(plaintextSize int64) int64 {
+	segments := plaintextSize / c.segmentSize
+	if plaintextSize%c.segmentSize != 0 || segments == 0 {
+		segments++
+	}
+	return plaintextSize + segments*Overhead
+}
+
+// PlaintextSize returns the plaintext size of an object whose ciphertext is
+// ciphertextSize bytes long.
+func (c *Cipher) PlaintextSize(ciphertextSize int64) (int64, error) {
+	segments, err := c.segmentCount(ciphertextSize)
+	if err != nil {
+		return 0, err
+	}
+	return ciphertextSize - segments*Overhead, nil
+}
+
+// segmentCount returns the number of segments in a ciphertext of the given size.
+func (c *Cipher) segmentCount(ciphertextSize int64) (int64, error) {
+	if ciphertextSize < Overhead {
+		return 0, ErrCorrupted
+	}
+	full := c.encryptedSegmentSize()
+	segments := (ciphertextSize + full - 1) / full
+	last := ciphertextSize - (segments-1)*full
+	// Only an empty object may end with an empty segment.
+	if last < Overhead || (last == Overhead && segments > 1) {
+		return 0, ErrCorrupted
+	}
+	return segments, nil
+}
+
+// CiphertextRange maps the inclusive plaintext range [start, end] of an object
+// whose ciphertext is ciphertextSize bytes long to the inclusive,
+// segment-aligned ciphertext range that must be fetched to decrypt it.
+//
+// skip is the number of decrypted bytes to discard before start is reached.
+func (c *Cipher) CiphertextRange(start, end, ciphertextSize int64) (ctStart, ctEnd, skip int64, err error) {
+	plaintextSize, err := c.PlaintextSize(ciphertextSize)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if start < 0 || end < start || end >= plaintextSize {
+		return 0, 0, 0, fmt.Errorf("range %d-%d out of bounds for %d bytes", start, end, plaintextSize)
+	}
+	full := c.encryptedSegmentSize()
+	first := start / c.segmentSize
+	last := end / c.segmentSize
+	ctStart = first * full
+	ctEnd = min((last+1)*full, ciphertextSize) - 1
+	return ctStart, ctEnd, start - first*c.segmentSize, nil
+}
+
+// nonce returns the STREAM nonce for a segment: an 11 byte big-endian
+// counter followed by a flag byte set on the final segment.
+func nonce(index uint64, last bool) []byte {
+	n := make([]byte, chacha20poly1305.NonceSize)
+	binary.BigEndian.PutUint64(n[3:11], index)
+	if last {
+		n[11] = 1
+	}
+	return n
+}
@@ -0,0 +1,150 @@
+package envelope
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// writer seals plaintext into segments as it is written.
+type writer struct {
+	aead   cipher.AEAD
+	dst    io.Writer
+	buf    []byte
+	index  uint64
+	closed bool
+}
+
+// NewWriter returns a WriteCloser that encrypts everything written to it and
+// writes the ciphertext to dst. Close must be called to seal the final
+// segment; it does not close dst.
+func (c *Cipher) NewWriter(dst io.Writer) (io.WriteCloser, error) {
+	aead, err := chacha20poly1305.New(c.fileKey[:])
+	if err != nil {
+		return nil, err
+	}
+	return &writer{
+		aead: aead,
+		dst:  dst,
+		buf:  make([]byte, 0, c.segmentSize+Overhead),
+	}, nil
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("envelope: write after close")
+	}
+	segmentSize := cap(w.buf) - Overhead
+	written := 0
+	for len(p) > 0 {
+		// A full segment is only flushed once more data arrives, so that
+		// the final segment is always the one sealed by Close.
+		if len(w.buf) == segmentSize {
+			if err := w.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := min(segmentSize-len(w.buf), len(p))
+		w.buf = append(w.buf, p[:n]...)
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close seals and writes the final segment.
+func (w *writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.flush(true)
+}
+
+func (w *writer) flush(last bool) error {
+	sealed := w.aead.Seal(w.buf[:0], nonce(w.index, last), w.buf, nil)
+	if _, err := w.dst.Write(sealed); err != nil {
+		return err
+	}
+	w.index++
+	w.buf = w.buf[:0]
+	return nil
+}
+
+// reader decrypts segments read from an underlying ciphertext stream.
+type reader struct {
+	aead     cipher.AEAD
+	src      io.Reader
+	buf      []byte
+	plain    []byte
+	index    uint64
+	segments uint64
+	err      error
+}
+
+// NewReader returns a Reader that decrypts ciphertext read from src.
+//
+// src must start at the segment boundary ctStart returned by CiphertextRange
+// (or 0 to read the whole object), and ciphertextSize is the total size of the
+// encrypted object, which is needed to recognise the final segment. The
+// reader returns ErrCorrupted if any segment fails authentication.
+func (c *Cipher) NewReader(src io.Reader, ctStart, ciphertextSize int64) (io.Reader, error) {
+	segments, err := c.segmentCount(ciphertextSize)
+	if err != nil {
+		return nil, err
+	}
+	full := c.encryptedSegmentSize()
+	if ctStart < 0 || ctStart%full != 0 || ctStart >= ciphertextSize {
+		return nil, errors.New("envelope: ciphertext offset is not a segment boundary")
+	}
+	aead, err := chacha20poly1305.New(c.fileKey[:])
+	if err != nil {
+		return nil, err
+	}
+	return &reader{
+		aead:     aead,
+		src:      src,
+		buf:      make([]byte, full),
+		index:    uint64(ctStart / full),
+		segments: uint64(segments),
+	}, nil
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	for len(r.plain) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.next()
+	}
+	n := copy(p, r.plain)
+	r.plain = r.plain[n:]
+	return n, nil
+}
+
+// next decrypts the next segment into r.plain.
+func (r *reader) next() error {
+	if r.index >= r.segments {
+		return io.EOF
+	}
+	n, err := io.ReadFull(r.src, r.buf)
+	last := r.index == r.segments-1
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+		// Only the final segment may be short.
+		if !last {
+			return ErrCorrupted
+		}
+	case err != nil:
+		return err
+	}
+	plain, err := r.aead.Open(r.buf[:0], nonce(r.index, last), r.buf[:n], nil)
+	if err != nil {
+		return ErrCorrupted
+	}
+	r.plain = plain
+	r.index++
+	return nil
+}
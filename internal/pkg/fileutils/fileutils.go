@@ -8,10 +8,27 @@ import (
 	"strings"
 )
 
+const (
+	// TrashPrefix is the reserved key prefix under which deleted files are
+	// kept until they are purged.
+	TrashPrefix = ".trash/"
+	// SharePrefix is the reserved key prefix under which share links are stored.
+	SharePrefix = ".shares/"
+)
+
+// IsReserved reports whether name lies under one of the reserved prefixes
+// used for server-side bookkeeping rather than user files.
+func IsReserved(name string) bool {
+	return strings.HasPrefix(name, TrashPrefix) || strings.HasPrefix(name, SharePrefix)
+}
+
 func ValidateFileName(name string) error {
 	if strings.Contains(name, "..") {
 		return errors.New("invalid file name")
 	}
+	if IsReserved(name) {
+		return errors.New("file name uses a reserved prefix")
+	}
 	return nil
 }
 
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+
+	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
+)
+
+// LivenessHandler serves the liveness check. It responds 200 OK as long as
+// the process serves requests, so that an unreachable storage backend does
+// not get the process restarted; the body still reports each dependency.
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeReport(w, http.StatusOK, c.Report(r.Context()))
+	})
+}
+
+// ReadinessHandler serves the readiness check. It responds 503 Service
+// Unavailable while the server is starting or shutting down, or when a
+// dependency is failing.
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := c.Report(r.Context())
+		status := http.StatusOK
+		if !report.OK() {
+			status = http.StatusServiceUnavailable
+		}
+		writeReport(w, status, report)
+	})
+}
+
+func writeReport(w http.ResponseWriter, status int, report Report) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		slog.Error("writing health report", "error", err)
+	}
+}
+
+// GRPCChecker adapts a Checker to the grpc.health.v1.Health service. The
+// server as a whole ("") and each of services report SERVING when the
+// readiness check passes; other services are unknown.
+func (c *Checker) GRPCChecker(services ...string) grpchealth.Checker {
+	return &grpcChecker{checker: c, services: services}
+}
+
+type grpcChecker struct {
+	checker  *Checker
+	services []string
+}
+
+func (g *grpcChecker) Check(ctx context.Context, req *grpchealth.CheckRequest) (*grpchealth.CheckResponse, error) {
+	if req.Service != "" && !slices.Contains(g.services, req.Service) {
+		return nil, connect.NewError(connect.CodeNotFound, fmt.Errorf("unknown service %q", req.Service))
+	}
+	if !g.checker.Report(ctx).OK() {
+		return &grpchealth.CheckResponse{Status: grpchealth.StatusNotServing}, nil
+	}
+	return &grpchealth.CheckResponse{Status: grpchealth.StatusServing}, nil
+}
@@ -0,0 +1,139 @@
+// Package health probes the dependencies of the server and reports their
+// status for liveness and readiness checks. Probe results are cached so
+// that frequent checks by orchestrators and load balancers do not load the
+// storage backend.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Statuses of a check or of the whole report.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Check probes one dependency.
+type Check struct {
+	// Name identifies the dependency in reports, e.g. "storage:files".
+	Name string
+	// Probe returns an error when the dependency is unusable.
+	Probe func(ctx context.Context) error
+}
+
+// Result is the outcome of the last probe of a check.
+type Result struct {
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	DurationMS float64   `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// Report is the status of the server and of each of its dependencies.
+type Report struct {
+	// Status is StatusOK when the server is ready and every check passed.
+	Status string `json:"status"`
+	// Ready reports whether the server is accepting work; it is false
+	// while starting and shutting down.
+	Ready  bool              `json:"ready"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// OK reports whether the server is ready and every check passed.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Checker runs checks and caches their results.
+type Checker struct {
+	ready   func() bool
+	checks  []Check
+	ttl     time.Duration
+	timeout time.Duration
+
+	mu        sync.Mutex
+	results   map[string]Result
+	refreshed time.Time
+}
+
+// NewChecker returns a Checker probing checks at most once per ttl, each
+// probe bounded by timeout. ready reports whether the server is accepting
+// work.
+func NewChecker(ready func() bool, ttl, timeout time.Duration, checks ...Check) *Checker {
+	return &Checker{
+		ready:   ready,
+		checks:  checks,
+		ttl:     ttl,
+		timeout: timeout,
+	}
+}
+
+// Report returns the status of the server, probing the checks again when
+// the cached results are older than the ttl. Concurrent callers share a
+// single round of probes.
+func (c *Checker) Report(ctx context.Context) Report {
+	results := c.probeAll(ctx)
+	report := Report{
+		Status: StatusOK,
+		Ready:  c.ready(),
+		Checks: results,
+	}
+	if !report.Ready {
+		report.Status = StatusFail
+	}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+// probeAll returns the cached results, probing every check when they are
+// stale.
+func (c *Checker) probeAll(ctx context.Context) map[string]Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.results != nil && time.Since(c.refreshed) < c.ttl {
+		return c.results
+	}
+	// The probes outlive the caller's cancellation: their results are
+	// shared with the other callers through the cache.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.timeout)
+	defer cancel()
+	results := make(map[string]Result, len(c.checks))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := probe(ctx, check)
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	c.results = results
+	c.refreshed = time.Now()
+	return results
+}
+
+func probe(ctx context.Context, check Check) Result {
+	start := time.Now()
+	err := check.Probe(ctx)
+	result := Result{
+		Status:     StatusOK,
+		DurationMS: float64(time.Since(start).Microseconds()) / 1000,
+		CheckedAt:  start,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,26 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// BucketCheck returns a check that the storage backend is reachable and
+// bucket exists.
+func BucketCheck(client storage.Client, bucket string) Check {
+	return Check{
+		Name: "storage:" + bucket,
+		Probe: func(ctx context.Context) error {
+			exists, err := client.DoesBucketExists(ctx, bucket)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				return fmt.Errorf("bucket %q does not exist", bucket)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,100 @@
+// Package lifecycle evaluates per-namespace lifecycle rules: expiring old
+// objects, aborting stale multipart uploads and transitioning cold objects
+// to a secondary storage backend.
+package lifecycle
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/spf13/viper"
+)
+
+// Rule is a single lifecycle rule. An object matches a rule when its name
+// starts with Prefix and it carries every tag in Tags. Each action is
+// enabled by setting its threshold to a positive value.
+type Rule struct {
+	ID     string            `mapstructure:"id"`
+	Prefix string            `mapstructure:"prefix"`
+	Tags   map[string]string `mapstructure:"tags"`
+	// ExpireAfterDays permanently removes objects older than N days.
+	ExpireAfterDays int `mapstructure:"expire_after_days"`
+	// TransitionAfterDays moves objects older than N days to the secondary
+	// backend, leaving a stub pointing to them on the primary one.
+	TransitionAfterDays int `mapstructure:"transition_after_days"`
+	// AbortIncompleteUploadsAfterHours aborts multipart uploads started more
+	// than N hours ago. Tags are ignored since incomplete uploads have none.
+	AbortIncompleteUploadsAfterHours int `mapstructure:"abort_incomplete_uploads_after_hours"`
+}
+
+// SecondaryConfig describes the MinIO endpoint that cold objects are
+// transitioned to. Objects keep their namespace's bucket name there.
+type SecondaryConfig struct {
+	MinioHost        string `mapstructure:"minio_host"`
+	MinioAccessKeyID string `mapstructure:"minio_access_key_id"`
+	MinioAccessKey   string `mapstructure:"minio_access_key"`
+	MinioUseSSL      bool   `mapstructure:"minio_use_ssl"`
+}
+
+// Config holds the lifecycle rules of every namespace.
+type Config struct {
+	// Interval is how often the rules are evaluated.
+	Interval time.Duration `mapstructure:"interval"`
+	// DryRun only reports the actions that would be taken.
+	DryRun bool `mapstructure:"dry_run"`
+	// Secondary is required when any rule transitions objects.
+	Secondary *SecondaryConfig `mapstructure:"secondary"`
+	// Namespaces maps namespace names to their rules.
+	Namespaces map[string][]Rule `mapstructure:"namespaces"`
+}
+
+// LoadConfig reads lifecycle rules from a YAML, JSON or TOML file.
+func LoadConfig(path string) (*Config, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	v.SetDefault("interval", "1h")
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading lifecycle config: %w", err)
+	}
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("lifecycle config unmarshal error: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Validate checks the rules against the configured namespaces.
+func (c *Config) Validate(namespaces *namespace.Registry) error {
+	var errs []error
+	if c.Interval <= 0 {
+		errs = append(errs, errors.New("interval must be positive"))
+	}
+	for name, rules := range c.Namespaces {
+		if _, err := namespaces.Resolve(name); err != nil {
+			errs = append(errs, err)
+		}
+		for i, rule := range rules {
+			if rule.ExpireAfterDays <= 0 && rule.TransitionAfterDays <= 0 && rule.AbortIncompleteUploadsAfterHours <= 0 {
+				errs = append(errs, fmt.Errorf("namespace %q rule %d (%s): no action configured", name, i, rule.ID))
+			}
+			if rule.TransitionAfterDays > 0 && c.Secondary == nil {
+				errs = append(errs, fmt.Errorf("namespace %q rule %d (%s): transition requires a secondary backend", name, i, rule.ID))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// HasTransitions reports whether any rule transitions objects.
+func (c *Config) HasTransitions() bool {
+	for _, rules := range c.Namespaces {
+		for _, rule := range rules {
+			if rule.TransitionAfterDays > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
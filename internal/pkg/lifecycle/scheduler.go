@@ -0,0 +1,308 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// Action kinds reported by the scheduler.
+const (
+	ActionExpire      = "expire"
+	ActionTransition  = "transition"
+	ActionAbortUpload = "abort_upload"
+)
+
+// Action is a single lifecycle action taken (or, in dry-run mode, planned)
+// against an object or incomplete upload.
+type Action struct {
+	Namespace string
+	RuleID    string
+	Kind      string
+	Key       string
+	UploadID  string
+	Age       time.Duration
+	Err       error
+}
+
+// Report lists the actions of one evaluation of the rules.
+type Report struct {
+	StartedAt time.Time
+	Duration  time.Duration
+	DryRun    bool
+	Actions   []Action
+}
+
+// Scheduler periodically evaluates the lifecycle rules of every namespace.
+type Scheduler struct {
+	config    *Config
+	primary   storage.Client
+	secondary storage.Client
+	// objects is primary, tiered when objects may be transitioned.
+	objects    storage.Client
+	namespaces *namespace.Registry
+	logger     *slog.Logger
+	now        func() time.Time
+}
+
+// NewScheduler returns a Scheduler applying config to the namespaces stored
+// in primary. secondary receives transitioned objects and may be nil when no
+// rule transitions objects.
+func NewScheduler(
+	config *Config,
+	primary storage.Client,
+	secondary storage.Client,
+	namespaces *namespace.Registry,
+) *Scheduler {
+	s := &Scheduler{
+		config:     config,
+		primary:    primary,
+		secondary:  secondary,
+		objects:    primary,
+		namespaces: namespaces,
+		logger:     slog.Default().With("component", "lifecycle"),
+		now:        time.Now,
+	}
+	if secondary != nil {
+		s.objects = NewTieredClient(primary, secondary)
+	}
+	return s
+}
+
+// Run evaluates the rules every configured interval until ctx is done.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.RunOnce(ctx); err != nil {
+				s.logger.Error("lifecycle evaluation failed", "error", err)
+			}
+		}
+	}
+}
+
+// RunOnce evaluates every rule once and returns a report of the actions.
+// In dry-run mode nothing is changed.
+func (s *Scheduler) RunOnce(ctx context.Context) (*Report, error) {
+	report := &Report{StartedAt: s.now(), DryRun: s.config.DryRun}
+	var errs []error
+	for name, rules := range s.config.Namespaces {
+		ns, err := s.namespaces.Resolve(name)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.evaluateObjects(ctx, ns, rules, report); err != nil {
+			errs = append(errs, fmt.Errorf("namespace %q: %w", ns.Name, err))
+		}
+		if err := s.evaluateUploads(ctx, ns, rules, report); err != nil {
+			errs = append(errs, fmt.Errorf("namespace %q: %w", ns.Name, err))
+		}
+	}
+	report.Duration = s.now().Sub(report.StartedAt)
+	s.logger.Info("lifecycle evaluation complete",
+		"dry_run", report.DryRun,
+		"actions", len(report.Actions),
+		"duration", report.Duration,
+	)
+	return report, errors.Join(errs...)
+}
+
+// evaluateObjects applies the expire and transition actions. When both
+// apply to an object, expiry wins. Transitioned objects are only expired,
+// by their age when they were transitioned.
+func (s *Scheduler) evaluateObjects(
+	ctx context.Context,
+	ns namespace.Namespace,
+	rules []Rule,
+	report *Report,
+) error {
+	objects, err := s.primary.ListObjects(ctx, ns.Bucket, storage.ListObjectsOptions{})
+	if err != nil {
+		return err
+	}
+	now := s.now()
+	for _, object := range objects {
+		// The trash and share links have their own retention.
+		if fileutils.IsReserved(object.Key) {
+			continue
+		}
+		age := now.Sub(object.LastModified)
+		ref, transitioned := s.stub(ctx, ns, object)
+		if transitioned && !ref.modified.IsZero() {
+			age = now.Sub(ref.modified)
+		}
+		var action *Action
+		for _, rule := range rules {
+			if !rule.matches(object) {
+				continue
+			}
+			switch {
+			case rule.ExpireAfterDays > 0 && age >= days(rule.ExpireAfterDays):
+				action = &Action{RuleID: rule.ID, Kind: ActionExpire}
+			case action == nil && !transitioned && rule.TransitionAfterDays > 0 && age >= days(rule.TransitionAfterDays):
+				action = &Action{RuleID: rule.ID, Kind: ActionTransition}
+			}
+			if action != nil && action.Kind == ActionExpire {
+				break
+			}
+		}
+		if action == nil {
+			continue
+		}
+		action.Namespace = ns.Name
+		action.Key = object.Key
+		action.Age = age
+		if !s.config.DryRun {
+			switch action.Kind {
+			case ActionExpire:
+				action.Err = s.objects.RemoveObject(ctx, ns.Bucket, object.Key, storage.RemoveObjectOptions{})
+			case ActionTransition:
+				action.Err = s.transition(ctx, ns, object)
+			}
+		}
+		s.record(report, *action)
+	}
+	return nil
+}
+
+// stub reports whether object is the stub of a transitioned object and
+// returns its reference. Only empty objects are looked up, since listings
+// do not reliably carry metadata.
+func (s *Scheduler) stub(ctx context.Context, ns namespace.Namespace, object storage.ObjectInfo) (tierRef, bool) {
+	if s.secondary == nil || object.Size != 0 {
+		return tierRef{}, false
+	}
+	info, err := s.primary.GetObjectInfo(ctx, ns.Bucket, object.Key, storage.GetObjectInfoOptions{})
+	if err != nil {
+		return tierRef{}, false
+	}
+	return stubRef(info)
+}
+
+// evaluateUploads aborts multipart uploads that have been pending too long.
+func (s *Scheduler) evaluateUploads(
+	ctx context.Context,
+	ns namespace.Namespace,
+	rules []Rule,
+	report *Report,
+) error {
+	now := s.now()
+	for _, rule := range rules {
+		if rule.AbortIncompleteUploadsAfterHours <= 0 {
+			continue
+		}
+		uploads, err := s.primary.ListIncompleteUploads(ctx, ns.Bucket, rule.Prefix)
+		if err != nil {
+			return err
+		}
+		for _, upload := range uploads {
+			age := now.Sub(upload.Initiated)
+			if age < time.Duration(rule.AbortIncompleteUploadsAfterHours)*time.Hour {
+				continue
+			}
+			action := Action{
+				Namespace: ns.Name,
+				RuleID:    rule.ID,
+				Kind:      ActionAbortUpload,
+				Key:       upload.Key,
+				UploadID:  upload.UploadID,
+				Age:       age,
+			}
+			if !s.config.DryRun {
+				action.Err = s.primary.AbortIncompleteUpload(ctx, ns.Bucket, upload.Key, upload.UploadID)
+			}
+			s.record(report, action)
+		}
+	}
+	return nil
+}
+
+// transition copies an object to the secondary backend and replaces it on
+// the primary one with a stub pointing to the copy.
+func (s *Scheduler) transition(ctx context.Context, ns namespace.Namespace, object storage.ObjectInfo) error {
+	// Listings do not include every attribute, so fetch the full metadata;
+	// tags, however, are only reported by listings.
+	info, err := s.primary.GetObjectInfo(ctx, ns.Bucket, object.Key, storage.GetObjectInfoOptions{})
+	if err != nil {
+		return err
+	}
+	obj, err := s.primary.GetObject(ctx, ns.Bucket, object.Key, storage.GetObjectOptions{Start: -1, End: -1})
+	if err != nil {
+		return err
+	}
+	defer obj.Close()
+	copied, err := s.secondary.PutObject(ctx, ns.Bucket, object.Key, obj, info.Size, storage.PutObjectOptions{
+		ContentType: info.ContentType,
+		Metadata:    info.Metadata,
+		Tags:        object.Tags,
+	})
+	if err != nil {
+		return fmt.Errorf("copying to secondary: %w", err)
+	}
+	_, err = writeStub(ctx, s.primary, ns.Bucket, object.Key, tierRef{
+		key:       object.Key,
+		versionID: copied.VersionID,
+		modified:  info.LastModified,
+	}, info.ContentType, object.Tags)
+	return err
+}
+
+// record adds an action to the report, logs it and counts it.
+func (s *Scheduler) record(report *Report, action Action) {
+	report.Actions = append(report.Actions, action)
+	result := "executed"
+	switch {
+	case report.DryRun:
+		result = "planned"
+	case action.Err != nil:
+		result = "failed"
+	}
+	metrics.RecordLifecycleAction(action.Kind, result)
+	attrs := []any{
+		"namespace", action.Namespace,
+		"rule", action.RuleID,
+		"action", action.Kind,
+		"key", action.Key,
+		"age", action.Age.Round(time.Second),
+		"result", result,
+	}
+	if action.UploadID != "" {
+		attrs = append(attrs, "upload_id", action.UploadID)
+	}
+	if action.Err != nil {
+		s.logger.Error("lifecycle action failed", append(attrs, "error", action.Err)...)
+		return
+	}
+	s.logger.Info("lifecycle action", attrs...)
+}
+
+// matches reports whether the rule's prefix and tag filters select object.
+func (r Rule) matches(object storage.ObjectInfo) bool {
+	if !strings.HasPrefix(object.Key, r.Prefix) {
+		return false
+	}
+	for key, value := range r.Tags {
+		if object.Tags[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// days converts a number of days to a duration.
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
+}
@@ -0,0 +1,264 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// Metadata keys of the stub a transitioned object leaves on the primary
+// backend, recording where its content lives on the secondary one and when
+// it was last modified.
+const (
+	tierKeyMetadata      = "Fs-Tier-Key"
+	tierVersionMetadata  = "Fs-Tier-Version"
+	tierModifiedMetadata = "Fs-Tier-Modified"
+)
+
+// tierRef locates the content of a transitioned object on the secondary
+// backend.
+type tierRef struct {
+	key       string
+	versionID string
+	// modified is when the object was last modified before it was
+	// transitioned.
+	modified time.Time
+}
+
+// stubRef returns the reference recorded by info if it describes a stub.
+func stubRef(info storage.ObjectInfo) (tierRef, bool) {
+	key, ok := info.Metadata[tierKeyMetadata]
+	if !ok || info.Size != 0 {
+		return tierRef{}, false
+	}
+	ref := tierRef{key: key, versionID: info.Metadata[tierVersionMetadata]}
+	ref.modified, _ = time.Parse(time.RFC3339Nano, info.Metadata[tierModifiedMetadata])
+	return ref, true
+}
+
+// writeStub stores objectName on primary as a stub pointing to ref. It keeps
+// the content type and tags of the object, so that rules still select it.
+func writeStub(
+	ctx context.Context,
+	primary storage.Client,
+	bucketName string,
+	objectName string,
+	ref tierRef,
+	contentType string,
+	tags map[string]string,
+) (storage.ObjectInfo, error) {
+	return primary.PutObject(ctx, bucketName, objectName, bytes.NewReader(nil), 0, storage.PutObjectOptions{
+		ContentType: contentType,
+		Metadata: map[string]string{
+			tierKeyMetadata:      ref.key,
+			tierVersionMetadata:  ref.versionID,
+			tierModifiedMetadata: ref.modified.UTC().Format(time.RFC3339Nano),
+		},
+		Tags: tags,
+	})
+}
+
+// tieredClient serves transitioned objects from the secondary backend.
+// The stub left on the primary backend decides: an object missing from the
+// primary one is missing, whatever the secondary one holds. Reads and
+// copies of stubs go to the secondary backend, and removing a stub for
+// good removes the content it points to. Every other operation goes to the
+// primary backend.
+type tieredClient struct {
+	storage.Client
+	secondary storage.Client
+}
+
+// NewTieredClient wraps primary so that objects transitioned to secondary
+// are read, copied and removed there.
+func NewTieredClient(primary, secondary storage.Client) storage.Client {
+	return &tieredClient{Client: primary, secondary: secondary}
+}
+
+// Unwrap returns the primary client.
+func (t *tieredClient) Unwrap() storage.Client {
+	return t.Client
+}
+
+// resolve returns the metadata of the object on the primary backend and,
+// if it is a stub, the reference to its content.
+func (t *tieredClient) resolve(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	versionID string,
+) (storage.ObjectInfo, tierRef, bool, error) {
+	info, err := t.Client.GetObjectInfo(ctx, bucketName, objectName, storage.GetObjectInfoOptions{VersionID: versionID})
+	if err != nil {
+		return storage.ObjectInfo{}, tierRef{}, false, err
+	}
+	ref, ok := stubRef(info)
+	return info, ref, ok, nil
+}
+
+// content returns the metadata of the content stub points to, presented as
+// the object: under the stub's name, version and tags, and with its own
+// modification time.
+func (t *tieredClient) content(
+	ctx context.Context,
+	bucketName string,
+	stub storage.ObjectInfo,
+	ref tierRef,
+) (storage.ObjectInfo, error) {
+	info, err := t.secondary.GetObjectInfo(ctx, bucketName, ref.key, storage.GetObjectInfoOptions{VersionID: ref.versionID})
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	info.Key = stub.Key
+	info.VersionID = stub.VersionID
+	info.IsLatest = stub.IsLatest
+	info.Tags = stub.Tags
+	if !ref.modified.IsZero() {
+		info.LastModified = ref.modified
+	}
+	return info, nil
+}
+
+func (t *tieredClient) GetObjectInfo(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectInfoOptions,
+) (storage.ObjectInfo, error) {
+	info, ref, ok, err := t.resolve(ctx, bucketName, objectName, opts.VersionID)
+	if err != nil || !ok {
+		return info, err
+	}
+	return t.content(ctx, bucketName, info, ref)
+}
+
+// GetObject reads the object, from the secondary backend if it was
+// transitioned, which takes a metadata lookup on the primary one first.
+func (t *tieredClient) GetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectOptions,
+) (io.ReadCloser, error) {
+	_, ref, ok, err := t.resolve(ctx, bucketName, objectName, opts.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return t.Client.GetObject(ctx, bucketName, objectName, opts)
+	}
+	opts.VersionID = ref.versionID
+	return t.secondary.GetObject(ctx, bucketName, ref.key, opts)
+}
+
+func (t *tieredClient) GetObjectWithRange(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	start int64,
+	end int64,
+) (io.ReadCloser, error) {
+	return t.GetObject(ctx, bucketName, objectName, storage.GetObjectOptions{Start: start, End: end})
+}
+
+// ListObjects lists the objects of the primary backend, reporting the size
+// and ETag of the content of transitioned ones rather than of their stubs.
+func (t *tieredClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts storage.ListObjectsOptions,
+) ([]storage.ObjectInfo, error) {
+	objects, err := t.Client.ListObjects(ctx, bucketName, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i, object := range objects {
+		// Listings do not reliably carry metadata: look empty objects up.
+		if object.Size != 0 || object.IsDeleteMarker {
+			continue
+		}
+		var versionID string
+		if opts.WithVersions {
+			versionID = object.VersionID
+		}
+		info, ref, ok, err := t.resolve(ctx, bucketName, object.Key, versionID)
+		if storage.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		content, err := t.content(ctx, bucketName, info, ref)
+		if err != nil {
+			return nil, err
+		}
+		objects[i].Size = content.Size
+		objects[i].ETag = content.ETag
+		objects[i].LastModified = content.LastModified
+	}
+	return objects, nil
+}
+
+// CopyObject copies the object. The content of a transitioned object is
+// copied on the secondary backend, under a stub of its own, so that each
+// stub owns the content it points to.
+func (t *tieredClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts storage.CopyObjectOptions,
+) (storage.ObjectInfo, error) {
+	src, ref, ok, err := t.resolve(ctx, bucketName, srcObjectName, opts.SrcVersionID)
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	if !ok {
+		return t.Client.CopyObject(ctx, bucketName, srcObjectName, dstObjectName, opts)
+	}
+	copied, err := t.secondary.CopyObject(ctx, bucketName, ref.key, dstObjectName, storage.CopyObjectOptions{
+		SrcVersionID: ref.versionID,
+	})
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	stub, err := writeStub(ctx, t.Client, bucketName, dstObjectName, tierRef{
+		key:       dstObjectName,
+		versionID: copied.VersionID,
+		modified:  ref.modified,
+	}, src.ContentType, src.Tags)
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	stub.Size = copied.Size
+	stub.ETag = copied.ETag
+	return stub, nil
+}
+
+// RemoveObject removes the object. When that removes a stub for good,
+// rather than hiding it behind a delete marker, the content it points to
+// is removed too.
+func (t *tieredClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.RemoveObjectOptions,
+) error {
+	info, ref, ok, err := t.resolve(ctx, bucketName, objectName, opts.VersionID)
+	if err != nil && !storage.IsNotFound(err) {
+		return err
+	}
+	if err := t.Client.RemoveObject(ctx, bucketName, objectName, opts); err != nil {
+		return err
+	}
+	if !ok || (opts.VersionID == "" && info.VersionID != "") {
+		return nil
+	}
+	return t.secondary.RemoveObject(ctx, bucketName, ref.key, storage.RemoveObjectOptions{VersionID: ref.versionID})
+}
@@ -0,0 +1,142 @@
+// Package listener opens the listeners the servers are bound to from
+// address strings, supporting TCP addresses, Unix domain sockets and
+// sockets passed by systemd socket activation.
+package listener
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Address prefixes selecting the kind of listener.
+const (
+	// UnixPrefix selects a Unix domain socket, e.g. "unix:/run/fs.sock".
+	UnixPrefix = "unix:"
+	// SystemdPrefix selects a socket passed by systemd, by its
+	// FileDescriptorName= or by its index among the passed sockets, e.g.
+	// "systemd:http" or "systemd:0".
+	SystemdPrefix = "systemd:"
+)
+
+// Listen opens a listener for address: a TCP "host:port" (or ":port"), a
+// "unix:" path or a "systemd:" socket.
+func Listen(address string) (net.Listener, error) {
+	switch {
+	case strings.HasPrefix(address, UnixPrefix):
+		return listenUnix(strings.TrimPrefix(address, UnixPrefix))
+	case strings.HasPrefix(address, SystemdPrefix):
+		return listenSystemd(strings.TrimPrefix(address, SystemdPrefix))
+	default:
+		return net.Listen("tcp", address)
+	}
+}
+
+// listenUnix listens on a Unix domain socket at path, replacing a socket
+// left behind by a previous process. The socket file is removed when the
+// listener is closed.
+func listenUnix(path string) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// The sockets passed by systemd, read once from the environment.
+var (
+	systemdOnce    sync.Once
+	systemdSockets []systemdSocket
+	systemdErr     error
+)
+
+type systemdSocket struct {
+	name string
+	file *os.File
+	used bool
+}
+
+// listenSystemd returns the listener for the socket passed by systemd with
+// the given name or index. Each socket can only be used once.
+func listenSystemd(selector string) (net.Listener, error) {
+	systemdOnce.Do(func() {
+		systemdSockets, systemdErr = readSystemdSockets()
+	})
+	if systemdErr != nil {
+		return nil, systemdErr
+	}
+	socket, err := selectSystemdSocket(selector)
+	if err != nil {
+		return nil, err
+	}
+	socket.used = true
+	ln, err := net.FileListener(socket.file)
+	if err != nil {
+		return nil, fmt.Errorf("systemd socket %q: %w", selector, err)
+	}
+	// FileListener duplicated the descriptor.
+	socket.file.Close()
+	return ln, nil
+}
+
+func selectSystemdSocket(selector string) (*systemdSocket, error) {
+	if index, err := strconv.Atoi(selector); err == nil {
+		if index < 0 || index >= len(systemdSockets) {
+			return nil, fmt.Errorf("systemd passed %d sockets; no socket %d", len(systemdSockets), index)
+		}
+		if systemdSockets[index].used {
+			return nil, fmt.Errorf("systemd socket %d is already in use", index)
+		}
+		return &systemdSockets[index], nil
+	}
+	for i := range systemdSockets {
+		if systemdSockets[i].name == selector && !systemdSockets[i].used {
+			return &systemdSockets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no unused systemd socket named %q", selector)
+}
+
+// listenFDsStart is the first file descriptor passed by systemd.
+const listenFDsStart = 3
+
+// readSystemdSockets reads the sockets passed by systemd as described in
+// sd_listen_fds(3), and unsets the variables so that child processes do not
+// inherit them.
+func readSystemdSockets() ([]systemdSocket, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, errors.New("no sockets were passed by systemd")
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count <= 0 {
+		return nil, errors.New("no sockets were passed by systemd")
+	}
+	var names []string
+	if v := os.Getenv("LISTEN_FDNAMES"); v != "" {
+		names = strings.Split(v, ":")
+	}
+	sockets := make([]systemdSocket, count)
+	for i := range sockets {
+		fd := listenFDsStart + i
+		sockets[i].file = os.NewFile(uintptr(fd), "systemd-socket-"+strconv.Itoa(fd))
+		if i < len(names) {
+			sockets[i].name = names[i]
+		}
+	}
+	return sockets, nil
+}
@@ -0,0 +1,224 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Access log formats.
+const (
+	// AccessFormatCombined is the Apache/NCSA Combined Log Format, followed
+	// by the duration in milliseconds and the request ID.
+	AccessFormatCombined = "combined"
+	// AccessFormatJSON writes one JSON object per request.
+	AccessFormatJSON = "json"
+	// AccessFormatOff disables the access log.
+	AccessFormatOff = "off"
+)
+
+// AccessLogger writes one access log entry per HTTP request or RPC.
+type AccessLogger struct {
+	format string
+	mu     sync.Mutex
+	w      io.Writer
+	json   *slog.Logger
+}
+
+// NewAccessLogger returns an AccessLogger writing entries to w in format.
+// It returns nil for AccessFormatOff; request IDs are still assigned then.
+func NewAccessLogger(w io.Writer, format string) (*AccessLogger, error) {
+	switch format {
+	case AccessFormatOff:
+		return nil, nil
+	case AccessFormatCombined:
+		return &AccessLogger{format: format, w: w}, nil
+	case AccessFormatJSON, "":
+		return &AccessLogger{format: AccessFormatJSON, json: slog.New(slog.NewJSONHandler(w, nil))}, nil
+	default:
+		return nil, fmt.Errorf("invalid access log format %q", format)
+	}
+}
+
+// entry is one access log entry.
+type entry struct {
+	remote    string
+	user      string
+	start     time.Time
+	method    string
+	uri       string
+	proto     string
+	status    string
+	sent      int64
+	received  int64
+	referer   string
+	userAgent string
+	requestID string
+}
+
+func (a *AccessLogger) log(e entry) {
+	if a == nil {
+		return
+	}
+	duration := time.Since(e.start)
+	if a.format == AccessFormatJSON {
+		a.json.LogAttrs(context.Background(), slog.LevelInfo, "access",
+			slog.String("request_id", e.requestID),
+			slog.String("remote", e.remote),
+			slog.String("user", e.user),
+			slog.String("method", e.method),
+			slog.String("uri", e.uri),
+			slog.String("proto", e.proto),
+			slog.String("status", e.status),
+			slog.Int64("bytes_sent", e.sent),
+			slog.Int64("bytes_received", e.received),
+			slog.Float64("duration_ms", float64(duration.Microseconds())/1000),
+			slog.String("referer", e.referer),
+			slog.String("user_agent", e.userAgent),
+		)
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	fmt.Fprintf(a.w, "%s - %s [%s] \"%s %s %s\" %s %s \"%s\" \"%s\" %.3f %s\n",
+		e.remote,
+		dash(e.user),
+		e.start.Format("02/Jan/2006:15:04:05 -0700"),
+		e.method, e.uri, e.proto,
+		e.status,
+		combinedBytes(e.sent),
+		dash(e.referer),
+		dash(e.userAgent),
+		float64(duration.Microseconds())/1000,
+		e.requestID,
+	)
+}
+
+func dash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// combinedBytes formats a body size as the Combined Log Format does, with
+// "-" for no body.
+func combinedBytes(n int64) string {
+	if n == 0 {
+		return "-"
+	}
+	return fmt.Sprint(n)
+}
+
+// Middleware assigns every request an ID, stores it with a request-scoped
+// logger in the request context and echoes it in the X-Request-Id response
+// header, then logs the request once served.
+func (a *AccessLogger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx, req := withRequest(r.Context(), requestID(r.Header.Get(RequestIDHeader)))
+		w.Header().Set(RequestIDHeader, req.id)
+		rec := &responseRecorder{ResponseWriter: w, code: http.StatusOK}
+		body := &countingBody{ReadCloser: r.Body}
+		if r.Body != nil {
+			r.Body = body
+		}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		a.log(entry{
+			remote:    host(r.RemoteAddr),
+			user:      req.user,
+			start:     start,
+			method:    r.Method,
+			uri:       redactURI(r.URL),
+			proto:     r.Proto,
+			status:    fmt.Sprint(rec.code),
+			sent:      rec.written,
+			received:  body.read,
+			referer:   r.Referer(),
+			userAgent: r.UserAgent(),
+			requestID: req.id,
+		})
+	})
+}
+
+// sensitiveParams are the query parameters carrying credentials.
+var sensitiveParams = []string{"password", "token", "api_key", "access_token"}
+
+// redactURI returns the request URI of u without the credentials it may
+// carry: the token of share links (/s/{token}) is masked and credential
+// query parameters are dropped.
+func redactURI(u *url.URL) string {
+	redacted := *u
+	if token, ok := strings.CutPrefix(u.Path, "/s/"); ok && token != "" {
+		redacted.Path = "/s/REDACTED"
+		redacted.RawPath = ""
+	}
+	query := u.Query()
+	dropped := false
+	for _, name := range sensitiveParams {
+		if query.Has(name) {
+			query.Del(name)
+			dropped = true
+		}
+	}
+	if dropped {
+		redacted.RawQuery = query.Encode()
+	}
+	return redacted.RequestURI()
+}
+
+// host returns the host of a host:port address.
+func host(addr string) string {
+	h, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return h
+}
+
+// responseRecorder captures the status code and body size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	code        int
+	written     int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.written += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// countingBody counts the bytes read from a request body.
+type countingBody struct {
+	io.ReadCloser
+	read int64
+}
+
+func (c *countingBody) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += int64(n)
+	return n, err
+}
@@ -0,0 +1,127 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+)
+
+// Interceptor assigns every RPC a request ID, stores it with a
+// request-scoped logger in the handler's context and echoes it in the
+// X-Request-Id response header, then logs the RPC with its code, the
+// payload bytes of the messages exchanged and its duration.
+type Interceptor struct {
+	logger *AccessLogger
+}
+
+// Compile-time check to ensure Interceptor implements connect.Interceptor.
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor returns an Interceptor logging to logger, which may be nil.
+func NewInterceptor(logger *AccessLogger) *Interceptor {
+	return &Interceptor{logger: logger}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		start := time.Now()
+		ctx, r := withRequest(ctx, requestID(req.Header().Get(RequestIDHeader)))
+		res, err := next(ctx, req)
+		var sent int64
+		if err == nil {
+			res.Header().Set(RequestIDHeader, r.id)
+			sent = int64(messageSize(res.Any()))
+		} else if connectErr := new(connect.Error); errors.As(err, &connectErr) {
+			connectErr.Meta().Set(RequestIDHeader, r.id)
+		}
+		i.log(r, req.Spec().Procedure, req.Peer(), req.Header(), start, sent, int64(messageSize(req.Any())), err)
+		return res, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		start := time.Now()
+		ctx, r := withRequest(ctx, requestID(conn.RequestHeader().Get(RequestIDHeader)))
+		conn.ResponseHeader().Set(RequestIDHeader, r.id)
+		counting := &countingConn{StreamingHandlerConn: conn}
+		err := next(ctx, counting)
+		i.log(r, conn.Spec().Procedure, conn.Peer(), conn.RequestHeader(), start, counting.sent, counting.received, err)
+		return err
+	}
+}
+
+func (i *Interceptor) log(
+	r *request,
+	procedure string,
+	peer connect.Peer,
+	header http.Header,
+	start time.Time,
+	sent int64,
+	received int64,
+	err error,
+) {
+	status := "ok"
+	if err != nil {
+		status = connect.CodeOf(err).String()
+	}
+	i.logger.log(entry{
+		remote:    host(peer.Addr),
+		user:      r.user,
+		start:     start,
+		method:    "RPC",
+		uri:       procedure,
+		proto:     peer.Protocol,
+		status:    status,
+		sent:      sent,
+		received:  received,
+		userAgent: header.Get("User-Agent"),
+		requestID: r.id,
+	})
+}
+
+// countingConn counts the payload bytes of the messages of a stream.
+type countingConn struct {
+	connect.StreamingHandlerConn
+	sent     int64
+	received int64
+}
+
+func (c *countingConn) Receive(msg any) error {
+	if err := c.StreamingHandlerConn.Receive(msg); err != nil {
+		return err
+	}
+	c.received += int64(messageSize(msg))
+	return nil
+}
+
+func (c *countingConn) Send(msg any) error {
+	if err := c.StreamingHandlerConn.Send(msg); err != nil {
+		return err
+	}
+	c.sent += int64(messageSize(msg))
+	return nil
+}
+
+// messageSize returns the encoded size of a protobuf message.
+func messageSize(msg any) int {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return 0
+	}
+	return proto.Size(m)
+}
@@ -0,0 +1,124 @@
+// Package logging configures structured logging with log/slog and provides
+// request-scoped loggers carrying request IDs, along with access logs for
+// the HTTP server and the Connect RPCs.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// RequestIDHeader carries the request ID. A client-supplied ID is kept so
+// that requests can be correlated across services; otherwise one is
+// generated. The ID is echoed in the response.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength bounds client-supplied request IDs.
+const maxRequestIDLength = 128
+
+// minLevel is the minimum level of the default logger, which SetLevel changes
+// at runtime.
+var minLevel slog.LevelVar
+
+// Setup installs the default slog logger writing to w at level ("debug",
+// "info", "warn" or "error") in format ("text" or "json"). Output of the
+// standard log package is routed through it as well.
+func Setup(w io.Writer, level, format string) error {
+	if err := SetLevel(level); err != nil {
+		return err
+	}
+	opts := &slog.HandlerOptions{Level: &minLevel}
+	var handler slog.Handler
+	switch strings.ToLower(format) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return fmt.Errorf("invalid log format %q", format)
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
+
+// SetLevel changes the minimum level of the default logger installed by
+// Setup.
+func SetLevel(name string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(name)); err != nil {
+		return fmt.Errorf("invalid log level %q", name)
+	}
+	minLevel.Set(lvl)
+	return nil
+}
+
+// request holds what is known about a request as it is served. It is
+// shared by pointer so that handlers running inside the access log can
+// complete it, e.g. with the authenticated principal.
+type request struct {
+	id   string
+	user string
+}
+
+type requestKey struct{}
+
+// withRequest returns a copy of ctx carrying a request with the given ID.
+func withRequest(ctx context.Context, id string) (context.Context, *request) {
+	req := &request{id: id}
+	return context.WithValue(ctx, requestKey{}, req), req
+}
+
+// RequestID returns the ID of the request served with ctx, if any.
+func RequestID(ctx context.Context) string {
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		return req.id
+	}
+	return ""
+}
+
+// SetUser records the principal of the request served with ctx for its
+// access log entry.
+func SetUser(ctx context.Context, user string) {
+	if req, ok := ctx.Value(requestKey{}).(*request); ok {
+		req.user = user
+	}
+}
+
+// FromContext returns the default logger annotated with the ID of the
+// request served with ctx.
+func FromContext(ctx context.Context) *slog.Logger {
+	if id := RequestID(ctx); id != "" {
+		return slog.Default().With("request_id", id)
+	}
+	return slog.Default()
+}
+
+// requestID returns the client-supplied ID when it is usable, or a new one.
+func requestID(supplied string) string {
+	if supplied != "" && len(supplied) <= maxRequestIDLength && printable(supplied) {
+		return supplied
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func printable(s string) bool {
+	for _, r := range s {
+		if r < 0x21 || r > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	admissionInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "admission_in_flight",
+		Help:      "Requests admitted and in progress, by class.",
+	}, []string{"class"})
+	admissionQueued = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "admission_queued",
+		Help:      "Requests waiting for a slot, by class and priority.",
+	}, []string{"class", "priority"})
+	admissionShed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "admission_shed_total",
+		Help:      "Requests shed by admission control, by class and reason (rejected with a full queue or timed_out waiting).",
+	}, []string{"class", "reason"})
+)
+
+func init() {
+	registry.MustRegister(admissionInFlight, admissionQueued, admissionShed)
+}
+
+// AddAdmissionInFlight adds delta to the requests of class in progress.
+func AddAdmissionInFlight(class string, delta int) {
+	admissionInFlight.WithLabelValues(class).Add(float64(delta))
+}
+
+// AddAdmissionQueued adds delta to the requests of class waiting with
+// priority.
+func AddAdmissionQueued(class, priority string, delta int) {
+	admissionQueued.WithLabelValues(class, priority).Add(float64(delta))
+}
+
+// RecordAdmissionShed counts a request of class shed for reason,
+// "rejected" or "timed_out".
+func RecordAdmissionShed(class, reason string) {
+	admissionShed.WithLabelValues(class, reason).Inc()
+}
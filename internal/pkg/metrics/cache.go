@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_cache_lookups_total",
+		Help:      "Disk cache block lookups, by result (hit or miss).",
+	}, []string{"result"})
+	cacheCoalesced = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_cache_coalesced_total",
+		Help:      "Disk cache misses that waited for a fill already in progress.",
+	})
+	cacheEvictions = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_cache_evictions_total",
+		Help:      "Blocks evicted from the disk cache.",
+	})
+	cacheFillErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_cache_fill_errors_total",
+		Help:      "Blocks that could not be stored in the disk cache.",
+	})
+)
+
+// cacheSize reports the size of the disk cache on scrape.
+var cacheSize struct {
+	sync.Mutex
+	bytes func() int64
+}
+
+func init() {
+	registry.MustRegister(
+		cacheLookups, cacheCoalesced, cacheEvictions, cacheFillErrors,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "storage_cache_bytes",
+			Help:      "Size of the blocks in the disk cache.",
+		}, func() float64 {
+			cacheSize.Lock()
+			defer cacheSize.Unlock()
+			if cacheSize.bytes == nil {
+				return 0
+			}
+			return float64(cacheSize.bytes())
+		}),
+	)
+}
+
+// RecordCacheLookup counts a block looked up in the disk cache.
+func RecordCacheLookup(hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(result).Inc()
+}
+
+// RecordCacheCoalesced counts a miss that waited for a fill in progress.
+func RecordCacheCoalesced() {
+	cacheCoalesced.Inc()
+}
+
+// RecordCacheEviction counts a block evicted from the disk cache.
+func RecordCacheEviction() {
+	cacheEvictions.Inc()
+}
+
+// RecordCacheFillError counts a block that could not be cached.
+func RecordCacheFillError() {
+	cacheFillErrors.Inc()
+}
+
+// SetCacheSize makes the cache size gauge report the value returned by
+// bytes.
+func SetCacheSize(bytes func() int64) {
+	cacheSize.Lock()
+	defer cacheSize.Unlock()
+	cacheSize.bytes = bytes
+}
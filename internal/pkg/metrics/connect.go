@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"connectrpc.com/connect"
+	"google.golang.org/protobuf/proto"
+)
+
+// Interceptor records the count and latency of every RPC, the size of the
+// messages exchanged and the number of active streams.
+type Interceptor struct{}
+
+// Compile-time check to ensure Interceptor implements connect.Interceptor.
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor returns an Interceptor.
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		start := time.Now()
+		bytesReceived.WithLabelValues(TransportConnect).Add(float64(messageSize(req.Any())))
+		res, err := next(ctx, req)
+		if err == nil {
+			bytesSent.WithLabelValues(TransportConnect).Add(float64(messageSize(res.Any())))
+		}
+		observeRPC(req.Spec().Procedure, start, err)
+		return res, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		active := activeStreams.WithLabelValues(TransportConnect)
+		active.Inc()
+		defer active.Dec()
+		start := time.Now()
+		err := next(ctx, &countingConn{StreamingHandlerConn: conn})
+		observeRPC(conn.Spec().Procedure, start, err)
+		return err
+	}
+}
+
+func observeRPC(procedure string, start time.Time, err error) {
+	code := "ok"
+	if err != nil {
+		code = connect.CodeOf(err).String()
+	}
+	rpcDuration.WithLabelValues(procedure).Observe(time.Since(start).Seconds())
+	rpcRequests.WithLabelValues(procedure, code).Inc()
+}
+
+// countingConn counts the payload bytes of the messages of a stream.
+type countingConn struct {
+	connect.StreamingHandlerConn
+}
+
+func (c *countingConn) Receive(msg any) error {
+	if err := c.StreamingHandlerConn.Receive(msg); err != nil {
+		return err
+	}
+	bytesReceived.WithLabelValues(TransportConnect).Add(float64(messageSize(msg)))
+	return nil
+}
+
+func (c *countingConn) Send(msg any) error {
+	if err := c.StreamingHandlerConn.Send(msg); err != nil {
+		return err
+	}
+	bytesSent.WithLabelValues(TransportConnect).Add(float64(messageSize(msg)))
+	return nil
+}
+
+// messageSize returns the encoded size of a protobuf message.
+func messageSize(msg any) int {
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return 0
+	}
+	return proto.Size(m)
+}
@@ -0,0 +1,88 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Middleware records the count, latency and transferred bytes of every
+// request to next. route returns the route pattern a request matches, used
+// as the route label so that file names do not create new series.
+func Middleware(route func(r *http.Request) string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		pattern := route(r)
+		if pattern == "" {
+			pattern = "unmatched"
+		}
+		active := activeStreams.WithLabelValues(TransportHTTP)
+		active.Inc()
+		defer active.Dec()
+		start := time.Now()
+		rec := &responseRecorder{ResponseWriter: w, code: http.StatusOK}
+		if r.Body != nil && r.Body != http.NoBody {
+			r.Body = &countingReader{ReadCloser: r.Body}
+		}
+		next.ServeHTTP(rec, r)
+		httpDuration.WithLabelValues(pattern, r.Method).Observe(time.Since(start).Seconds())
+		httpRequests.WithLabelValues(pattern, r.Method, strconv.Itoa(rec.code)).Inc()
+		bytesSent.WithLabelValues(TransportHTTP).Add(float64(rec.written))
+		if body, ok := r.Body.(*countingReader); ok {
+			bytesReceived.WithLabelValues(TransportHTTP).Add(float64(body.read))
+		}
+	})
+}
+
+// responseRecorder captures the status code and body size of a response.
+type responseRecorder struct {
+	http.ResponseWriter
+	code        int
+	written     int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(code int) {
+	if !r.wroteHeader {
+		r.code = code
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *responseRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(p)
+	r.written += int64(n)
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// countingReader counts the bytes read from a request body.
+type countingReader struct {
+	io.ReadCloser
+	read int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.read += int64(n)
+	return n, err
+}
+
+// CountingWriter counts the bytes written to W, e.g. to measure the output
+// of a compressor.
+type CountingWriter struct {
+	W       io.Writer
+	Written int64
+}
+
+func (c *CountingWriter) Write(p []byte) (int, error) {
+	n, err := c.W.Write(p)
+	c.Written += int64(n)
+	return n, err
+}
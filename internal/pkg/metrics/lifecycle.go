@@ -0,0 +1,18 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var lifecycleActions = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: namespace,
+	Name:      "lifecycle_actions_total",
+	Help:      "Lifecycle actions by kind (expire, transition or abort_upload) and result (planned in dry runs, executed or failed).",
+}, []string{"kind", "result"})
+
+func init() {
+	registry.MustRegister(lifecycleActions)
+}
+
+// RecordLifecycleAction counts a lifecycle action of kind with result.
+func RecordLifecycleAction(kind, result string) {
+	lifecycleActions.WithLabelValues(kind, result).Inc()
+}
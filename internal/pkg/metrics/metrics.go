@@ -0,0 +1,140 @@
+// Package metrics exposes Prometheus metrics for both transports and the
+// storage backends: request counts and latencies per HTTP route and RPC,
+// transferred bytes, active streams, download and upload outcomes, and the
+// latency and errors of every storage call.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "file_streamer"
+
+// Transports reported in the transport label.
+const (
+	TransportHTTP    = "http"
+	TransportConnect = "connect"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by route, method and status code.",
+	}, []string{"route", "method", "code"})
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method"})
+	rpcRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rpc_requests_total",
+		Help:      "Connect RPCs by procedure and code.",
+	}, []string{"procedure", "code"})
+	rpcDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "rpc_duration_seconds",
+		Help:      "Connect RPC latency by procedure.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"procedure"})
+	bytesSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bytes_sent_total",
+		Help:      "Bytes sent to clients: response bodies over HTTP, message payloads over Connect.",
+	}, []string{"transport"})
+	bytesReceived = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bytes_received_total",
+		Help:      "Bytes received from clients: request bodies over HTTP, message payloads over Connect.",
+	}, []string{"transport"})
+	activeStreams = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_streams",
+		Help:      "Requests and streams in progress.",
+	}, []string{"transport"})
+	downloads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "downloads_total",
+		Help:      "Downloads started, by whether they requested a byte range.",
+	}, []string{"transport", "ranged"})
+	compressionIn = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "compression_input_bytes_total",
+		Help:      "Bytes passed to the compressor; divide compression_output_bytes_total by it for the compression ratio.",
+	}, []string{"transport"})
+	compressionOut = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "compression_output_bytes_total",
+		Help:      "Compressed bytes produced by the compressor.",
+	}, []string{"transport"})
+	uploads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "uploads_total",
+		Help:      "Uploads finished, by result.",
+	}, []string{"transport", "result"})
+	storageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_call_duration_seconds",
+		Help:      "Storage backend call latency by backend and method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"backend", "method"})
+	storageErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_call_errors_total",
+		Help:      "Failed storage backend calls by backend and method.",
+	}, []string{"backend", "method"})
+	storageRouting = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_routing_total",
+		Help:      "Storage calls failed over to another endpoint, reads hedged and hedged reads won by the duplicate, by event.",
+	}, []string{"event"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests, httpDuration,
+		rpcRequests, rpcDuration,
+		bytesSent, bytesReceived, activeStreams,
+		downloads, compressionIn, compressionOut, uploads,
+		storageDuration, storageErrors, storageRouting,
+	)
+}
+
+// Handler serves the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// RecordDownload counts a download started over transport.
+func RecordDownload(transport string, ranged bool) {
+	label := "false"
+	if ranged {
+		label = "true"
+	}
+	downloads.WithLabelValues(transport, label).Inc()
+}
+
+// RecordCompression counts in bytes compressed into out bytes.
+func RecordCompression(transport string, in, out int64) {
+	compressionIn.WithLabelValues(transport).Add(float64(in))
+	compressionOut.WithLabelValues(transport).Add(float64(out))
+}
+
+// RecordUpload counts an upload that finished with err.
+func RecordUpload(transport string, err error) {
+	result := "completed"
+	if err != nil {
+		result = "failed"
+	}
+	uploads.WithLabelValues(transport, result).Inc()
+}
@@ -0,0 +1,91 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	readaheadReads = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readahead_reads_total",
+		Help:      "Downloads served with read-ahead.",
+	})
+	readaheadChunks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readahead_chunks_total",
+		Help:      "Chunks fetched by read-ahead, by whether they were prefetched into a buffer or, the pool being exhausted, read unbuffered.",
+	}, []string{"buffered"})
+	readaheadBytes = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readahead_bytes_total",
+		Help:      "Bytes served with read-ahead.",
+	})
+	readaheadFetchSeconds = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readahead_fetch_seconds_total",
+		Help:      "Time spent fetching prefetched chunks; divided by the wait time, the speedup of read-ahead.",
+	})
+	readaheadWaitSeconds = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "readahead_wait_seconds_total",
+		Help:      "Time readers spent waiting for prefetched chunks.",
+	})
+)
+
+// readaheadBuffers reports the memory of the read-ahead buffers on scrape.
+var readaheadBuffers struct {
+	sync.Mutex
+	bytes func() int64
+}
+
+func init() {
+	registry.MustRegister(
+		readaheadReads, readaheadChunks, readaheadBytes, readaheadFetchSeconds, readaheadWaitSeconds,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "readahead_buffer_bytes",
+			Help:      "Memory of the read-ahead buffers in use.",
+		}, func() float64 {
+			readaheadBuffers.Lock()
+			defer readaheadBuffers.Unlock()
+			if readaheadBuffers.bytes == nil {
+				return 0
+			}
+			return float64(readaheadBuffers.bytes())
+		}),
+	)
+}
+
+// RecordReadahead counts a download served with read-ahead.
+func RecordReadahead() {
+	readaheadReads.Inc()
+}
+
+// RecordReadaheadChunk counts a chunk fetched by read-ahead.
+func RecordReadaheadChunk(buffered bool) {
+	readaheadChunks.WithLabelValues(strconv.FormatBool(buffered)).Inc()
+}
+
+// RecordReadaheadBytes counts n bytes served with read-ahead.
+func RecordReadaheadBytes(n int) {
+	readaheadBytes.Add(float64(n))
+}
+
+// RecordReadaheadWait records that a reader waited wait for a chunk that
+// took fetch to fetch.
+func RecordReadaheadWait(wait, fetch time.Duration) {
+	readaheadWaitSeconds.Add(wait.Seconds())
+	readaheadFetchSeconds.Add(fetch.Seconds())
+}
+
+// SetReadaheadBuffers makes the buffer memory gauge report the value
+// returned by bytes.
+func SetReadaheadBuffers(bytes func() int64) {
+	readaheadBuffers.Lock()
+	defer readaheadBuffers.Unlock()
+	readaheadBuffers.bytes = bytes
+}
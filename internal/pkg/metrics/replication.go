@@ -0,0 +1,100 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	replicationObjects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "replication_objects_total",
+		Help:      "Objects replicated to the replication target, by action (copied or removed).",
+	}, []string{"action"})
+	replicationFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "replication_failures_total",
+		Help:      "Failed attempts to replicate an object.",
+	})
+	replicationDivergent = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "replication_divergent_total",
+		Help:      "Objects found to differ from the replication target by reconciliation.",
+	})
+	replicationEnqueueErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "replication_enqueue_errors_total",
+		Help:      "Objects that could not be enqueued for replication.",
+	})
+)
+
+// replicationQueue reports the state of the replication queue on scrape.
+var replicationQueue struct {
+	sync.Mutex
+	pending func() int
+	lag     func() time.Duration
+}
+
+func init() {
+	registry.MustRegister(
+		replicationObjects, replicationFailures, replicationDivergent, replicationEnqueueErrors,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "replication_pending",
+			Help:      "Objects waiting to be replicated.",
+		}, func() float64 {
+			replicationQueue.Lock()
+			defer replicationQueue.Unlock()
+			if replicationQueue.pending == nil {
+				return 0
+			}
+			return float64(replicationQueue.pending())
+		}),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "replication_lag_seconds",
+			Help:      "How long the oldest object waiting to be replicated has been waiting.",
+		}, func() float64 {
+			replicationQueue.Lock()
+			defer replicationQueue.Unlock()
+			if replicationQueue.lag == nil {
+				return 0
+			}
+			return replicationQueue.lag().Seconds()
+		}),
+	)
+}
+
+// RecordReplication counts an object replicated with action, "copied" or
+// "removed".
+func RecordReplication(action string) {
+	replicationObjects.WithLabelValues(action).Inc()
+}
+
+// RecordReplicationFailure counts a failed attempt to replicate an object.
+func RecordReplicationFailure() {
+	replicationFailures.Inc()
+}
+
+// RecordReplicationDivergent counts n objects found divergent by
+// reconciliation.
+func RecordReplicationDivergent(n int) {
+	replicationDivergent.Add(float64(n))
+}
+
+// RecordReplicationEnqueueError counts an object that could not be enqueued
+// for replication.
+func RecordReplicationEnqueueError() {
+	replicationEnqueueErrors.Inc()
+}
+
+// SetReplicationQueue makes the replication gauges report the number of
+// pending objects and the replication lag as returned by pending and lag.
+func SetReplicationQueue(pending func() int, lag func() time.Duration) {
+	replicationQueue.Lock()
+	defer replicationQueue.Unlock()
+	replicationQueue.pending = pending
+	replicationQueue.lag = lag
+}
@@ -0,0 +1,55 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	storageRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_retries_total",
+		Help:      "Storage calls retried after a transient failure, by backend.",
+	}, []string{"backend"})
+	storageReconnects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_reconnects_total",
+		Help:      "Reads resumed from the last delivered byte after the backend failed mid-stream, by backend.",
+	}, []string{"backend"})
+	storageBreakerRejections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "storage_breaker_rejections_total",
+		Help:      "Storage calls failed fast by an open circuit breaker, by backend.",
+	}, []string{"backend"})
+	storageBreakerOpen = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "storage_breaker_open",
+		Help:      "Whether the circuit breaker of a backend is open (1) or closed (0).",
+	}, []string{"backend"})
+)
+
+func init() {
+	registry.MustRegister(storageRetries, storageReconnects, storageBreakerRejections, storageBreakerOpen)
+}
+
+// RecordStorageRetry counts a storage call retried on backend.
+func RecordStorageRetry(backend string) {
+	storageRetries.WithLabelValues(backend).Inc()
+}
+
+// RecordStorageReconnect counts a read of backend resumed mid-stream.
+func RecordStorageReconnect(backend string) {
+	storageReconnects.WithLabelValues(backend).Inc()
+}
+
+// RecordBreakerRejection counts a call to backend failed fast by its open
+// circuit breaker.
+func RecordBreakerRejection(backend string) {
+	storageBreakerRejections.WithLabelValues(backend).Inc()
+}
+
+// SetBreakerOpen records whether the circuit breaker of backend is open.
+func SetBreakerOpen(backend string, open bool) {
+	value := 0.0
+	if open {
+		value = 1
+	}
+	storageBreakerOpen.WithLabelValues(backend).Set(value)
+}
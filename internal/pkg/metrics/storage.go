@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// storageClient records the latency and errors of every call to a storage
+// backend. For reads, only the call opening the object is timed.
+type storageClient struct {
+	client  storage.Client
+	backend string
+}
+
+// Compile-time check to ensure storageClient implements storage.Client.
+var _ storage.Client = (*storageClient)(nil)
+
+// NewStorageClient wraps client so that its calls are recorded under the
+// backend label.
+func NewStorageClient(client storage.Client, backend string) storage.Client {
+	return &storageClient{client: client, backend: backend}
+}
+
+// Unwrap returns the instrumented client.
+func (s *storageClient) Unwrap() storage.Client {
+	return s.client
+}
+
+// observe records a call to method that started at start and returned err.
+func (s *storageClient) observe(method string, start time.Time, err error) {
+	storageDuration.WithLabelValues(s.backend, method).Observe(time.Since(start).Seconds())
+	// A bucket that already exists is the expected outcome at startup.
+	if err != nil && !errors.Is(err, storage.ErrBucketAlreadyExists) {
+		storageErrors.WithLabelValues(s.backend, method).Inc()
+	}
+}
+
+func (s *storageClient) CreateBucket(ctx context.Context, bucketName string) error {
+	start := time.Now()
+	err := s.client.CreateBucket(ctx, bucketName)
+	s.observe("CreateBucket", start, err)
+	return err
+}
+
+func (s *storageClient) DoesBucketExists(ctx context.Context, bucketName string) (bool, error) {
+	start := time.Now()
+	exists, err := s.client.DoesBucketExists(ctx, bucketName)
+	s.observe("DoesBucketExists", start, err)
+	return exists, err
+}
+
+func (s *storageClient) GetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectOptions,
+) (io.ReadCloser, error) {
+	start := time.Now()
+	obj, err := s.client.GetObject(ctx, bucketName, objectName, opts)
+	s.observe("GetObject", start, err)
+	return obj, err
+}
+
+func (s *storageClient) GetObjectWithRange(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	startOffset int64,
+	endOffset int64,
+) (io.ReadCloser, error) {
+	start := time.Now()
+	obj, err := s.client.GetObjectWithRange(ctx, bucketName, objectName, startOffset, endOffset)
+	s.observe("GetObjectWithRange", start, err)
+	return obj, err
+}
+
+func (s *storageClient) GetObjectInfo(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectInfoOptions,
+) (storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := s.client.GetObjectInfo(ctx, bucketName, objectName, opts)
+	s.observe("GetObjectInfo", start, err)
+	return info, err
+}
+
+func (s *storageClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	size int64,
+	opts storage.PutObjectOptions,
+) (storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := s.client.PutObject(ctx, bucketName, objectName, reader, size, opts)
+	s.observe("PutObject", start, err)
+	return info, err
+}
+
+func (s *storageClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts storage.ListObjectsOptions,
+) ([]storage.ObjectInfo, error) {
+	start := time.Now()
+	objects, err := s.client.ListObjects(ctx, bucketName, opts)
+	s.observe("ListObjects", start, err)
+	return objects, err
+}
+
+func (s *storageClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts storage.CopyObjectOptions,
+) (storage.ObjectInfo, error) {
+	start := time.Now()
+	info, err := s.client.CopyObject(ctx, bucketName, srcObjectName, dstObjectName, opts)
+	s.observe("CopyObject", start, err)
+	return info, err
+}
+
+func (s *storageClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.RemoveObjectOptions,
+) error {
+	start := time.Now()
+	err := s.client.RemoveObject(ctx, bucketName, objectName, opts)
+	s.observe("RemoveObject", start, err)
+	return err
+}
+
+func (s *storageClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	prefix string,
+) ([]storage.IncompleteUpload, error) {
+	start := time.Now()
+	uploads, err := s.client.ListIncompleteUploads(ctx, bucketName, prefix)
+	s.observe("ListIncompleteUploads", start, err)
+	return uploads, err
+}
+
+func (s *storageClient) AbortIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	uploadID string,
+) error {
+	start := time.Now()
+	err := s.client.AbortIncompleteUpload(ctx, bucketName, objectName, uploadID)
+	s.observe("AbortIncompleteUpload", start, err)
+	return err
+}
+
+func (s *storageClient) EnableVersioning(ctx context.Context, bucketName string) error {
+	start := time.Now()
+	err := s.client.EnableVersioning(ctx, bucketName)
+	s.observe("EnableVersioning", start, err)
+	return err
+}
+
+// RecordRoutingEvent counts a routing decision taken between storage
+// endpoints. It is meant to be passed to storage.WithRoutingObserver.
+func RecordRoutingEvent(event storage.RoutingEvent) {
+	storageRouting.WithLabelValues(string(event)).Inc()
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	upstreamRequests = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_requests_total",
+		Help:      "Requests made to the upstream file-streamer.",
+	})
+	upstreamMetadataLookups = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_metadata_lookups_total",
+		Help:      "Object metadata lookups answered without a full request upstream, by result (hit, revalidated or coalesced).",
+	}, []string{"result"})
+	upstreamShieldFailovers = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "upstream_shield_failovers_total",
+		Help:      "Requests that fell back from the origin shield to the origin.",
+	})
+)
+
+func init() {
+	registry.MustRegister(upstreamRequests, upstreamMetadataLookups, upstreamShieldFailovers)
+}
+
+// RecordUpstreamRequest counts a request made to the upstream
+// file-streamer.
+func RecordUpstreamRequest() {
+	upstreamRequests.Inc()
+}
+
+// RecordUpstreamMetadataLookup counts a metadata lookup answered from
+// memory ("hit"), revalidated with a 304 ("revalidated") or shared with
+// another caller's ("coalesced").
+func RecordUpstreamMetadataLookup(result string) {
+	upstreamMetadataLookups.WithLabelValues(result).Inc()
+}
+
+// RecordUpstreamShieldFailover counts a request that fell back from the
+// origin shield to the origin.
+func RecordUpstreamShieldFailover() {
+	upstreamShieldFailovers.Inc()
+}
@@ -0,0 +1,89 @@
+// Package namespace maps the namespaces clients address onto storage buckets
+// and holds the per-namespace settings.
+package namespace
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/config"
+)
+
+// ErrUnknownNamespace is returned when a request addresses a namespace that
+// is not configured.
+var ErrUnknownNamespace = errors.New("unknown namespace")
+
+// Namespace is an isolated set of files backed by a single bucket.
+type Namespace struct {
+	Name   string
+	Bucket string
+	// Versioned reports whether every upload keeps the previous versions.
+	Versioned bool
+	// TrashRetention is how long deleted files stay restorable.
+	TrashRetention time.Duration
+}
+
+// Registry holds the configured namespaces.
+type Registry struct {
+	defaultName string
+	namespaces  map[string]Namespace
+}
+
+// NewRegistry builds the namespace registry from config. The namespace named
+// after config.BucketName is always present and is used when a request does
+// not name one.
+func NewRegistry(config *config.Config) *Registry {
+	r := &Registry{
+		defaultName: config.BucketName,
+		namespaces:  make(map[string]Namespace),
+	}
+	names := append([]string{config.BucketName}, config.Namespaces...)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		r.namespaces[name] = Namespace{
+			Name:           name,
+			Bucket:         name,
+			Versioned:      slices.Contains(config.VersionedNamespaces, name),
+			TrashRetention: config.TrashRetention,
+		}
+	}
+	return r
+}
+
+// Resolve returns the namespace with the given name, or the default
+// namespace when name is empty.
+func (r *Registry) Resolve(name string) (Namespace, error) {
+	if name == "" {
+		name = r.defaultName
+	}
+	ns, ok := r.namespaces[name]
+	if !ok {
+		return Namespace{}, fmt.Errorf("%w: %q", ErrUnknownNamespace, name)
+	}
+	return ns, nil
+}
+
+// List returns every configured namespace sorted by name.
+func (r *Registry) List() []Namespace {
+	list := make([]Namespace, 0, len(r.namespaces))
+	for _, ns := range r.namespaces {
+		list = append(list, ns)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Canonical returns the name of the namespace addressed by name, resolving
+// the empty name to the default namespace. It returns "" for unknown names.
+func (r *Registry) Canonical(name string) string {
+	ns, err := r.Resolve(name)
+	if err != nil {
+		return ""
+	}
+	return ns.Name
+}
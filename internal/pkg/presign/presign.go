@@ -0,0 +1,228 @@
+// Package presign issues presigned URLs through which clients transfer files
+// directly to or from the storage backend, bypassing the server. Every URL
+// issued is recorded in the audit trail, and uploads can optionally be
+// verified by watching for the object until the URL expires. At most
+// maxWatchers uploads are watched at once.
+package presign
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/audit"
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// Methods a URL can be presigned for.
+const (
+	MethodGet = "GET"
+	MethodPut = "PUT"
+)
+
+const (
+	// DefaultTTL is the lifetime of URLs requested without an explicit one.
+	DefaultTTL = 15 * time.Minute
+	// MaxTTL is the longest lifetime S3-compatible backends accept.
+	MaxTTL = 7 * 24 * time.Hour
+
+	// maxPollInterval caps the delay between completion checks.
+	maxPollInterval = time.Minute
+	// maxWatchers caps the number of uploads watched at once, since each
+	// is watched until its URL expires, for up to MaxTTL.
+	maxWatchers = 1024
+)
+
+var (
+	// ErrUnsupported is returned when the storage backend cannot presign URLs.
+	ErrUnsupported = errors.New("storage backend does not support presigned URLs")
+	// ErrInvalidRequest is returned for malformed presign requests.
+	ErrInvalidRequest = errors.New("invalid presign request")
+	// ErrTooManyWatchers is returned when an upload asks for verification
+	// while maxWatchers uploads are already watched.
+	ErrTooManyWatchers = errors.New("too many presigned uploads being verified")
+)
+
+// Request describes a presigned URL to issue.
+type Request struct {
+	Namespace namespace.Namespace
+	FileName  string
+	// Method is MethodGet or MethodPut.
+	Method string
+	// VersionID selects the version to download; only valid for MethodGet.
+	VersionID string
+	// TTL is how long the URL is valid; zero means DefaultTTL.
+	TTL time.Duration
+	// VerifyCompletion watches for the uploaded object until the URL
+	// expires and records the outcome; only valid for MethodPut.
+	VerifyCompletion bool
+	// Remote is the address of the requesting client, for the audit trail.
+	Remote string
+}
+
+// URL is an issued presigned URL.
+type URL struct {
+	URL       string
+	Method    string
+	ExpiresAt time.Time
+}
+
+// Issuer issues presigned URLs.
+type Issuer struct {
+	// ctx bounds the lifetime of completion checks.
+	ctx           context.Context
+	storageClient storage.Client
+	audit         *audit.Logger
+	now           func() time.Time
+	// notify, when set, is told of the objects uploaded through presigned
+	// URLs.
+	notify func(ctx context.Context, bucket, key string)
+	// watchers is the number of uploads being watched.
+	watchers atomic.Int64
+}
+
+// NewIssuer returns an Issuer presigning URLs with storageClient. Completion
+// checks stop when ctx is done.
+func NewIssuer(ctx context.Context, storageClient storage.Client, audit *audit.Logger) *Issuer {
+	return &Issuer{
+		ctx:           ctx,
+		storageClient: storageClient,
+		audit:         audit,
+		now:           time.Now,
+	}
+}
+
+// NotifyUploads makes notify be called with every object uploaded through
+// a URL issued from now on, once it is found, so that, e.g., it is
+// replicated although it was not written through the server. Uploads are
+// then watched for whether or not they are verified, as long as fewer than
+// maxWatchers are; the others are not notified, and must be caught up
+// with otherwise, e.g. by replication reconciliation.
+func (i *Issuer) NotifyUploads(notify func(ctx context.Context, bucket, key string)) {
+	i.notify = notify
+}
+
+// Issue presigns a URL for req and records it in the audit trail.
+func (i *Issuer) Issue(ctx context.Context, req Request) (URL, error) {
+	presigner, ok := storage.AsPresigner(i.storageClient)
+	if !ok {
+		return URL{}, ErrUnsupported
+	}
+	ttl := req.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	if ttl < time.Second || ttl > MaxTTL {
+		return URL{}, fmt.Errorf("%w: expiry must be between 1s and %s", ErrInvalidRequest, MaxTTL)
+	}
+	issuedAt := i.now()
+	var (
+		u   fmt.Stringer
+		err error
+	)
+	switch req.Method {
+	case MethodGet:
+		if req.VerifyCompletion {
+			return URL{}, fmt.Errorf("%w: only uploads can be verified", ErrInvalidRequest)
+		}
+		if _, err := i.storageClient.GetObjectInfo(ctx, req.Namespace.Bucket, req.FileName, storage.GetObjectInfoOptions{
+			VersionID: req.VersionID,
+		}); err != nil {
+			return URL{}, err
+		}
+		u, err = presigner.PresignGetObject(ctx, req.Namespace.Bucket, req.FileName, ttl, storage.PresignGetObjectOptions{
+			VersionID: req.VersionID,
+		})
+	case MethodPut:
+		if req.VersionID != "" {
+			return URL{}, fmt.Errorf("%w: uploads cannot target a version", ErrInvalidRequest)
+		}
+		u, err = presigner.PresignPutObject(ctx, req.Namespace.Bucket, req.FileName, ttl)
+	default:
+		return URL{}, fmt.Errorf("%w: unsupported method %q", ErrInvalidRequest, req.Method)
+	}
+	if err != nil {
+		return URL{}, err
+	}
+	watch := req.VerifyCompletion || (req.Method == MethodPut && i.notify != nil)
+	if watch && i.watchers.Add(1) > maxWatchers {
+		i.watchers.Add(-1)
+		if req.VerifyCompletion {
+			return URL{}, ErrTooManyWatchers
+		}
+		watch = false
+	}
+	expiresAt := issuedAt.Add(ttl)
+	i.audit.Record(ctx, audit.Event{
+		Action:    "presign.issue",
+		Namespace: req.Namespace.Name,
+		FileName:  req.FileName,
+		Remote:    req.Remote,
+		Details: map[string]any{
+			"method":            req.Method,
+			"version_id":        req.VersionID,
+			"expires_at":        expiresAt,
+			"verify_completion": req.VerifyCompletion,
+		},
+	})
+	if watch {
+		var principal string
+		if identity, ok := auth.FromContext(ctx); ok {
+			principal = identity.Subject
+		}
+		go i.verifyUpload(req, principal, issuedAt, expiresAt)
+	}
+	return URL{URL: u.String(), Method: req.Method, ExpiresAt: expiresAt}, nil
+}
+
+// verifyUpload polls for the object uploaded through a presigned PUT URL,
+// backing off up to maxPollInterval, notifies it when it arrives and, if
+// req asks for verification, records whether it arrived before the URL
+// expired. Only the first upload is noticed: the URL can be used again
+// until it expires.
+func (i *Issuer) verifyUpload(req Request, principal string, issuedAt, expiresAt time.Time) {
+	defer i.watchers.Add(-1)
+	event := audit.Event{
+		Action:    "presign.complete",
+		Namespace: req.Namespace.Name,
+		FileName:  req.FileName,
+		Principal: principal,
+		Remote:    req.Remote,
+	}
+	interval := time.Second
+	for {
+		info, err := i.storageClient.GetObjectInfo(i.ctx, req.Namespace.Bucket, req.FileName, storage.GetObjectInfoOptions{})
+		// Object timestamps have second precision.
+		if err == nil && !info.LastModified.Before(issuedAt.Truncate(time.Second)) {
+			event.Details = map[string]any{
+				"size":       info.Size,
+				"etag":       info.ETag,
+				"version_id": info.VersionID,
+			}
+			if req.VerifyCompletion {
+				i.audit.Record(i.ctx, event)
+			}
+			if i.notify != nil {
+				i.notify(i.ctx, req.Namespace.Bucket, req.FileName)
+			}
+			return
+		}
+		if !i.now().Before(expiresAt) {
+			if req.VerifyCompletion {
+				event.Action = "presign.expire"
+				i.audit.Record(i.ctx, event)
+			}
+			return
+		}
+		select {
+		case <-i.ctx.Done():
+			return
+		case <-time.After(min(interval, expiresAt.Sub(i.now()))):
+		}
+		interval = min(interval*2, maxPollInterval)
+	}
+}
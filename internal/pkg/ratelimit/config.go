@@ -0,0 +1,81 @@
+// Package ratelimit enforces per-client request rates and bandwidth. Token
+// buckets are kept per identity, per client IP and per namespace; a request
+// must fit in all three, and transferred bytes are throttled by all three.
+// Limits are read from a file and applied live when it changes.
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/viper"
+)
+
+// Limit bounds one key. Zero values mean unlimited.
+type Limit struct {
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	// Burst is the number of requests allowed at once; it defaults to
+	// RequestsPerSecond rounded up.
+	Burst          int   `mapstructure:"burst"`
+	BytesPerSecond int64 `mapstructure:"bytes_per_second"`
+}
+
+// ScopeConfig holds the limits of one scope: a default applied to every key
+// and overrides for specific keys.
+type ScopeConfig struct {
+	Default   Limit            `mapstructure:"default"`
+	Overrides map[string]Limit `mapstructure:"overrides"`
+}
+
+// limit returns the limit applying to key.
+func (c ScopeConfig) limit(key string) Limit {
+	if limit, ok := c.Overrides[key]; ok {
+		return limit
+	}
+	return c.Default
+}
+
+// Config holds the limits of every scope.
+type Config struct {
+	// Identity limits apply per authenticated subject.
+	Identity ScopeConfig `mapstructure:"identity"`
+	// IP limits apply per client address.
+	IP ScopeConfig `mapstructure:"ip"`
+	// Namespace limits apply per namespace, across all clients.
+	Namespace ScopeConfig `mapstructure:"namespace"`
+}
+
+// Validate checks that no limit is negative.
+func (c *Config) Validate() error {
+	var errs []error
+	for name, scope := range map[string]ScopeConfig{"identity": c.Identity, "ip": c.IP, "namespace": c.Namespace} {
+		if err := scope.Default.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("%s default: %w", name, err))
+		}
+		for key, limit := range scope.Overrides {
+			if err := limit.validate(); err != nil {
+				errs = append(errs, fmt.Errorf("%s override %q: %w", name, key, err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l Limit) validate() error {
+	if l.RequestsPerSecond < 0 || l.Burst < 0 || l.BytesPerSecond < 0 {
+		return errors.New("limits must not be negative")
+	}
+	return nil
+}
+
+// decodeConfig unmarshals and validates the limits held by v.
+func decodeConfig(v *viper.Viper) (*Config, error) {
+	var cfg Config
+	if err := v.Unmarshal(&cfg); err != nil {
+		return nil, fmt.Errorf("rate limit config unmarshal error: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rate limit config: %w", err)
+	}
+	return &cfg, nil
+}
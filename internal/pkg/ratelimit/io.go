@@ -0,0 +1,54 @@
+package ratelimit
+
+import (
+	"context"
+	"io"
+)
+
+// Reader returns r throttled to the bandwidth limits of keys. Bytes are
+// accounted for as they are read, so a read is delayed until the bucket has
+// refilled from the previous one.
+func (l *Limiter) Reader(ctx context.Context, keys Keys, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &reader{ctx: ctx, limiter: l, keys: keys, r: r}
+}
+
+type reader struct {
+	ctx     context.Context
+	limiter *Limiter
+	keys    Keys
+	r       io.Reader
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	if waitErr := r.limiter.WaitBytes(r.ctx, r.keys, n); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	return n, err
+}
+
+// Writer returns w throttled to the bandwidth limits of keys. Each write is
+// delayed until its bytes fit in the buckets.
+func (l *Limiter) Writer(ctx context.Context, keys Keys, w io.Writer) io.Writer {
+	if l == nil {
+		return w
+	}
+	return &writer{ctx: ctx, limiter: l, keys: keys, w: w}
+}
+
+type writer struct {
+	ctx     context.Context
+	limiter *Limiter
+	keys    Keys
+	w       io.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if err := w.limiter.WaitBytes(w.ctx, w.keys, len(p)); err != nil {
+		return 0, err
+	}
+	return w.w.Write(p)
+}
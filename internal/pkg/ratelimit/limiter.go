@@ -0,0 +1,251 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"golang.org/x/time/rate"
+)
+
+// Scopes limits are kept for.
+const (
+	ScopeIdentity  = "identity"
+	ScopeIP        = "ip"
+	ScopeNamespace = "namespace"
+)
+
+// idleTimeout is how long buckets of keys that are no longer seen are kept.
+const idleTimeout = 10 * time.Minute
+
+// Keys identifies the client and namespace of a request. Empty keys are not
+// limited.
+type Keys struct {
+	Identity  string
+	IP        string
+	Namespace string
+}
+
+// bucket holds the token buckets of one key.
+type bucket struct {
+	requests *rate.Limiter
+	bytes    *rate.Limiter
+	lastUsed time.Time
+}
+
+// Limiter enforces the configured limits. A nil Limiter limits nothing.
+type Limiter struct {
+	mu        sync.Mutex
+	config    *Config
+	buckets   map[string]*bucket
+	lastPrune time.Time
+}
+
+// New returns a Limiter enforcing config.
+func New(config *Config) *Limiter {
+	return &Limiter{config: config, buckets: make(map[string]*bucket), lastPrune: time.Now()}
+}
+
+// Load reads the limits from a YAML, JSON or TOML file and returns a Limiter
+// enforcing them. The file is watched and changes apply to existing clients
+// immediately; a changed file that fails to parse is ignored.
+func Load(path string) (*Limiter, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("reading rate limit config: %w", err)
+	}
+	config, err := decodeConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	l := New(config)
+	v.OnConfigChange(func(fsnotify.Event) {
+		config, err := decodeConfig(v)
+		if err != nil {
+			slog.Error("keeping previous rate limits", "error", err)
+			return
+		}
+		l.SetConfig(config)
+		slog.Info("rate limits reloaded", "path", path)
+	})
+	v.WatchConfig()
+	return l, nil
+}
+
+// SetConfig replaces the limits, applying them to the buckets of clients
+// already seen, including transfers in progress.
+func (l *Limiter) SetConfig(config *Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.config = config
+	for key, b := range l.buckets {
+		scope, name := splitKey(key)
+		limit := l.limit(scope, name)
+		setLimit(b.requests, requestLimit(limit))
+		setLimit(b.bytes, byteLimit(limit))
+	}
+}
+
+// Allow takes one request token from the buckets of every key. When any of
+// them is empty no token is taken, and the time after which the request may
+// be retried is returned with false.
+func (l *Limiter) Allow(keys Keys) (time.Duration, bool) {
+	if l == nil {
+		return 0, true
+	}
+	now := time.Now()
+	var reservations []*rate.Reservation
+	var wait time.Duration
+	for _, b := range l.lookup(keys) {
+		r := b.requests.ReserveN(now, 1)
+		if !r.OK() {
+			// The burst is zero: requests are blocked entirely.
+			wait = max(wait, time.Second)
+			continue
+		}
+		reservations = append(reservations, r)
+		wait = max(wait, r.DelayFrom(now))
+	}
+	if wait == 0 {
+		return 0, true
+	}
+	for _, r := range reservations {
+		r.CancelAt(now)
+	}
+	return wait, false
+}
+
+// WaitBytes blocks until n bytes may be transferred for keys, or ctx is done.
+func (l *Limiter) WaitBytes(ctx context.Context, keys Keys, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+	for _, b := range l.lookup(keys) {
+		if err := waitN(ctx, b.bytes, n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitN waits for n tokens from limiter, in steps no larger than its burst.
+func waitN(ctx context.Context, limiter *rate.Limiter, n int) error {
+	for n > 0 {
+		step := n
+		if limiter.Limit() != rate.Inf {
+			step = min(n, max(limiter.Burst(), 1))
+		}
+		if err := limiter.WaitN(ctx, step); err != nil {
+			return err
+		}
+		n -= step
+	}
+	return nil
+}
+
+// lookup returns the buckets of every non-empty key, creating them on first
+// use.
+func (l *Limiter) lookup(keys Keys) []*bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	if now.Sub(l.lastPrune) > idleTimeout {
+		l.prune(now)
+	}
+	var buckets []*bucket
+	for _, scoped := range [][2]string{
+		{ScopeIdentity, keys.Identity},
+		{ScopeIP, keys.IP},
+		{ScopeNamespace, keys.Namespace},
+	} {
+		scope, name := scoped[0], scoped[1]
+		if name == "" {
+			continue
+		}
+		key := scope + "/" + name
+		b, ok := l.buckets[key]
+		if !ok {
+			limit := l.limit(scope, name)
+			b = &bucket{
+				requests: newLimiter(requestLimit(limit)),
+				bytes:    newLimiter(byteLimit(limit)),
+			}
+			l.buckets[key] = b
+		}
+		b.lastUsed = now
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+// prune drops the buckets of keys not seen for idleTimeout. A client coming
+// back starts with full buckets.
+func (l *Limiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if now.Sub(b.lastUsed) > idleTimeout {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastPrune = now
+}
+
+// limit returns the configured limit of name in scope.
+func (l *Limiter) limit(scope, name string) Limit {
+	switch scope {
+	case ScopeIdentity:
+		return l.config.Identity.limit(name)
+	case ScopeIP:
+		return l.config.IP.limit(name)
+	default:
+		return l.config.Namespace.limit(name)
+	}
+}
+
+// splitKey splits a bucket key into its scope and name.
+func splitKey(key string) (string, string) {
+	scope, name, _ := strings.Cut(key, "/")
+	return scope, name
+}
+
+// tokens is a token bucket rate and size.
+type tokens struct {
+	rate  rate.Limit
+	burst int
+}
+
+// requestLimit returns the request bucket of limit.
+func requestLimit(limit Limit) tokens {
+	if limit.RequestsPerSecond == 0 {
+		return tokens{rate: rate.Inf}
+	}
+	burst := limit.Burst
+	if burst == 0 {
+		burst = int(math.Ceil(limit.RequestsPerSecond))
+	}
+	return tokens{rate: rate.Limit(limit.RequestsPerSecond), burst: burst}
+}
+
+// byteLimit returns the bandwidth bucket of limit, which holds one second
+// worth of bytes.
+func byteLimit(limit Limit) tokens {
+	if limit.BytesPerSecond == 0 {
+		return tokens{rate: rate.Inf}
+	}
+	return tokens{rate: rate.Limit(limit.BytesPerSecond), burst: int(min(limit.BytesPerSecond, math.MaxInt32))}
+}
+
+func newLimiter(t tokens) *rate.Limiter {
+	return rate.NewLimiter(t.rate, t.burst)
+}
+
+func setLimit(limiter *rate.Limiter, t tokens) {
+	limiter.SetLimit(t.rate)
+	limiter.SetBurst(t.burst)
+}
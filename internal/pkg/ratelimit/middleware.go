@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+)
+
+// errLimited is the message of rejected requests.
+const errLimited = "rate limit exceeded"
+
+type clientIPKey struct{}
+
+// WithClientIP returns a copy of ctx carrying the client's IP address.
+func WithClientIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, clientIPKey{}, ip)
+}
+
+// KeysFor returns the keys limiting a request in ctx to the namespace ns.
+// Anonymous requests are limited by IP address only, so that unrelated
+// anonymous clients do not share a bucket.
+func KeysFor(ctx context.Context, ns string) Keys {
+	keys := Keys{Namespace: ns}
+	keys.IP, _ = ctx.Value(clientIPKey{}).(string)
+	if identity, ok := auth.FromContext(ctx); ok && identity.Method != auth.MethodAnonymous {
+		keys.Identity = identity.Subject
+	}
+	return keys
+}
+
+// retryAfter formats wait as a Retry-After value in whole seconds.
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(wait.Seconds())))
+}
+
+// Middleware rejects requests over their request rate with 429 Too Many
+// Requests and a Retry-After header, and stores the client IP in the context
+// of the others. It must run after authentication. namespace returns the
+// name of the namespace addressed by the "namespace" query parameter; when
+// nil, requests are not limited per namespace.
+func Middleware(l *Limiter, namespace func(name string) string, next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := WithClientIP(r.Context(), hostIP(r.RemoteAddr))
+		var ns string
+		if namespace != nil {
+			ns = namespace(r.URL.Query().Get("namespace"))
+		}
+		if wait, ok := l.Allow(KeysFor(ctx, ns)); !ok {
+			w.Header().Set("Retry-After", retryAfter(wait))
+			http.Error(w, errLimited, http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// hostIP returns the IP address of a host:port address.
+func hostIP(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+// namespaced is implemented by request messages addressing a namespace.
+type namespaced interface {
+	GetNamespace() string
+}
+
+// Interceptor rejects RPCs over their request rate with ResourceExhausted
+// and a Retry-After header, and stores the client IP in the handler's
+// context. It must run after authentication. Streaming RPCs are checked
+// against their namespace when their first message arrives.
+type Interceptor struct {
+	limiter   *Limiter
+	namespace func(name string) string
+}
+
+// Compile-time check to ensure Interceptor implements connect.Interceptor.
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor returns an Interceptor enforcing the limits of l. namespace
+// returns the name of the namespace a request message addresses.
+func NewInterceptor(l *Limiter, namespace func(name string) string) *Interceptor {
+	return &Interceptor{limiter: l, namespace: namespace}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		ctx = WithClientIP(ctx, hostIP(req.Peer().Addr))
+		var ns string
+		if msg, ok := req.Any().(namespaced); ok {
+			ns = i.namespace(msg.GetNamespace())
+		}
+		if err := i.allow(KeysFor(ctx, ns)); err != nil {
+			return nil, err
+		}
+		return next(ctx, req)
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx = WithClientIP(ctx, hostIP(conn.Peer().Addr))
+		if err := i.allow(KeysFor(ctx, "")); err != nil {
+			return err
+		}
+		return next(ctx, &streamingConn{StreamingHandlerConn: conn, interceptor: i})
+	}
+}
+
+// allow returns a ResourceExhausted error when keys are over their rate.
+func (i *Interceptor) allow(keys Keys) error {
+	wait, ok := i.limiter.Allow(keys)
+	if ok {
+		return nil
+	}
+	err := connect.NewError(connect.CodeResourceExhausted, fmt.Errorf("%s, retry after %s", errLimited, wait.Round(time.Millisecond)))
+	err.Meta().Set("Retry-After", retryAfter(wait))
+	return err
+}
+
+// streamingConn checks the namespace of the first message received.
+type streamingConn struct {
+	connect.StreamingHandlerConn
+	interceptor *Interceptor
+	checked     bool
+}
+
+func (c *streamingConn) Receive(msg any) error {
+	if err := c.StreamingHandlerConn.Receive(msg); err != nil {
+		return err
+	}
+	if c.checked {
+		return nil
+	}
+	c.checked = true
+	m, ok := msg.(namespaced)
+	if !ok {
+		return nil
+	}
+	return c.interceptor.allow(Keys{Namespace: c.interceptor.namespace(m.GetNamespace())})
+}
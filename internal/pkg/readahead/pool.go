@@ -0,0 +1,50 @@
+package readahead
+
+import "sync"
+
+// pool hands out the fixed-size buffers chunks are prefetched into, never
+// more than max at once, so that read-ahead is bounded in memory however
+// many downloads run concurrently.
+type pool struct {
+	size int64
+	max  int
+
+	mu    sync.Mutex
+	free  [][]byte
+	inUse int
+}
+
+func newPool(size int64, max int) *pool {
+	return &pool{size: size, max: max}
+}
+
+// get returns a buffer, or nil when every buffer is in use.
+func (p *pool) get() []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.inUse >= p.max {
+		return nil
+	}
+	p.inUse++
+	if n := len(p.free); n > 0 {
+		buf := p.free[n-1]
+		p.free = p.free[:n-1]
+		return buf
+	}
+	return make([]byte, p.size)
+}
+
+// put returns a buffer obtained from get.
+func (p *pool) put(buf []byte) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inUse--
+	p.free = append(p.free, buf)
+}
+
+// bytes returns the size of the buffers in use.
+func (p *pool) bytes() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return int64(p.inUse) * p.size
+}
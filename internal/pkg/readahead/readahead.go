@@ -0,0 +1,322 @@
+// Package readahead pipelines sequential downloads: instead of streaming a
+// byte range with one request, read synchronously and so bounded by the
+// latency of the backend, it reads the range in chunks fetched
+// concurrently, ahead of the consumer, into a bounded pool of buffers.
+//
+// A read starts with a single chunk in flight and doubles the number of
+// chunks fetched ahead each time the consumer finishes one, up to the
+// configured depth, so that short reads do not fetch more than they use
+// while long sequential reads quickly reach full depth.
+package readahead
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// Options configures read-ahead.
+type Options struct {
+	// ChunkSize is the size of the chunks ranges are fetched in.
+	ChunkSize int64
+	// Depth caps the number of chunks fetched ahead of a reader.
+	Depth int
+	// MaxMemory caps the memory of the buffers shared by every reader.
+	MaxMemory int64
+}
+
+// readaheadClient serves the plain byte ranges pinned to an ETag with
+// read-ahead. Other reads are passed through to the wrapped client.
+type readaheadClient struct {
+	storage.Client
+	opts Options
+	pool *pool
+}
+
+// NewStorageClient wraps client so that ranged reads longer than a chunk
+// are fetched ahead of the consumer, as configured by opts.
+func NewStorageClient(client storage.Client, opts Options) (storage.Client, error) {
+	if opts.ChunkSize <= 0 || opts.Depth <= 0 {
+		return nil, errors.New("readahead: chunk size and depth must be positive")
+	}
+	buffers := int(opts.MaxMemory / opts.ChunkSize)
+	if buffers < 1 {
+		return nil, errors.New("readahead: memory limit is smaller than a chunk")
+	}
+	pool := newPool(opts.ChunkSize, buffers)
+	metrics.SetReadaheadBuffers(pool.bytes)
+	return &readaheadClient{Client: client, opts: opts, pool: pool}, nil
+}
+
+// Unwrap returns the wrapped client.
+func (c *readaheadClient) Unwrap() storage.Client {
+	return c.Client
+}
+
+// GetObject reads the byte range selected by opts ahead of the consumer
+// when it spans more than a chunk. The range must be pinned to an ETag, so
+// that every chunk comes from the same version of the object.
+func (c *readaheadClient) GetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectOptions,
+) (io.ReadCloser, error) {
+	if opts.Start < 0 || opts.End < opts.Start || opts.MatchETag == "" ||
+		opts.End-opts.Start+1 <= c.opts.ChunkSize {
+		return c.Client.GetObject(ctx, bucketName, objectName, opts)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	r := &reader{
+		ctx:        ctx,
+		cancel:     cancel,
+		client:     c,
+		bucketName: bucketName,
+		objectName: objectName,
+		opts:       opts,
+		next:       opts.Start,
+		window:     1,
+	}
+	// Wait for the first chunk, so that a missing or modified object fails
+	// the call rather than the first read.
+	r.schedule()
+	if err := r.wait(); err != nil {
+		r.Close()
+		return nil, err
+	}
+	metrics.RecordReadahead()
+	return r, nil
+}
+
+var errClosed = errors.New("readahead: read after close")
+
+// chunk is a part of the range, fetched into buf or, when the pool was
+// exhausted, read straight from body.
+type chunk struct {
+	start, end int64
+	buf        []byte
+	// n is the number of bytes fetched into buf.
+	n    int
+	body io.ReadCloser
+	err  error
+	// last reports that the chunk starts past the end of the object.
+	last bool
+	done chan struct{}
+	// fetchTime is how long the chunk took to fetch.
+	fetchTime time.Duration
+}
+
+// reader reads a byte range chunk by chunk, fetching chunks ahead.
+type reader struct {
+	ctx        context.Context
+	cancel     context.CancelFunc
+	client     *readaheadClient
+	bucketName string
+	objectName string
+	opts       storage.GetObjectOptions
+	// next is the first byte of the next chunk to fetch.
+	next int64
+	// window is how many chunks may be in flight, up to the depth.
+	window int
+	// pending holds the chunks in flight or fetched, in order; the first
+	// one is being read from off, also counted for chunks read unbuffered.
+	pending []*chunk
+	off     int
+	// waited reports whether the first pending chunk was waited for.
+	waited bool
+	err    error
+}
+
+// schedule fetches chunks until the window is full.
+func (r *reader) schedule() {
+	for len(r.pending) < r.window && r.next <= r.opts.End {
+		if n := len(r.pending); n > 0 && r.pending[n-1].last {
+			return
+		}
+		buf := r.client.pool.get()
+		if buf == nil && len(r.pending) > 0 {
+			// Fetch no further ahead until buffers are returned.
+			return
+		}
+		c := &chunk{
+			start: r.next,
+			end:   min(r.next+r.client.opts.ChunkSize, r.opts.End+1) - 1,
+			buf:   buf,
+			done:  make(chan struct{}),
+		}
+		r.next = c.end + 1
+		r.pending = append(r.pending, c)
+		if buf == nil {
+			metrics.RecordReadaheadChunk(false)
+			c.body, c.err = r.client.Client.GetObject(r.ctx, r.bucketName, r.objectName, r.chunkOptions(c))
+			r.pastEnd(c)
+			close(c.done)
+			continue
+		}
+		metrics.RecordReadaheadChunk(true)
+		go r.fetch(c)
+	}
+}
+
+func (r *reader) chunkOptions(c *chunk) storage.GetObjectOptions {
+	return storage.GetObjectOptions{
+		Start:     c.start,
+		End:       c.end,
+		VersionID: r.opts.VersionID,
+		MatchETag: r.opts.MatchETag,
+	}
+}
+
+// fetch reads a chunk into its buffer.
+func (r *reader) fetch(c *chunk) {
+	defer close(c.done)
+	began := time.Now()
+	defer func() { c.fetchTime = time.Since(began) }()
+	body, err := r.client.Client.GetObject(r.ctx, r.bucketName, r.objectName, r.chunkOptions(c))
+	if err != nil {
+		c.err = err
+		r.pastEnd(c)
+		return
+	}
+	defer body.Close()
+	c.n, err = io.ReadFull(body, c.buf[:c.end-c.start+1])
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		c.err = c.truncated(int64(c.n))
+		return
+	}
+	if err != nil {
+		c.err = storage.Translate("reading object", err)
+	}
+}
+
+// truncated returns the error of a chunk that ended after n bytes. The
+// range was clamped to the object, which is pinned by its ETag: a short
+// chunk is a truncated response, not the end of the object.
+func (c *chunk) truncated(n int64) error {
+	return &storage.Error{
+		Kind: storage.ErrUnavailable,
+		Op:   "reading object",
+		Err:  fmt.Errorf("chunk %d-%d ended after %d bytes", c.start, c.end, n),
+	}
+}
+
+// pastEnd turns the error of a chunk starting past the end of the object
+// into the end of the range.
+func (r *reader) pastEnd(c *chunk) {
+	if c.start > r.opts.Start && errors.Is(c.err, storage.ErrInvalidRange) {
+		c.err = nil
+		c.last = true
+	}
+}
+
+// wait waits for the first pending chunk to be fetched.
+func (r *reader) wait() error {
+	c := r.pending[0]
+	began := time.Now()
+	select {
+	case <-c.done:
+	case <-r.ctx.Done():
+		return r.ctx.Err()
+	}
+	if !r.waited {
+		r.waited = true
+		if c.buf != nil {
+			metrics.RecordReadaheadWait(time.Since(began), c.fetchTime)
+		}
+	}
+	return c.err
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	for len(r.pending) > 0 {
+		if err := r.wait(); err != nil {
+			r.err = err
+			return 0, err
+		}
+		c := r.pending[0]
+		var n int
+		var err error
+		if c.body != nil {
+			n, err = c.body.Read(p)
+			r.off += n
+			switch {
+			case errors.Is(err, io.EOF) && int64(r.off) < c.end-c.start+1:
+				err = c.truncated(int64(r.off))
+				r.err = err
+			case errors.Is(err, io.EOF):
+				err = nil
+				if n == 0 {
+					r.advance()
+					continue
+				}
+			case err != nil:
+				err = storage.Translate("reading object", err)
+				r.err = err
+			}
+		} else {
+			n = copy(p, c.buf[r.off:c.n])
+			r.off += n
+			if r.off == c.n {
+				r.advance()
+			}
+		}
+		if n > 0 || err != nil {
+			metrics.RecordReadaheadBytes(n)
+			return n, err
+		}
+	}
+	return 0, io.EOF
+}
+
+// advance moves on to the next chunk, widening the window as the range is
+// read sequentially.
+func (r *reader) advance() {
+	c := r.pending[0]
+	r.release(c)
+	r.pending = r.pending[1:]
+	r.off = 0
+	r.waited = false
+	if c.last {
+		r.drop()
+		return
+	}
+	r.window = min(r.window*2, r.client.opts.Depth)
+	r.schedule()
+}
+
+// release returns the resources of a chunk that is done.
+func (r *reader) release(c *chunk) {
+	if c.body != nil {
+		c.body.Close()
+	}
+	if c.buf != nil {
+		r.client.pool.put(c.buf)
+		c.buf = nil
+	}
+}
+
+// drop cancels and releases the pending chunks.
+func (r *reader) drop() {
+	r.cancel()
+	for _, c := range r.pending {
+		<-c.done
+		r.release(c)
+	}
+	r.pending = nil
+}
+
+func (r *reader) Close() error {
+	r.drop()
+	if r.err == nil {
+		r.err = errClosed
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package replication
+
+import (
+	"context"
+	"io"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// replicatingClient enqueues the objects written or removed through it for
+// replication.
+type replicatingClient struct {
+	storage.Client
+	replicator *Replicator
+}
+
+// NewStorageClient wraps client so that every successful write or delete
+// in a replicated bucket is replicated by replicator.
+func NewStorageClient(client storage.Client, replicator *Replicator) storage.Client {
+	return &replicatingClient{Client: client, replicator: replicator}
+}
+
+// Unwrap returns the wrapped client.
+func (c *replicatingClient) Unwrap() storage.Client {
+	return c.Client
+}
+
+func (c *replicatingClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	size int64,
+	opts storage.PutObjectOptions,
+) (storage.ObjectInfo, error) {
+	info, err := c.Client.PutObject(ctx, bucketName, objectName, reader, size, opts)
+	if err == nil {
+		c.replicator.Enqueue(ctx, bucketName, objectName)
+	}
+	return info, err
+}
+
+func (c *replicatingClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts storage.CopyObjectOptions,
+) (storage.ObjectInfo, error) {
+	info, err := c.Client.CopyObject(ctx, bucketName, srcObjectName, dstObjectName, opts)
+	if err == nil {
+		c.replicator.Enqueue(ctx, bucketName, dstObjectName)
+	}
+	return info, err
+}
+
+func (c *replicatingClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.RemoveObjectOptions,
+) error {
+	err := c.Client.RemoveObject(ctx, bucketName, objectName, opts)
+	if err == nil {
+		c.replicator.Enqueue(ctx, bucketName, objectName)
+	}
+	return err
+}
@@ -0,0 +1,236 @@
+package replication
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Task asks for one object of the target to be made identical to the
+// source: copied if it exists there, removed otherwise.
+type Task struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	// EnqueuedAt is when the object first diverged; re-enqueuing a pending
+	// object keeps it, so that the lag is not hidden by frequent writes.
+	EnqueuedAt time.Time `json:"enqueued_at"`
+	// Attempts counts the failed attempts so far.
+	Attempts int `json:"attempts"`
+	// NextAttempt is when the task may be attempted again after a failure.
+	NextAttempt time.Time `json:"next_attempt"`
+	// Seq changes every time the task is enqueued, so that a task enqueued
+	// again while being replicated is not dropped once the earlier attempt
+	// completes.
+	Seq uint64 `json:"seq"`
+}
+
+func (t *Task) id() string {
+	sum := sha256.Sum256([]byte(t.Bucket + "/" + t.Key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Queue is the persistent replication queue: one JSON file per pending
+// object in its directory, so that writes still to replicate survive a
+// restart. Enqueuing an object already pending only updates its task.
+type Queue struct {
+	dir string
+
+	mu       sync.Mutex
+	tasks    map[string]*Task
+	inflight map[string]bool
+	seq      uint64
+	// wake is signaled when a task may have become due.
+	wake chan struct{}
+}
+
+// OpenQueue opens the queue stored in dir, creating dir if needed, and
+// loads the tasks left pending by a previous run.
+func OpenQueue(dir string) (*Queue, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("replication queue: %w", err)
+	}
+	q := &Queue{
+		dir:      dir,
+		tasks:    make(map[string]*Task),
+		inflight: make(map[string]bool),
+		wake:     make(chan struct{}, 1),
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("replication queue: %w", err)
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		raw, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("replication queue: %w", err)
+		}
+		var task Task
+		if err := json.Unmarshal(raw, &task); err != nil {
+			return nil, fmt.Errorf("replication queue: %s: %w", name, err)
+		}
+		q.tasks[task.id()] = &task
+		q.seq = max(q.seq, task.Seq)
+	}
+	return q, nil
+}
+
+// Add enqueues the object, persisting the task before returning.
+func (q *Queue) Add(bucket, key string, now time.Time) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.seq++
+	task := &Task{Bucket: bucket, Key: key, EnqueuedAt: now, Seq: q.seq}
+	if pending, ok := q.tasks[task.id()]; ok {
+		task.EnqueuedAt = pending.EnqueuedAt
+	}
+	if err := q.persist(task); err != nil {
+		return err
+	}
+	q.tasks[task.id()] = task
+	q.signal()
+	return nil
+}
+
+// persist writes the task's file. The caller holds q.mu.
+func (q *Queue) persist(task *Task) error {
+	raw, err := json.Marshal(task)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(q.dir, task.id()+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("replication queue: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replication queue: %w", err)
+	}
+	return nil
+}
+
+// Take waits for a task that is due and not being replicated by another
+// worker, and marks it as being replicated. It returns false once ctx is
+// done.
+func (q *Queue) Take(ctx context.Context) (Task, bool) {
+	for {
+		task, wait := q.next(time.Now())
+		if task != nil {
+			return *task, true
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return Task{}, false
+		case <-q.wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+// next returns the oldest due task, or how long to wait for one.
+func (q *Queue) next(now time.Time) (*Task, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var due *Task
+	var ready int
+	wait := time.Minute
+	for id, task := range q.tasks {
+		if q.inflight[id] {
+			continue
+		}
+		if delay := task.NextAttempt.Sub(now); delay > 0 {
+			wait = min(wait, delay)
+			continue
+		}
+		ready++
+		if due == nil || task.EnqueuedAt.Before(due.EnqueuedAt) {
+			due = task
+		}
+	}
+	if due != nil {
+		q.inflight[due.id()] = true
+	}
+	if ready > 1 {
+		// Wake another worker for the next one.
+		q.signal()
+	}
+	return due, wait
+}
+
+// Done removes a replicated task, unless it was enqueued again meanwhile.
+func (q *Queue) Done(task Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	id := task.id()
+	delete(q.inflight, id)
+	if current, ok := q.tasks[id]; !ok || current.Seq != task.Seq {
+		q.signal()
+		return nil
+	}
+	delete(q.tasks, id)
+	if err := os.Remove(filepath.Join(q.dir, id+".json")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("replication queue: %w", err)
+	}
+	return nil
+}
+
+// Retry schedules a failed task for another attempt after delay. A task
+// enqueued again meanwhile is retried right away instead.
+func (q *Queue) Retry(task Task, delay time.Duration) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	id := task.id()
+	delete(q.inflight, id)
+	current, ok := q.tasks[id]
+	if !ok {
+		return nil
+	}
+	current.Attempts++
+	if current.Seq == task.Seq {
+		current.NextAttempt = time.Now().Add(delay)
+	}
+	q.signal()
+	return q.persist(current)
+}
+
+// signal wakes a waiting worker. The caller holds q.mu.
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Len returns the number of pending tasks.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.tasks)
+}
+
+// Lag returns how long the oldest pending task has been waiting, or zero
+// when the queue is empty.
+func (q *Queue) Lag(now time.Time) time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var lag time.Duration
+	for _, task := range q.tasks {
+		lag = max(lag, now.Sub(task.EnqueuedAt))
+	}
+	return lag
+}
@@ -0,0 +1,98 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// Reconcile compares the listings of every replicated bucket in source and
+// target and enqueues the objects that differ: missing from either side,
+// of another size, or whose copy records another source ETag. It returns
+// the number of objects enqueued.
+func (r *Replicator) Reconcile(ctx context.Context) (int, error) {
+	buckets := make([]string, 0, len(r.buckets))
+	for bucket := range r.buckets {
+		buckets = append(buckets, bucket)
+	}
+	slices.Sort(buckets)
+	var divergent int
+	for _, bucket := range buckets {
+		keys, err := r.divergent(ctx, bucket)
+		if err != nil {
+			return divergent, fmt.Errorf("reconciling bucket %q: %w", bucket, err)
+		}
+		for _, key := range keys {
+			if err := r.queue.Add(bucket, key, time.Now()); err != nil {
+				return divergent, err
+			}
+			divergent++
+		}
+	}
+	metrics.RecordReplicationDivergent(divergent)
+	return divergent, nil
+}
+
+// divergent returns the keys of the bucket whose copy differs from the
+// source.
+func (r *Replicator) divergent(ctx context.Context, bucket string) ([]string, error) {
+	sources, err := r.source.ListObjects(ctx, bucket, storage.ListObjectsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	copies, err := r.target.ListObjects(ctx, bucket, storage.ListObjectsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	replicated := make(map[string]storage.ObjectInfo, len(copies))
+	for _, info := range copies {
+		replicated[info.Key] = info
+	}
+	var keys []string
+	for _, source := range sources {
+		replica, ok := replicated[source.Key]
+		delete(replicated, source.Key)
+		if ok && replica.Size == source.Size {
+			// Listings do not reliably carry metadata; ask for the copy's.
+			replica, err = r.target.GetObjectInfo(ctx, bucket, source.Key, storage.GetObjectInfoOptions{})
+			if err != nil && !storage.IsNotFound(err) {
+				return nil, err
+			}
+			if err == nil && replica.Metadata[SourceETagKey] == source.ETag {
+				continue
+			}
+		}
+		keys = append(keys, source.Key)
+	}
+	for key := range replicated {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// RunReconciler reconciles the replicated buckets every interval until ctx
+// is done.
+func (r *Replicator) RunReconciler(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			divergent, err := r.Reconcile(ctx)
+			if err != nil {
+				slog.Error("replication reconciliation failed", "error", err)
+			}
+			if divergent > 0 {
+				slog.Info("enqueued divergent replicas", "count", divergent)
+			}
+		}
+	}
+}
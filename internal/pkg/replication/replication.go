@@ -0,0 +1,174 @@
+// Package replication mirrors the objects of some namespaces to a second
+// storage backend. Every successful write or delete enqueues the object in
+// a persistent queue; workers then make the target's copy identical to the
+// source, retrying with backoff until they succeed, and a periodic
+// reconciliation compares both listings to repair whatever was missed.
+package replication
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// SourceETagKey is the metadata key under which a replicated object records
+// the ETag of its source, so that copies are compared across backends that
+// compute ETags differently.
+const SourceETagKey = "Fs-Source-Etag"
+
+// Options configures a Replicator.
+type Options struct {
+	// Workers is how many objects are replicated concurrently.
+	Workers int
+	// MaxRetryDelay caps the backoff between attempts to replicate an
+	// object, which starts at a second and doubles with every failure.
+	MaxRetryDelay time.Duration
+}
+
+// Replicator replicates the objects of some buckets of source to target.
+type Replicator struct {
+	source  storage.Client
+	target  storage.Client
+	queue   *Queue
+	buckets map[string]bool
+	opts    Options
+	logger  *slog.Logger
+}
+
+// New returns a Replicator of the given buckets, whose pending objects are
+// kept in queue.
+func New(source, target storage.Client, queue *Queue, buckets []string, opts Options) *Replicator {
+	r := &Replicator{
+		source:  source,
+		target:  target,
+		queue:   queue,
+		buckets: make(map[string]bool, len(buckets)),
+		opts:    opts,
+		logger:  slog.Default().With("component", "replication"),
+	}
+	for _, bucket := range buckets {
+		r.buckets[bucket] = true
+	}
+	metrics.SetReplicationQueue(queue.Len, func() time.Duration { return queue.Lag(time.Now()) })
+	return r
+}
+
+// Enqueue schedules the object for replication. Failing to enqueue it is
+// logged rather than returned: the write it follows did succeed, and the
+// next reconciliation will find the divergence.
+func (r *Replicator) Enqueue(ctx context.Context, bucket, key string) {
+	if !r.buckets[bucket] {
+		return
+	}
+	if err := r.queue.Add(bucket, key, time.Now()); err != nil {
+		metrics.RecordReplicationEnqueueError()
+		r.logger.ErrorContext(ctx, "enqueuing object for replication", "bucket", bucket, "key", key, "error", err)
+	}
+}
+
+// Run replicates the pending objects until ctx is done.
+func (r *Replicator) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range max(r.opts.Workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.work(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (r *Replicator) work(ctx context.Context) {
+	for {
+		task, ok := r.queue.Take(ctx)
+		if !ok {
+			return
+		}
+		if err := r.replicate(ctx, task); err != nil {
+			metrics.RecordReplicationFailure()
+			delay := r.backoff(task.Attempts + 1)
+			if ctx.Err() == nil {
+				r.logger.Warn("replicating object failed",
+					"bucket", task.Bucket,
+					"key", task.Key,
+					"attempt", task.Attempts+1,
+					"retry_in", delay,
+					"error", err,
+				)
+			}
+			err = r.queue.Retry(task, delay)
+			if err != nil {
+				r.logger.Error("rescheduling replication", "bucket", task.Bucket, "key", task.Key, "error", err)
+			}
+			continue
+		}
+		if err := r.queue.Done(task); err != nil {
+			r.logger.Error("dequeuing replicated object", "bucket", task.Bucket, "key", task.Key, "error", err)
+		}
+	}
+}
+
+// replicate makes the target's copy of the object identical to the source:
+// it is copied unless already up to date, or removed when the source no
+// longer has it.
+func (r *Replicator) replicate(ctx context.Context, task Task) error {
+	info, err := r.source.GetObjectInfo(ctx, task.Bucket, task.Key, storage.GetObjectInfoOptions{})
+	if storage.IsNotFound(err) {
+		if err := r.target.RemoveObject(ctx, task.Bucket, task.Key, storage.RemoveObjectOptions{}); err != nil {
+			return err
+		}
+		metrics.RecordReplication("removed")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	current, err := r.target.GetObjectInfo(ctx, task.Bucket, task.Key, storage.GetObjectInfoOptions{})
+	if err == nil && current.Size == info.Size && current.Metadata[SourceETagKey] == info.ETag {
+		return nil
+	}
+	if err != nil && !storage.IsNotFound(err) {
+		return err
+	}
+	body, err := r.source.GetObject(ctx, task.Bucket, task.Key, storage.GetObjectOptions{
+		Start:     -1,
+		End:       -1,
+		MatchETag: info.ETag,
+	})
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	metadata := maps.Clone(info.Metadata)
+	if metadata == nil {
+		metadata = make(map[string]string)
+	}
+	metadata[SourceETagKey] = info.ETag
+	if _, err := r.target.PutObject(ctx, task.Bucket, task.Key, body, info.Size, storage.PutObjectOptions{
+		ContentType: info.ContentType,
+		Metadata:    metadata,
+		Tags:        info.Tags,
+	}); err != nil {
+		return err
+	}
+	metrics.RecordReplication("copied")
+	return nil
+}
+
+// backoff returns the delay before the attempt following the given number
+// of failed ones: half of the exponential delay plus a random part of the
+// other half.
+func (r *Replicator) backoff(attempts int) time.Duration {
+	delay := time.Second << min(attempts-1, 30)
+	if r.opts.MaxRetryDelay > 0 && delay > r.opts.MaxRetryDelay {
+		delay = r.opts.MaxRetryDelay
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
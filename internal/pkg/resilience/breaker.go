@@ -0,0 +1,103 @@
+package resilience
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// ErrCircuitOpen is the cause of the ErrUnavailable errors returned without
+// calling the backend while its circuit breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+type breakerState int
+
+const (
+	// closed lets every call through.
+	closed breakerState = iota
+	// open rejects every call until the cooldown has passed.
+	open
+	// halfOpen lets a single probing call through; its outcome closes or
+	// reopens the breaker.
+	halfOpen
+)
+
+// breaker is the circuit breaker of one backend. Only failures meaning the
+// backend is in trouble, those that are retryable, count against it.
+type breaker struct {
+	backend   string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+}
+
+// newBreaker returns a breaker opening after threshold consecutive
+// failures, or nil, which lets every call through, if threshold is zero.
+func newBreaker(backend string, threshold int, cooldown time.Duration) *breaker {
+	if threshold <= 0 {
+		return nil
+	}
+	return &breaker{backend: backend, threshold: threshold, cooldown: cooldown}
+}
+
+// allow returns ErrCircuitOpen if a call must not be made.
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case open:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = halfOpen
+		return nil
+	case halfOpen:
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of an allowed call.
+func (b *breaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case storage.IsRetryable(err):
+		b.failures++
+		if b.state == halfOpen || (b.state == closed && b.failures >= b.threshold) {
+			if b.state == closed {
+				metrics.SetBreakerOpen(b.backend, true)
+				slog.Warn("storage circuit breaker opened", "backend", b.backend, "failures", b.failures, "error", err)
+			}
+			b.state = open
+			b.openedAt = time.Now()
+		}
+	case err != nil && storage.KindOf(err) == nil:
+		// A call canceled or failing for an unknown reason says nothing
+		// about the backend; let the next call probe it instead.
+		if b.state == halfOpen {
+			b.state = open
+		}
+	default:
+		b.failures = 0
+		if b.state != closed {
+			metrics.SetBreakerOpen(b.backend, false)
+			slog.Info("storage circuit breaker closed", "backend", b.backend)
+			b.state = closed
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// resumingReader reads a byte range of an object, reopening it from the
+// first byte not yet delivered when the backend fails mid-stream.
+type resumingReader struct {
+	ctx        context.Context
+	client     *storageClient
+	bucketName string
+	objectName string
+	// opts.Start is advanced past every byte delivered.
+	opts       storage.GetObjectOptions
+	body       io.ReadCloser
+	reconnects int
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	for {
+		if r.body == nil {
+			if r.opts.Start > r.opts.End {
+				return 0, io.EOF
+			}
+			if err := r.reopen(); err != nil {
+				return 0, err
+			}
+		}
+		n, err := r.body.Read(p)
+		r.opts.Start += int64(n)
+		if err == nil || errors.Is(err, io.EOF) {
+			return n, err
+		}
+		err = storage.Translate("reading object", err)
+		if !storage.IsRetryable(err) || r.reconnects >= r.client.opts.MaxReconnects || r.ctx.Err() != nil {
+			return n, err
+		}
+		r.client.breaker.record(err)
+		r.body.Close()
+		r.body = nil
+		logging.FromContext(r.ctx).Warn("resuming storage read",
+			"backend", r.client.backend,
+			"object", r.objectName,
+			"offset", r.opts.Start,
+			"error", err,
+		)
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// reopen opens the rest of the range.
+func (r *resumingReader) reopen() error {
+	r.reconnects++
+	metrics.RecordStorageReconnect(r.client.backend)
+	body, err := r.client.open(r.ctx, "getting object", func(ctx context.Context) (io.ReadCloser, error) {
+		return r.client.client.GetObject(ctx, r.bucketName, r.objectName, r.opts)
+	})
+	if err != nil {
+		return err
+	}
+	r.body = body
+	return nil
+}
+
+func (r *resumingReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
@@ -0,0 +1,335 @@
+// Package resilience decorates a storage backend so that transient failures
+// do not reach clients: calls are bounded by a timeout, idempotent ones are
+// retried with jittered backoff, a circuit breaker fails calls fast while
+// the backend is down, and reads that break mid-stream are resumed from the
+// last delivered byte.
+package resilience
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// Options configures a resilient storage client.
+type Options struct {
+	// Timeout bounds every call but PutObject, whose duration depends on
+	// the client, and the opening of reads, not reading them. Zero disables
+	// the timeout.
+	Timeout time.Duration
+	// MaxAttempts is how many times an idempotent call is attempted before
+	// its error is returned.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles with
+	// every retry up to MaxDelay and is jittered.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// BreakerThreshold is how many consecutive failures open the circuit
+	// breaker. Zero disables the breaker.
+	BreakerThreshold int
+	// BreakerCooldown is how long the breaker stays open before a single
+	// call is let through to probe the backend.
+	BreakerCooldown time.Duration
+	// MaxReconnects is how many times a read may be resumed after the
+	// backend failed mid-stream.
+	MaxReconnects int
+}
+
+// storageClient makes the calls to a storage backend resilient.
+type storageClient struct {
+	client  storage.Client
+	backend string
+	opts    Options
+	breaker *breaker
+}
+
+// Compile-time check to ensure storageClient implements storage.Client.
+var _ storage.Client = (*storageClient)(nil)
+
+// NewStorageClient wraps client, the backend named backend, so that its
+// calls are resilient as configured by opts.
+func NewStorageClient(client storage.Client, backend string, opts Options) storage.Client {
+	return &storageClient{
+		client:  client,
+		backend: backend,
+		opts:    opts,
+		breaker: newBreaker(backend, opts.BreakerThreshold, opts.BreakerCooldown),
+	}
+}
+
+// Unwrap returns the wrapped client.
+func (s *storageClient) Unwrap() storage.Client {
+	return s.client
+}
+
+// retry makes attempt, guarded by the breaker, until it succeeds, fails
+// with an error that is not retryable or, for calls that are not
+// idempotent, once.
+func (s *storageClient) retry(ctx context.Context, op string, idempotent bool, attempt func() error) error {
+	attempts := 1
+	if idempotent {
+		attempts = max(s.opts.MaxAttempts, 1)
+	}
+	for i := 1; ; i++ {
+		if err := s.breaker.allow(); err != nil {
+			metrics.RecordBreakerRejection(s.backend)
+			return &storage.Error{Kind: storage.ErrUnavailable, Op: op, Err: err}
+		}
+		err := attempt()
+		s.breaker.record(err)
+		if err == nil || !storage.IsRetryable(err) || i >= attempts || ctx.Err() != nil {
+			return err
+		}
+		metrics.RecordStorageRetry(s.backend)
+		delay := s.backoff(i)
+		logging.FromContext(ctx).Warn("retrying storage call",
+			"backend", s.backend,
+			"op", op,
+			"attempt", i,
+			"delay", delay,
+			"error", err,
+		)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the given attempt:
+// half of the exponential delay plus a random part of the other half.
+func (s *storageClient) backoff(attempt int) time.Duration {
+	delay := s.opts.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > s.opts.MaxDelay {
+		delay = s.opts.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// do is retry for calls whose every attempt is bounded by the timeout.
+func (s *storageClient) do(ctx context.Context, op string, idempotent bool, call func(ctx context.Context) error) error {
+	return s.retry(ctx, op, idempotent, func() error {
+		if s.opts.Timeout <= 0 {
+			return call(ctx)
+		}
+		ctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+		return call(ctx)
+	})
+}
+
+// open is retry for calls opening a read. Only the opening is bounded by
+// the timeout: the context of the returned body is canceled when it is
+// closed.
+func (s *storageClient) open(
+	ctx context.Context,
+	op string,
+	call func(ctx context.Context) (io.ReadCloser, error),
+) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := s.retry(ctx, op, true, func() error {
+		ctx, cancel := context.WithCancel(ctx)
+		var timer *time.Timer
+		if s.opts.Timeout > 0 {
+			timer = time.AfterFunc(s.opts.Timeout, cancel)
+		}
+		rc, err := call(ctx)
+		if timer != nil && !timer.Stop() {
+			if rc != nil {
+				rc.Close()
+			}
+			cancel()
+			return &storage.Error{Kind: storage.ErrTimeout, Op: op, Err: context.DeadlineExceeded}
+		}
+		if err != nil {
+			cancel()
+			return err
+		}
+		body = &cancelingBody{ReadCloser: rc, cancel: cancel}
+		return nil
+	})
+	return body, err
+}
+
+// cancelingBody cancels the context of a read when it is closed.
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func (s *storageClient) CreateBucket(ctx context.Context, bucketName string) error {
+	return s.do(ctx, "make bucket", false, func(ctx context.Context) error {
+		return s.client.CreateBucket(ctx, bucketName)
+	})
+}
+
+func (s *storageClient) DoesBucketExists(ctx context.Context, bucketName string) (bool, error) {
+	var exists bool
+	err := s.do(ctx, "checking if bucket exists", true, func(ctx context.Context) (err error) {
+		exists, err = s.client.DoesBucketExists(ctx, bucketName)
+		return err
+	})
+	return exists, err
+}
+
+// GetObject opens the object. Reads of a byte range pinned to a version or
+// an ETag are resumed when the backend fails mid-stream; resuming other
+// reads could mix the bytes of two versions of the object.
+func (s *storageClient) GetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectOptions,
+) (io.ReadCloser, error) {
+	body, err := s.open(ctx, "getting object", func(ctx context.Context) (io.ReadCloser, error) {
+		return s.client.GetObject(ctx, bucketName, objectName, opts)
+	})
+	if err != nil {
+		return nil, err
+	}
+	pinned := opts.VersionID != "" || opts.MatchETag != ""
+	if !pinned || opts.Start < 0 || opts.End < 0 || s.opts.MaxReconnects <= 0 {
+		return body, nil
+	}
+	return &resumingReader{
+		ctx:        ctx,
+		client:     s,
+		bucketName: bucketName,
+		objectName: objectName,
+		opts:       opts,
+		body:       body,
+	}, nil
+}
+
+func (s *storageClient) GetObjectWithRange(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	start int64,
+	end int64,
+) (io.ReadCloser, error) {
+	return s.open(ctx, "getting ranged object", func(ctx context.Context) (io.ReadCloser, error) {
+		return s.client.GetObjectWithRange(ctx, bucketName, objectName, start, end)
+	})
+}
+
+func (s *storageClient) GetObjectInfo(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectInfoOptions,
+) (storage.ObjectInfo, error) {
+	var info storage.ObjectInfo
+	err := s.do(ctx, "stat object", true, func(ctx context.Context) (err error) {
+		info, err = s.client.GetObjectInfo(ctx, bucketName, objectName, opts)
+		return err
+	})
+	return info, err
+}
+
+// PutObject stores the object. It is neither retried, as reader cannot be
+// rewound, nor bounded by the timeout, as uploads take as long as the
+// client takes to send them.
+func (s *storageClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	size int64,
+	opts storage.PutObjectOptions,
+) (storage.ObjectInfo, error) {
+	var info storage.ObjectInfo
+	err := s.retry(ctx, "put object", false, func() (err error) {
+		info, err = s.client.PutObject(ctx, bucketName, objectName, reader, size, opts)
+		return err
+	})
+	return info, err
+}
+
+func (s *storageClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts storage.ListObjectsOptions,
+) ([]storage.ObjectInfo, error) {
+	var objects []storage.ObjectInfo
+	err := s.do(ctx, "list objects", true, func(ctx context.Context) (err error) {
+		objects, err = s.client.ListObjects(ctx, bucketName, opts)
+		return err
+	})
+	return objects, err
+}
+
+// CopyObject copies the object. It is not retried: in a versioned bucket
+// every successful attempt would add a version.
+func (s *storageClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts storage.CopyObjectOptions,
+) (storage.ObjectInfo, error) {
+	var info storage.ObjectInfo
+	err := s.do(ctx, "copy object", false, func(ctx context.Context) (err error) {
+		info, err = s.client.CopyObject(ctx, bucketName, srcObjectName, dstObjectName, opts)
+		return err
+	})
+	return info, err
+}
+
+// RemoveObject removes the object. Only removals of a given version are
+// retried: in a versioned bucket every successful attempt to remove the
+// object itself would add a delete marker.
+func (s *storageClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.RemoveObjectOptions,
+) error {
+	return s.do(ctx, "remove object", opts.VersionID != "", func(ctx context.Context) error {
+		return s.client.RemoveObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+func (s *storageClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	prefix string,
+) ([]storage.IncompleteUpload, error) {
+	var uploads []storage.IncompleteUpload
+	err := s.do(ctx, "list incomplete uploads", true, func(ctx context.Context) (err error) {
+		uploads, err = s.client.ListIncompleteUploads(ctx, bucketName, prefix)
+		return err
+	})
+	return uploads, err
+}
+
+func (s *storageClient) AbortIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	uploadID string,
+) error {
+	return s.do(ctx, "abort multipart upload", false, func(ctx context.Context) error {
+		return s.client.AbortIncompleteUpload(ctx, bucketName, objectName, uploadID)
+	})
+}
+
+func (s *storageClient) EnableVersioning(ctx context.Context, bucketName string) error {
+	return s.do(ctx, "enable versioning", true, func(ctx context.Context) error {
+		return s.client.EnableVersioning(ctx, bucketName)
+	})
+}
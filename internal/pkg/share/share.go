@@ -0,0 +1,303 @@
+// Package share implements shareable download links. A link grants access to
+// one file without credentials until it expires, runs out of downloads or is
+// revoked. Links are addressed by HMAC-signed tokens and their state is kept
+// in the object store so it survives restarts.
+package share
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// DefaultTTL is the lifetime of links created without an explicit one.
+const DefaultTTL = 24 * time.Hour
+
+var (
+	// ErrNotFound is returned for unknown, forged, expired or revoked links.
+	ErrNotFound = errors.New("share link not found")
+	// ErrPasswordRequired is returned when a protected link is opened
+	// without a password or with the wrong one.
+	ErrPasswordRequired = errors.New("share link password required")
+	// ErrExhausted is returned when a link has reached its download limit.
+	ErrExhausted = errors.New("share link download limit reached")
+)
+
+// Link is a shareable link to a single file.
+type Link struct {
+	ID        string    `json:"id"`
+	Namespace string    `json:"namespace"`
+	FileName  string    `json:"file_name"`
+	VersionID string    `json:"version_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// MaxDownloads limits the number of downloads; zero means unlimited.
+	MaxDownloads int64 `json:"max_downloads,omitempty"`
+	Downloads    int64 `json:"downloads"`
+	// Clients are the addresses of the clients that downloaded a limited
+	// link, which may resume their downloads once it is exhausted.
+	Clients []string `json:"clients,omitempty"`
+	// PasswordHash is the bcrypt hash of the link's password, if any.
+	PasswordHash []byte `json:"password_hash,omitempty"`
+}
+
+// PasswordProtected reports whether the link requires a password.
+func (l Link) PasswordProtected() bool {
+	return len(l.PasswordHash) > 0
+}
+
+// CreateOptions defines optional parameters for creating a link.
+type CreateOptions struct {
+	// VersionID pins the link to a version; empty follows the latest one.
+	VersionID string
+	// TTL is how long the link is valid; zero means DefaultTTL.
+	TTL time.Duration
+	// MaxDownloads limits the number of downloads; zero means unlimited.
+	MaxDownloads int64
+	// Password, when set, must be presented to download through the link.
+	Password string
+}
+
+// Manager creates, resolves and revokes share links. Link records are
+// stored under fileutils.SharePrefix in bucket.
+type Manager struct {
+	storageClient storage.Client
+	bucket        string
+	secret        []byte
+	baseURL       string
+	// mu serializes download counting so concurrent downloads cannot
+	// exceed a link's limit.
+	mu  sync.Mutex
+	now func() time.Time
+}
+
+// NewManager returns a Manager storing link records in bucket and signing
+// tokens with secret. baseURL is the public URL of the HTTP server, used to
+// build absolute link URLs; when empty, URLs are relative.
+func NewManager(storageClient storage.Client, bucket string, secret []byte, baseURL string) *Manager {
+	return &Manager{
+		storageClient: storageClient,
+		bucket:        bucket,
+		secret:        secret,
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		now:           time.Now,
+	}
+}
+
+// URL returns the download URL of the link addressed by token.
+func (m *Manager) URL(token string) string {
+	return m.baseURL + "/s/" + token
+}
+
+// Create returns a new link to fileName in ns along with its token.
+func (m *Manager) Create(ctx context.Context, ns namespace.Namespace, fileName string, opts CreateOptions) (Link, string, error) {
+	if opts.TTL < 0 || opts.MaxDownloads < 0 {
+		return Link{}, "", errors.New("ttl and max downloads must not be negative")
+	}
+	if _, err := m.storageClient.GetObjectInfo(ctx, ns.Bucket, fileName, storage.GetObjectInfoOptions{
+		VersionID: opts.VersionID,
+	}); err != nil {
+		return Link{}, "", err
+	}
+	ttl := opts.TTL
+	if ttl == 0 {
+		ttl = DefaultTTL
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return Link{}, "", err
+	}
+	now := m.now()
+	link := Link{
+		ID:           base64.RawURLEncoding.EncodeToString(id),
+		Namespace:    ns.Name,
+		FileName:     fileName,
+		VersionID:    opts.VersionID,
+		CreatedAt:    now,
+		ExpiresAt:    now.Add(ttl),
+		MaxDownloads: opts.MaxDownloads,
+	}
+	if opts.Password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(opts.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return Link{}, "", err
+		}
+		link.PasswordHash = hash
+	}
+	if err := m.save(ctx, link); err != nil {
+		return Link{}, "", err
+	}
+	return link, m.token(link.ID), nil
+}
+
+// Open resolves token to its link and checks password against it. Links
+// that reached their download limit still open, so that transfers already
+// started can be resumed; Count enforces the limit and must be called
+// before serving anything.
+func (m *Manager) Open(ctx context.Context, token, password string) (Link, error) {
+	id, ok := m.verify(token)
+	if !ok {
+		return Link{}, ErrNotFound
+	}
+	link, err := m.load(ctx, id)
+	if err != nil {
+		return Link{}, err
+	}
+	if !m.now().Before(link.ExpiresAt) {
+		return Link{}, ErrNotFound
+	}
+	if link.PasswordProtected() && bcrypt.CompareHashAndPassword(link.PasswordHash, []byte(password)) != nil {
+		return Link{}, ErrPasswordRequired
+	}
+	return link, nil
+}
+
+// Count counts a download by client through the link id against its
+// limit, returning ErrExhausted once it is reached. A download resuming a
+// transfer does not count if client already counted one, so that it can be
+// resumed until the link expires, even once the link is exhausted.
+func (m *Manager) Count(ctx context.Context, id, client string, resume bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	link, err := m.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !m.now().Before(link.ExpiresAt) {
+		return ErrNotFound
+	}
+	limited := link.MaxDownloads > 0
+	if resume && (!limited || slices.Contains(link.Clients, client)) {
+		return nil
+	}
+	if limited && link.Downloads >= link.MaxDownloads {
+		return ErrExhausted
+	}
+	link.Downloads++
+	if limited && !slices.Contains(link.Clients, client) {
+		link.Clients = append(link.Clients, client)
+	}
+	return m.save(ctx, link)
+}
+
+// List returns the active links of ns, most recently created first.
+func (m *Manager) List(ctx context.Context, ns namespace.Namespace) ([]Link, error) {
+	objects, err := m.storageClient.ListObjects(ctx, m.bucket, storage.ListObjectsOptions{
+		Prefix: fileutils.SharePrefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	now := m.now()
+	links := make([]Link, 0, len(objects))
+	for _, object := range objects {
+		link, err := m.load(ctx, strings.TrimPrefix(object.Key, fileutils.SharePrefix))
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if link.Namespace != ns.Name || !now.Before(link.ExpiresAt) {
+			continue
+		}
+		if link.MaxDownloads > 0 && link.Downloads >= link.MaxDownloads {
+			continue
+		}
+		links = append(links, link)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].CreatedAt.After(links[j].CreatedAt) })
+	return links, nil
+}
+
+// Revoke permanently disables the link id of ns.
+func (m *Manager) Revoke(ctx context.Context, ns namespace.Namespace, id string) error {
+	link, err := m.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if link.Namespace != ns.Name {
+		return ErrNotFound
+	}
+	return m.storageClient.RemoveObject(ctx, m.bucket, fileutils.SharePrefix+id, storage.RemoveObjectOptions{})
+}
+
+// token returns the signed token addressing the link id.
+func (m *Manager) token(id string) string {
+	return id + "." + base64.RawURLEncoding.EncodeToString(m.sign(id))
+}
+
+// verify checks the signature of token and returns the link ID it carries.
+func (m *Manager) verify(token string) (string, bool) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return "", false
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, m.sign(id)) {
+		return "", false
+	}
+	return id, true
+}
+
+func (m *Manager) sign(id string) []byte {
+	mac := hmac.New(sha256.New, m.secret)
+	mac.Write([]byte(id))
+	return mac.Sum(nil)
+}
+
+// load reads the record of the link id.
+func (m *Manager) load(ctx context.Context, id string) (Link, error) {
+	if id == "" || strings.Contains(id, "/") {
+		return Link{}, ErrNotFound
+	}
+	obj, err := m.storageClient.GetObject(ctx, m.bucket, fileutils.SharePrefix+id, storage.GetObjectOptions{
+		Start: -1,
+		End:   -1,
+	})
+	if storage.IsNotFound(err) {
+		return Link{}, ErrNotFound
+	}
+	if err != nil {
+		return Link{}, err
+	}
+	defer obj.Close()
+	data, err := io.ReadAll(obj)
+	if err != nil {
+		return Link{}, err
+	}
+	var link Link
+	if err := json.Unmarshal(data, &link); err != nil {
+		return Link{}, fmt.Errorf("decoding share link %q: %w", id, err)
+	}
+	return link, nil
+}
+
+// save writes the record of link.
+func (m *Manager) save(ctx context.Context, link Link) error {
+	data, err := json.Marshal(link)
+	if err != nil {
+		return err
+	}
+	_, err = m.storageClient.PutObject(ctx, m.bucket, fileutils.SharePrefix+link.ID, bytes.NewReader(data), int64(len(data)), storage.PutObjectOptions{
+		ContentType: "application/json",
+	})
+	return err
+}
@@ -3,8 +3,9 @@ package storage
 import (
 	"context"
 	"errors"
-	"fmt"
 	"io"
+	"net/url"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
@@ -15,8 +16,11 @@ type blobStorageClient struct {
 	client *minio.Client
 }
 
-// Compile-time check to ensure blobStorageClient implements Client.
-var _ Client = (*blobStorageClient)(nil)
+// Compile-time check to ensure blobStorageClient implements Client and Presigner.
+var (
+	_ Client    = (*blobStorageClient)(nil)
+	_ Presigner = (*blobStorageClient)(nil)
+)
 
 // ErrBucketAlreadyExists is returned when CreateBucket is called on an existing bucket.
 var ErrBucketAlreadyExists = errors.New("bucket already exists")
@@ -32,6 +36,9 @@ func newClient(
 	client, err := minio.New(endpoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(accessKeyID, secretAccessKey, ""),
 		Secure: useSSL,
+		// Attempt every request once: retries are made by the caller,
+		// e.g. package resilience, within its own timeouts.
+		MaxRetries: 1,
 	})
 	if err != nil {
 		return nil, err
@@ -48,12 +55,12 @@ func newClient(
 func (b *blobStorageClient) CreateBucket(ctx context.Context, bucketName string) error {
 	exists, err := b.DoesBucketExists(ctx, bucketName)
 	if err != nil {
-		return fmt.Errorf("checking if bucket exists: %w", err)
+		return err
 	}
 	if exists {
 		return ErrBucketAlreadyExists
 	}
-	return b.client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{})
+	return Translate("make bucket", b.client.MakeBucket(ctx, bucketName, minio.MakeBucketOptions{}))
 }
 
 // DoesBucketExists checks whether a bucket with the specified name exists.
@@ -61,34 +68,46 @@ func (b *blobStorageClient) CreateBucket(ctx context.Context, bucketName string)
 // Returns true if the bucket exists, false otherwise. Returns an error
 // if the existence check could not be performed.
 func (b *blobStorageClient) DoesBucketExists(ctx context.Context, bucketName string) (bool, error) {
-	return b.client.BucketExists(ctx, bucketName)
+	exists, err := b.client.BucketExists(ctx, bucketName)
+	return exists, Translate("checking if bucket exists", err)
 }
 
-// GetObject retrieves the full object from the specified bucket.
+// GetObject retrieves the object, or the byte range of it selected by opts,
+// from the specified bucket.
 //
-// Returns a MinIO object (*minio.Object), allowing the caller to read the object
-// or perform MinIO-specific operations. Returns an error if the object does not exist
-// or cannot be accessed.
+// Returns an error if the object does not exist or cannot be accessed.
 func (b *blobStorageClient) GetObject(
 	ctx context.Context,
 	bucketName,
 	objectName string,
 	opts GetObjectOptions,
-) (*minio.Object, error) {
-	minioOpts := minio.GetObjectOptions{}
+) (io.ReadCloser, error) {
+	minioOpts := minio.GetObjectOptions{VersionID: opts.VersionID}
 	// Only set range if opts are passed
 	if opts.Start >= 0 && opts.End >= 0 {
 		if err := minioOpts.SetRange(opts.Start, opts.End); err != nil {
-			return nil, fmt.Errorf("invalid range: %w", err)
+			return nil, &Error{Kind: ErrInvalidRange, Op: "invalid range", Err: err}
+		}
+	}
+	statOpts := minio.StatObjectOptions{VersionID: opts.VersionID}
+	if opts.MatchETag != "" {
+		if err := minioOpts.SetMatchETag(opts.MatchETag); err != nil {
+			return nil, Translate("getting object", err)
+		}
+		if err := statOpts.SetMatchETag(opts.MatchETag); err != nil {
+			return nil, Translate("stat object", err)
 		}
 	}
 	obj, err := b.client.GetObject(ctx, bucketName, objectName, minioOpts)
 	if err != nil {
-		return nil, fmt.Errorf("getting object: %w", err)
+		return nil, Translate("getting object", err)
 	}
-	// Ensure the object actually exists
-	if _, err := obj.Stat(); err != nil {
-		return nil, fmt.Errorf("stat object: %w", err)
+	// Ensure the object actually exists. This deliberately does not use
+	// obj.Stat, which drops the Range header so the first Read would fetch
+	// the whole object instead of the requested range.
+	if _, err := b.client.StatObject(ctx, bucketName, objectName, statOpts); err != nil {
+		obj.Close()
+		return nil, Translate("stat object", err)
 	}
 	return obj, nil
 }
@@ -107,15 +126,16 @@ func (b *blobStorageClient) GetObjectWithRange(
 ) (io.ReadCloser, error) {
 	opts := minio.GetObjectOptions{}
 	if err := opts.SetRange(start, end); err != nil {
-		return nil, fmt.Errorf("invalid range: %w", err)
+		return nil, &Error{Kind: ErrInvalidRange, Op: "invalid range", Err: err}
 	}
 	obj, err := b.client.GetObject(ctx, bucketName, objectName, opts)
 	if err != nil {
-		return nil, fmt.Errorf("getting ranged object: %w", err)
+		return nil, Translate("getting ranged object", err)
 	}
-	// Ensure object exists
-	if _, err := obj.Stat(); err != nil {
-		return nil, fmt.Errorf("stat object: %w", err)
+	// Ensure object exists (see GetObject for why obj.Stat is not used)
+	if _, err := b.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{}); err != nil {
+		obj.Close()
+		return nil, Translate("stat object", err)
 	}
 	return obj, nil
 }
@@ -128,12 +148,211 @@ func (b *blobStorageClient) GetObjectInfo(
 	ctx context.Context,
 	bucketName string,
 	objectName string,
+	opts GetObjectInfoOptions,
 ) (ObjectInfo, error) {
-	info, err := b.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{})
+	info, err := b.client.StatObject(ctx, bucketName, objectName, minio.StatObjectOptions{
+		VersionID: opts.VersionID,
+	})
 	if err != nil {
-		return ObjectInfo{}, fmt.Errorf("stat object: %w", err)
+		return ObjectInfo{}, Translate("stat object", err)
+	}
+	objectInfo := toObjectInfo(info)
+	// MinIO only reports IsLatest in versioned listings.
+	objectInfo.IsLatest = opts.VersionID == ""
+	return objectInfo, nil
+}
+
+// PutObject uploads the contents of reader to the specified bucket.
+//
+// If size is -1 the MinIO client streams the object as a multipart upload.
+// Returns the ObjectInfo of the stored object, or an error if the upload failed.
+func (b *blobStorageClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	size int64,
+	opts PutObjectOptions,
+) (ObjectInfo, error) {
+	info, err := b.client.PutObject(ctx, bucketName, objectName, reader, size, minio.PutObjectOptions{
+		ContentType:  opts.ContentType,
+		UserMetadata: opts.Metadata,
+		UserTags:     opts.Tags,
+	})
+	if err != nil {
+		return ObjectInfo{}, Translate("put object", err)
 	}
 	return ObjectInfo{
-		Size: info.Size,
+		Key:          info.Key,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		ContentType:  opts.ContentType,
+		LastModified: info.LastModified,
+		Metadata:     opts.Metadata,
+		Tags:         opts.Tags,
+		VersionID:    info.VersionID,
+		IsLatest:     true,
 	}, nil
 }
+
+// ListObjects lists every object in the bucket under opts.Prefix.
+//
+// With opts.WithVersions, MinIO returns all versions of each object,
+// newest first, including delete markers.
+func (b *blobStorageClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts ListObjectsOptions,
+) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for info := range b.client.ListObjects(ctx, bucketName, minio.ListObjectsOptions{
+		Prefix:       opts.Prefix,
+		Recursive:    true,
+		WithVersions: opts.WithVersions,
+		WithMetadata: true,
+	}) {
+		if info.Err != nil {
+			return nil, Translate("list objects", info.Err)
+		}
+		objectInfo := toObjectInfo(info)
+		if !opts.WithVersions {
+			objectInfo.IsLatest = true
+		}
+		objects = append(objects, objectInfo)
+	}
+	return objects, nil
+}
+
+// CopyObject copies an object (optionally a specific version) to a new name
+// in the same bucket. ComposeObject is used so objects larger than the 5GiB
+// single copy limit are copied part by part.
+func (b *blobStorageClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts CopyObjectOptions,
+) (ObjectInfo, error) {
+	info, err := b.client.ComposeObject(ctx,
+		minio.CopyDestOptions{Bucket: bucketName, Object: dstObjectName},
+		minio.CopySrcOptions{Bucket: bucketName, Object: srcObjectName, VersionID: opts.SrcVersionID},
+	)
+	if err != nil {
+		return ObjectInfo{}, Translate("copy object", err)
+	}
+	return ObjectInfo{
+		Key:          info.Key,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		VersionID:    info.VersionID,
+		IsLatest:     true,
+	}, nil
+}
+
+// RemoveObject removes an object, or a single version of it when
+// opts.VersionID is set.
+func (b *blobStorageClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts RemoveObjectOptions,
+) error {
+	if err := b.client.RemoveObject(ctx, bucketName, objectName, minio.RemoveObjectOptions{
+		VersionID: opts.VersionID,
+	}); err != nil {
+		return Translate("remove object", err)
+	}
+	return nil
+}
+
+// ListIncompleteUploads lists unfinished multipart uploads under prefix.
+func (b *blobStorageClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	prefix string,
+) ([]IncompleteUpload, error) {
+	var uploads []IncompleteUpload
+	for upload := range b.client.ListIncompleteUploads(ctx, bucketName, prefix, true) {
+		if upload.Err != nil {
+			return nil, Translate("list incomplete uploads", upload.Err)
+		}
+		uploads = append(uploads, IncompleteUpload{
+			Key:       upload.Key,
+			UploadID:  upload.UploadID,
+			Initiated: upload.Initiated,
+		})
+	}
+	return uploads, nil
+}
+
+// AbortIncompleteUpload aborts a multipart upload through the MinIO core API.
+func (b *blobStorageClient) AbortIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	uploadID string,
+) error {
+	core := minio.Core{Client: b.client}
+	if err := core.AbortMultipartUpload(ctx, bucketName, objectName, uploadID); err != nil {
+		return Translate("abort multipart upload", err)
+	}
+	return nil
+}
+
+// EnableVersioning enables MinIO bucket versioning for the bucket.
+func (b *blobStorageClient) EnableVersioning(ctx context.Context, bucketName string) error {
+	if err := b.client.EnableVersioning(ctx, bucketName); err != nil {
+		return Translate("enable versioning", err)
+	}
+	return nil
+}
+
+// toObjectInfo converts a MinIO object description into an ObjectInfo.
+func toObjectInfo(info minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Key:            info.Key,
+		Size:           info.Size,
+		ETag:           info.ETag,
+		ContentType:    info.ContentType,
+		LastModified:   info.LastModified,
+		Metadata:       info.UserMetadata,
+		Tags:           info.UserTags,
+		VersionID:      info.VersionID,
+		IsLatest:       info.IsLatest,
+		IsDeleteMarker: info.IsDeleteMarker,
+	}
+}
+
+// PresignGetObject returns a presigned URL for downloading the object.
+func (b *blobStorageClient) PresignGetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	expiry time.Duration,
+	opts PresignGetObjectOptions,
+) (*url.URL, error) {
+	params := url.Values{}
+	if opts.VersionID != "" {
+		params.Set("versionId", opts.VersionID)
+	}
+	u, err := b.client.PresignedGetObject(ctx, bucketName, objectName, expiry, params)
+	if err != nil {
+		return nil, Translate("presigning get", err)
+	}
+	return u, nil
+}
+
+// PresignPutObject returns a presigned URL for uploading the object.
+func (b *blobStorageClient) PresignPutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	expiry time.Duration,
+) (*url.URL, error) {
+	u, err := b.client.PresignedPutObject(ctx, bucketName, objectName, expiry)
+	if err != nil {
+		return nil, Translate("presigning put", err)
+	}
+	return u, nil
+}
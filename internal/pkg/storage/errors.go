@@ -0,0 +1,175 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// Kinds of storage errors. Errors returned by a Client match one of them
+// with errors.Is when their cause is known; the backend's own error stays
+// reachable with errors.As.
+var (
+	// ErrNotFound means the requested object or version does not exist.
+	ErrNotFound = errors.New("object not found")
+	// ErrBucketNotFound means the bucket backing a namespace does not exist.
+	ErrBucketNotFound = errors.New("bucket not found")
+	// ErrAccessDenied means the backend refused the server's credentials.
+	ErrAccessDenied = errors.New("storage access denied")
+	// ErrInvalidRange means the requested byte range lies outside the object.
+	ErrInvalidRange = errors.New("requested range not satisfiable")
+	// ErrModified means the object no longer has the ETag a read was
+	// pinned to.
+	ErrModified = errors.New("object was modified")
+	// ErrThrottled means the backend is shedding load; the call may be
+	// retried later.
+	ErrThrottled = errors.New("storage is throttling requests")
+	// ErrUnavailable means the backend could not be reached or failed.
+	ErrUnavailable = errors.New("storage unavailable")
+	// ErrTimeout means the backend did not answer in time.
+	ErrTimeout = errors.New("storage timed out")
+	// ErrReadOnly means the backend only serves reads, e.g. an upstream
+	// file-streamer cached at an edge site.
+	ErrReadOnly = errors.New("storage is read-only")
+)
+
+// kinds lists every kind of storage error.
+var kinds = []error{
+	ErrNotFound,
+	ErrBucketNotFound,
+	ErrAccessDenied,
+	ErrInvalidRange,
+	ErrModified,
+	ErrThrottled,
+	ErrUnavailable,
+	ErrTimeout,
+	ErrReadOnly,
+}
+
+// Error is a storage error of a known kind.
+type Error struct {
+	// Kind is one of the Err* kinds.
+	Kind error
+	// Op describes the failed operation, e.g. "stat object".
+	Op string
+	// Err is the backend's error.
+	Err error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is the kind of e.
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// KindOf returns the kind of a storage error, or nil when it is not known.
+func KindOf(err error) error {
+	for _, kind := range kinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	return nil
+}
+
+// IsNotFound reports whether err means the requested bucket, object or
+// version does not exist.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound) || errors.Is(err, ErrBucketNotFound)
+}
+
+// IsRetryable reports whether a call failing with err may succeed if
+// retried.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrThrottled) || errors.Is(err, ErrUnavailable) || errors.Is(err, ErrTimeout)
+}
+
+// Translate wraps a MinIO error, such as one returned while reading an
+// object, as an Error of the matching kind prefixed with op. Errors of unknown cause and cancellations by the caller are
+// only prefixed.
+func Translate(op string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if kind := classify(err); kind != nil {
+		return &Error{Kind: kind, Op: op, Err: err}
+	}
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+// classify returns the kind of a MinIO error.
+func classify(err error) error {
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ErrTimeout
+	}
+	var resp minio.ErrorResponse
+	if errors.As(err, &resp) {
+		return classifyResponse(resp)
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return ErrTimeout
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrUnavailable
+	}
+	return nil
+}
+
+func classifyResponse(resp minio.ErrorResponse) error {
+	switch resp.Code {
+	case "NoSuchKey", "NoSuchVersion":
+		return ErrNotFound
+	case "NoSuchBucket":
+		return ErrBucketNotFound
+	case "AccessDenied", "InvalidAccessKeyId", "SignatureDoesNotMatch", "AllAccessDisabled":
+		return ErrAccessDenied
+	case "InvalidRange":
+		return ErrInvalidRange
+	case "PreconditionFailed":
+		return ErrModified
+	case "SlowDown", "SlowDownRead", "SlowDownWrite", "RequestLimitExceeded", "TooManyRequests":
+		return ErrThrottled
+	case "RequestTimeout":
+		return ErrTimeout
+	case "XMinioServerNotInitialized", "XMinioStorageFull", "ServiceUnavailable", "InternalError":
+		return ErrUnavailable
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode == http.StatusForbidden:
+		return ErrAccessDenied
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		return ErrInvalidRange
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return ErrModified
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return ErrThrottled
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusGatewayTimeout:
+		return ErrTimeout
+	case resp.StatusCode >= 500:
+		return ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,422 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrVersioningUnsupported is returned by EnableVersioning on backends that
+// keep a single version of every object.
+var ErrVersioningUnsupported = errors.New("versioning is not supported")
+
+// filesystemClient stores objects as files in a local directory: bucket b's
+// object k is the file root/b/k. The ETag, content type, metadata and tags
+// of each object are kept in root/.meta/b/k.json, and files are written to
+// root/.tmp before being renamed into place, so readers never see a partial
+// object. It keeps a single version of every object.
+type filesystemClient struct {
+	root string
+}
+
+// Compile-time check to ensure filesystemClient implements Client.
+var _ Client = (*filesystemClient)(nil)
+
+// NewFilesystemClient returns a client storing objects under the directory
+// root, which is created if needed.
+func NewFilesystemClient(root string) (Client, error) {
+	for _, dir := range []string{root, filepath.Join(root, metaDir), filepath.Join(root, tmpDir)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, fmt.Errorf("storage: %w", err)
+		}
+	}
+	return &filesystemClient{root: root}, nil
+}
+
+const (
+	metaDir = ".meta"
+	tmpDir  = ".tmp"
+)
+
+// fileMeta is the part of an object's description not held by its file.
+type fileMeta struct {
+	ETag        string            `json:"etag"`
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+}
+
+// bucketPath returns the directory of the bucket.
+func (f *filesystemClient) bucketPath(op, bucketName string) (string, error) {
+	if bucketName == "" || strings.HasPrefix(bucketName, ".") || strings.ContainsAny(bucketName, `/\`) {
+		return "", fmt.Errorf("%s: invalid bucket name %q", op, bucketName)
+	}
+	dir := filepath.Join(f.root, bucketName)
+	if _, err := os.Stat(dir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", &Error{Kind: ErrBucketNotFound, Op: op, Err: err}
+		}
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	return dir, nil
+}
+
+// objectPaths returns the data and metadata files of the object.
+func (f *filesystemClient) objectPaths(op, bucketName, objectName string) (data, meta string, err error) {
+	dir, err := f.bucketPath(op, bucketName)
+	if err != nil {
+		return "", "", err
+	}
+	name := filepath.FromSlash(objectName)
+	if !filepath.IsLocal(name) || strings.HasSuffix(objectName, "/") {
+		return "", "", fmt.Errorf("%s: invalid object name %q", op, objectName)
+	}
+	return filepath.Join(dir, name), filepath.Join(f.root, metaDir, bucketName, name+".json"), nil
+}
+
+// stat describes the object stored in the given files.
+func (f *filesystemClient) stat(op, objectName, data, meta string) (ObjectInfo, error) {
+	fi, err := os.Stat(data)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return ObjectInfo{}, &Error{Kind: ErrNotFound, Op: op, Err: err}
+		}
+		return ObjectInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if fi.IsDir() {
+		return ObjectInfo{}, &Error{Kind: ErrNotFound, Op: op, Err: fs.ErrNotExist}
+	}
+	var m fileMeta
+	raw, err := os.ReadFile(meta)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return ObjectInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(raw, &m); err != nil {
+			return ObjectInfo{}, fmt.Errorf("%s: reading metadata: %w", op, err)
+		}
+	}
+	return ObjectInfo{
+		Key:          objectName,
+		Size:         fi.Size(),
+		ETag:         m.ETag,
+		ContentType:  m.ContentType,
+		LastModified: fi.ModTime(),
+		Metadata:     m.Metadata,
+		Tags:         m.Tags,
+		IsLatest:     true,
+	}, nil
+}
+
+func (f *filesystemClient) CreateBucket(_ context.Context, bucketName string) error {
+	if _, err := f.bucketPath("make bucket", bucketName); err == nil {
+		return ErrBucketAlreadyExists
+	} else if !errors.Is(err, ErrBucketNotFound) {
+		return err
+	}
+	if err := os.Mkdir(filepath.Join(f.root, bucketName), 0o755); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return ErrBucketAlreadyExists
+		}
+		return fmt.Errorf("make bucket: %w", err)
+	}
+	return nil
+}
+
+func (f *filesystemClient) DoesBucketExists(_ context.Context, bucketName string) (bool, error) {
+	_, err := f.bucketPath("checking if bucket exists", bucketName)
+	if errors.Is(err, ErrBucketNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// GetObject opens the object, or the byte range of it selected by opts.
+// Only the current version exists: naming any other fails with ErrNotFound.
+func (f *filesystemClient) GetObject(
+	_ context.Context,
+	bucketName string,
+	objectName string,
+	opts GetObjectOptions,
+) (io.ReadCloser, error) {
+	const op = "getting object"
+	if opts.VersionID != "" {
+		return nil, &Error{Kind: ErrNotFound, Op: op, Err: fmt.Errorf("no version %q", opts.VersionID)}
+	}
+	data, meta, err := f.objectPaths(op, bucketName, objectName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.stat(op, objectName, data, meta)
+	if err != nil {
+		return nil, err
+	}
+	if opts.MatchETag != "" && opts.MatchETag != info.ETag {
+		return nil, &Error{Kind: ErrModified, Op: op, Err: fmt.Errorf("etag is %q", info.ETag)}
+	}
+	file, err := os.Open(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	// Like MinIO, a negative bound selects the whole object.
+	if opts.Start < 0 || opts.End < 0 {
+		return file, nil
+	}
+	start, end := opts.Start, min(opts.End, info.Size-1)
+	if start > end {
+		file.Close()
+		return nil, &Error{Kind: ErrInvalidRange, Op: op, Err: fmt.Errorf("range %d-%d of %d bytes", opts.Start, opts.End, info.Size)}
+	}
+	return &sectionFile{Reader: io.NewSectionReader(file, start, end-start+1), file: file}, nil
+}
+
+// sectionFile reads a section of a file and closes the file.
+type sectionFile struct {
+	io.Reader
+	file *os.File
+}
+
+func (s *sectionFile) Close() error {
+	return s.file.Close()
+}
+
+func (f *filesystemClient) GetObjectWithRange(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	start int64,
+	end int64,
+) (io.ReadCloser, error) {
+	return f.GetObject(ctx, bucketName, objectName, GetObjectOptions{Start: start, End: end})
+}
+
+func (f *filesystemClient) GetObjectInfo(
+	_ context.Context,
+	bucketName string,
+	objectName string,
+	opts GetObjectInfoOptions,
+) (ObjectInfo, error) {
+	const op = "stat object"
+	if opts.VersionID != "" {
+		return ObjectInfo{}, &Error{Kind: ErrNotFound, Op: op, Err: fmt.Errorf("no version %q", opts.VersionID)}
+	}
+	data, meta, err := f.objectPaths(op, bucketName, objectName)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return f.stat(op, objectName, data, meta)
+}
+
+// PutObject writes reader to a temporary file, computing its MD5 as the
+// ETag, and renames it into place once complete.
+func (f *filesystemClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	size int64,
+	opts PutObjectOptions,
+) (ObjectInfo, error) {
+	const op = "put object"
+	data, meta, err := f.objectPaths(op, bucketName, objectName)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	tmp, err := os.CreateTemp(filepath.Join(f.root, tmpDir), "put-*")
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(tmp.Name())
+	hash := md5.New()
+	if size >= 0 {
+		reader = io.LimitReader(reader, size)
+	}
+	n, err := io.Copy(io.MultiWriter(tmp, hash), &contextReader{ctx: ctx, r: reader})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if size >= 0 && n != size {
+		return ObjectInfo{}, fmt.Errorf("%s: %w: read %d of %d bytes", op, io.ErrUnexpectedEOF, n, size)
+	}
+	m := fileMeta{
+		ETag:        hex.EncodeToString(hash.Sum(nil)),
+		ContentType: opts.ContentType,
+		Metadata:    opts.Metadata,
+		Tags:        opts.Tags,
+	}
+	if err := f.commit(op, tmp.Name(), data, meta, m); err != nil {
+		return ObjectInfo{}, err
+	}
+	return f.stat(op, objectName, data, meta)
+}
+
+// commit moves the temporary file tmp into place as the object stored in
+// data and meta.
+func (f *filesystemClient) commit(op, tmp, data, meta string, m fileMeta) error {
+	raw, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	for _, dir := range []string{filepath.Dir(data), filepath.Dir(meta)} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	metaTmp := tmp + ".json"
+	if err := os.WriteFile(metaTmp, raw, 0o644); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer os.Remove(metaTmp)
+	if err := os.Rename(metaTmp, meta); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if err := os.Rename(tmp, data); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return nil
+}
+
+// contextReader stops reading once ctx is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// ListObjects lists the objects in the bucket under opts.Prefix, sorted by
+// name. Every object has a single version, so opts.WithVersions changes
+// nothing.
+func (f *filesystemClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts ListObjectsOptions,
+) ([]ObjectInfo, error) {
+	const op = "list objects"
+	dir, err := f.bucketPath(op, bucketName)
+	if err != nil {
+		return nil, err
+	}
+	var objects []ObjectInfo
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if !strings.HasPrefix(key, opts.Prefix) {
+			return nil
+		}
+		info, err := f.stat(op, key, path, filepath.Join(f.root, metaDir, bucketName, rel+".json"))
+		if errors.Is(err, ErrNotFound) {
+			// Removed while listing.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		objects = append(objects, info)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// CopyObject copies the object and its metadata.
+func (f *filesystemClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts CopyObjectOptions,
+) (ObjectInfo, error) {
+	const op = "copy object"
+	src, err := f.GetObject(ctx, bucketName, srcObjectName, GetObjectOptions{
+		Start:     -1,
+		End:       -1,
+		VersionID: opts.SrcVersionID,
+	})
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	defer src.Close()
+	data, meta, err := f.objectPaths(op, bucketName, srcObjectName)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	info, err := f.stat(op, srcObjectName, data, meta)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	return f.PutObject(ctx, bucketName, dstObjectName, src, info.Size, PutObjectOptions{
+		ContentType: info.ContentType,
+		Metadata:    info.Metadata,
+		Tags:        info.Tags,
+	})
+}
+
+// RemoveObject removes the object. Only the current version exists:
+// removing any other is a no-op.
+func (f *filesystemClient) RemoveObject(
+	_ context.Context,
+	bucketName string,
+	objectName string,
+	opts RemoveObjectOptions,
+) error {
+	const op = "remove object"
+	if opts.VersionID != "" {
+		return nil
+	}
+	data, meta, err := f.objectPaths(op, bucketName, objectName)
+	if err != nil {
+		return err
+	}
+	for _, path := range []string{data, meta} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	return nil
+}
+
+// ListIncompleteUploads returns nothing: uploads that fail leave no trace.
+func (f *filesystemClient) ListIncompleteUploads(context.Context, string, string) ([]IncompleteUpload, error) {
+	return nil, nil
+}
+
+func (f *filesystemClient) AbortIncompleteUpload(_ context.Context, _, _, uploadID string) error {
+	return &Error{Kind: ErrNotFound, Op: "abort multipart upload", Err: fmt.Errorf("no upload %q", uploadID)}
+}
+
+func (f *filesystemClient) EnableVersioning(context.Context, string) error {
+	return fmt.Errorf("enable versioning: %w", ErrVersioningUnsupported)
+}
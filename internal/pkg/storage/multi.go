@@ -0,0 +1,457 @@
+package storage
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"slices"
+	"sync/atomic"
+	"time"
+)
+
+// recheckInterval is how long an endpoint that failed is only used as a last
+// resort, before being tried first again if it is the best one.
+const recheckInterval = 10 * time.Second
+
+// latencyWeight is the weight of a new sample in an endpoint's average
+// latency.
+const latencyWeight = 0.2
+
+// member is one endpoint of a multiClient.
+type member struct {
+	endpoint Endpoint
+	client   Client
+	// latency is the moving average of the latency of point reads, in
+	// nanoseconds.
+	latency atomic.Int64
+	// downUntil is when, in Unix nanoseconds, an endpoint that failed may
+	// be preferred again.
+	downUntil atomic.Int64
+}
+
+func (m *member) up(now time.Time) bool {
+	return m.downUntil.Load() <= now.UnixNano()
+}
+
+// observe updates the health and, when timed, the latency of the endpoint
+// with the outcome of a call that started at start.
+func (m *member) observe(start time.Time, err error, timed bool) {
+	now := time.Now()
+	switch {
+	case IsRetryable(err):
+		if m.downUntil.Swap(now.Add(recheckInterval).UnixNano()) == 0 {
+			slog.Warn("storage endpoint down", "endpoint", m.endpoint.Name, "error", err)
+		}
+	case err == nil || KindOf(err) != nil:
+		// The endpoint answered, even if with an error of its own.
+		if m.downUntil.Swap(0) != 0 {
+			slog.Info("storage endpoint up", "endpoint", m.endpoint.Name)
+		}
+		if timed {
+			sample := now.Sub(start).Nanoseconds()
+			if old := m.latency.Load(); old != 0 {
+				sample = int64(latencyWeight*float64(sample) + (1-latencyWeight)*float64(old))
+			}
+			m.latency.Store(sample)
+		}
+	}
+}
+
+// multiClient spreads calls over several endpoints holding the same
+// buckets. Reads go to the endpoints that are up, fastest first; writes go
+// to the primary endpoints, then to the replicas. Both move on to the next
+// endpoint when one is unreachable.
+type multiClient struct {
+	members    []*member
+	hedgeDelay time.Duration
+	// observe, when set, is told of every routing event.
+	observe func(RoutingEvent)
+}
+
+// Compile-time check to ensure multiClient implements Client.
+var _ Client = (*multiClient)(nil)
+
+// record reports a routing event to the observer.
+func (c *multiClient) record(event RoutingEvent) {
+	if c.observe != nil {
+		c.observe(event)
+	}
+}
+
+// Unwrap returns the client of the first primary endpoint, so that
+// presigned URLs point to it.
+func (c *multiClient) Unwrap() Client {
+	return c.writeOrder()[0].client
+}
+
+// readOrder returns the endpoints in the order reads should try them.
+func (c *multiClient) readOrder() []*member {
+	now := time.Now()
+	order := slices.Clone(c.members)
+	slices.SortStableFunc(order, func(a, b *member) int {
+		if upA, upB := a.up(now), b.up(now); upA != upB {
+			if upA {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(a.latency.Load(), b.latency.Load())
+	})
+	return order
+}
+
+// writeOrder returns the endpoints in the order writes should try them.
+func (c *multiClient) writeOrder() []*member {
+	now := time.Now()
+	order := slices.Clone(c.members)
+	slices.SortStableFunc(order, func(a, b *member) int {
+		if primaryA, primaryB := a.endpoint.Role == RolePrimary, b.endpoint.Role == RolePrimary; primaryA != primaryB {
+			if primaryA {
+				return -1
+			}
+			return 1
+		}
+		if upA, upB := a.up(now), b.up(now); upA != upB {
+			if upA {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	})
+	return order
+}
+
+// readElsewhere reports whether a read that failed on m with err should be
+// tried on another endpoint: m is unreachable or, being a replica, may not
+// have caught up with the object yet.
+func readElsewhere(m *member, err error) bool {
+	if IsRetryable(err) {
+		return true
+	}
+	return m.endpoint.Role == RoleReplica && (IsNotFound(err) || errors.Is(err, ErrModified))
+}
+
+// read calls call with the client of every endpoint in read order until it
+// succeeds. When every endpoint failed, the error of a primary one is
+// returned in preference.
+func (c *multiClient) read(ctx context.Context, timed bool, call func(Client) error) error {
+	var err error
+	for _, m := range c.readOrder() {
+		start := time.Now()
+		callErr := call(m.client)
+		m.observe(start, callErr, timed)
+		if callErr == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !readElsewhere(m, callErr) {
+			return callErr
+		}
+		if err == nil || m.endpoint.Role == RolePrimary {
+			err = callErr
+		}
+		c.record(RoutingFailover)
+	}
+	return err
+}
+
+// write calls call with the client of every endpoint in write order until
+// it succeeds or fails for another reason than the endpoint being
+// unreachable.
+func (c *multiClient) write(ctx context.Context, call func(Client) error) error {
+	var err error
+	for _, m := range c.writeOrder() {
+		start := time.Now()
+		callErr := call(m.client)
+		if callErr == errReaderConsumed {
+			break
+		}
+		m.observe(start, callErr, false)
+		if callErr == nil {
+			if m.endpoint.Role != RolePrimary {
+				slog.Warn("storage write failed over", "endpoint", m.endpoint.Name, "error", err)
+			}
+			return nil
+		}
+		if ctx.Err() != nil || !IsRetryable(callErr) {
+			return callErr
+		}
+		if err == nil {
+			err = callErr
+		}
+		c.record(RoutingFailover)
+	}
+	return err
+}
+
+// openResult is the outcome of opening a read on one endpoint.
+type openResult struct {
+	index  int
+	member *member
+	body   io.ReadCloser
+	err    error
+}
+
+// open opens a read with call on the endpoints in read order, failing over
+// as read does. When hedging is enabled and the first endpoint has not
+// answered after the hedge delay, the read is also opened on the next one
+// and the first body opened is returned.
+func (c *multiClient) open(
+	ctx context.Context,
+	call func(ctx context.Context, client Client) (io.ReadCloser, error),
+) (io.ReadCloser, error) {
+	order := c.readOrder()
+	results := make(chan openResult, len(order))
+	var cancels []context.CancelFunc
+	launch := func() {
+		index := len(cancels)
+		m := order[index]
+		ctx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		go func() {
+			start := time.Now()
+			body, err := call(ctx, m.client)
+			m.observe(start, err, true)
+			results <- openResult{index: index, member: m, body: body, err: err}
+		}()
+	}
+	// discard releases the reads still being opened once they are.
+	discard := func(pending int) {
+		go func() {
+			for range pending {
+				if r := <-results; r.body != nil {
+					r.body.Close()
+				}
+			}
+		}()
+	}
+
+	launch()
+	pending := 1
+	var hedge <-chan time.Time
+	if c.hedgeDelay > 0 && len(order) > 1 {
+		timer := time.NewTimer(c.hedgeDelay)
+		defer timer.Stop()
+		hedge = timer.C
+	}
+	hedged := -1
+	var err error
+	for pending > 0 {
+		select {
+		case <-hedge:
+			hedge = nil
+			if len(cancels) < len(order) {
+				c.record(RoutingHedgedRead)
+				hedged = len(cancels)
+				launch()
+				pending++
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				for i, cancel := range cancels {
+					if i != r.index {
+						cancel()
+					}
+				}
+				discard(pending)
+				if r.index == hedged {
+					c.record(RoutingHedgeWin)
+				}
+				return &cancelingBody{ReadCloser: r.body, cancel: cancels[r.index]}, nil
+			}
+			cancels[r.index]()
+			if ctx.Err() != nil || !readElsewhere(r.member, r.err) {
+				for _, cancel := range cancels {
+					cancel()
+				}
+				discard(pending)
+				return nil, r.err
+			}
+			if err == nil || r.member.endpoint.Role == RolePrimary {
+				err = r.err
+			}
+			if pending == 0 && len(cancels) < len(order) {
+				c.record(RoutingFailover)
+				launch()
+				pending++
+			}
+		}
+	}
+	return nil, err
+}
+
+// cancelingBody cancels the context of a read when it is closed.
+type cancelingBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelingBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+func (c *multiClient) CreateBucket(ctx context.Context, bucketName string) error {
+	return c.write(ctx, func(client Client) error {
+		return client.CreateBucket(ctx, bucketName)
+	})
+}
+
+func (c *multiClient) DoesBucketExists(ctx context.Context, bucketName string) (bool, error) {
+	var exists bool
+	err := c.read(ctx, true, func(client Client) (err error) {
+		exists, err = client.DoesBucketExists(ctx, bucketName)
+		return err
+	})
+	return exists, err
+}
+
+func (c *multiClient) GetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts GetObjectOptions,
+) (io.ReadCloser, error) {
+	return c.open(ctx, func(ctx context.Context, client Client) (io.ReadCloser, error) {
+		return client.GetObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+func (c *multiClient) GetObjectWithRange(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	start int64,
+	end int64,
+) (io.ReadCloser, error) {
+	return c.open(ctx, func(ctx context.Context, client Client) (io.ReadCloser, error) {
+		return client.GetObjectWithRange(ctx, bucketName, objectName, start, end)
+	})
+}
+
+func (c *multiClient) GetObjectInfo(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts GetObjectInfoOptions,
+) (ObjectInfo, error) {
+	var info ObjectInfo
+	err := c.read(ctx, true, func(client Client) (err error) {
+		info, err = client.GetObjectInfo(ctx, bucketName, objectName, opts)
+		return err
+	})
+	return info, err
+}
+
+// PutObject stores the object on the first reachable endpoint in write
+// order. It only fails over while nothing has been read from reader: the
+// other endpoints cannot be given the bytes already read.
+func (c *multiClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	size int64,
+	opts PutObjectOptions,
+) (ObjectInfo, error) {
+	counted := &countingReader{r: reader}
+	var info ObjectInfo
+	err := c.write(ctx, func(client Client) (err error) {
+		if counted.n > 0 {
+			return errReaderConsumed
+		}
+		info, err = client.PutObject(ctx, bucketName, objectName, counted, size, opts)
+		return err
+	})
+	return info, err
+}
+
+// errReaderConsumed stops a write from failing over, returning the error of
+// the endpoint that failed instead.
+var errReaderConsumed = errors.New("reader already consumed")
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func (c *multiClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts ListObjectsOptions,
+) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := c.read(ctx, false, func(client Client) (err error) {
+		objects, err = client.ListObjects(ctx, bucketName, opts)
+		return err
+	})
+	return objects, err
+}
+
+func (c *multiClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts CopyObjectOptions,
+) (ObjectInfo, error) {
+	var info ObjectInfo
+	err := c.write(ctx, func(client Client) (err error) {
+		info, err = client.CopyObject(ctx, bucketName, srcObjectName, dstObjectName, opts)
+		return err
+	})
+	return info, err
+}
+
+func (c *multiClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts RemoveObjectOptions,
+) error {
+	return c.write(ctx, func(client Client) error {
+		return client.RemoveObject(ctx, bucketName, objectName, opts)
+	})
+}
+
+// ListIncompleteUploads lists the uploads of the endpoint writes go to, as
+// multipart uploads are local to an endpoint.
+func (c *multiClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	prefix string,
+) ([]IncompleteUpload, error) {
+	var uploads []IncompleteUpload
+	err := c.write(ctx, func(client Client) (err error) {
+		uploads, err = client.ListIncompleteUploads(ctx, bucketName, prefix)
+		return err
+	})
+	return uploads, err
+}
+
+func (c *multiClient) AbortIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	uploadID string,
+) error {
+	return c.write(ctx, func(client Client) error {
+		return client.AbortIncompleteUpload(ctx, bucketName, objectName, uploadID)
+	})
+}
+
+func (c *multiClient) EnableVersioning(ctx context.Context, bucketName string) error {
+	return c.write(ctx, func(client Client) error {
+		return client.EnableVersioning(ctx, bucketName)
+	})
+}
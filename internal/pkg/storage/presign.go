@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"net/url"
+	"time"
+)
+
+// PresignGetObjectOptions defines optional parameters for presigning a download.
+type PresignGetObjectOptions struct {
+	// VersionID selects a specific version; empty means the latest version.
+	VersionID string
+}
+
+// Presigner is implemented by storage clients that can issue URLs granting
+// direct, time-limited access to an object, so that transfers bypass the
+// server entirely.
+type Presigner interface {
+	// PresignGetObject returns a URL from which the object can be downloaded
+	// until expiry has passed.
+	PresignGetObject(ctx context.Context, bucketName, objectName string, expiry time.Duration, opts PresignGetObjectOptions) (*url.URL, error)
+
+	// PresignPutObject returns a URL to which the object can be uploaded
+	// with a single PUT until expiry has passed.
+	PresignPutObject(ctx context.Context, bucketName, objectName string, expiry time.Duration) (*url.URL, error)
+}
+
+// Wrapper is implemented by Clients that decorate another Client, so that
+// optional capabilities of the wrapped client remain reachable.
+type Wrapper interface {
+	Unwrap() Client
+}
+
+// AsPresigner returns the Presigner implemented by c or by any Client it
+// wraps.
+func AsPresigner(c Client) (Presigner, bool) {
+	for c != nil {
+		if p, ok := c.(Presigner); ok {
+			return p, true
+		}
+		w, ok := c.(Wrapper)
+		if !ok {
+			break
+		}
+		c = w.Unwrap()
+	}
+	return nil, false
+}
@@ -2,15 +2,33 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
-
-	"github.com/minio/minio-go/v7"
+	"slices"
+	"time"
 )
 
 // ObjectInfo contains metadata about an object in storage.
 type ObjectInfo struct {
-	Size int64
-	// You can add other metadata fields like ContentType, ETag, LastModified, etc.
+	Key          string
+	Size         int64
+	ETag         string
+	ContentType  string
+	LastModified time.Time
+	// Metadata holds the user-defined metadata stored alongside the object.
+	// Keys are in canonical header form (e.g. "Fs-Encryption").
+	Metadata map[string]string
+	// Tags holds the object's tags.
+	Tags map[string]string
+	// VersionID identifies this version of the object in a versioned bucket.
+	// It is empty when versioning is disabled.
+	VersionID string
+	// IsLatest reports whether this is the current version of the object.
+	// It is not reported (false) when GetObjectInfo is asked for a specific version.
+	IsLatest bool
+	// IsDeleteMarker reports whether this version marks the object as deleted.
+	IsDeleteMarker bool
 }
 
 // GetObjectOptions defines optional parameters for retrieving an object
@@ -22,6 +40,57 @@ type ObjectInfo struct {
 type GetObjectOptions struct {
 	Start int64 // Starting byte offset (inclusive)
 	End   int64 // Ending byte offset (inclusive)
+	// VersionID selects a specific version; empty means the latest version.
+	VersionID string
+	// MatchETag, when set, makes the call fail with ErrModified unless the
+	// object still has this ETag, so that reads made of several requests
+	// never mix two versions of an object.
+	MatchETag string
+}
+
+// GetObjectInfoOptions defines optional parameters for retrieving object metadata.
+type GetObjectInfoOptions struct {
+	// VersionID selects a specific version; empty means the latest version.
+	VersionID string
+}
+
+// ListObjectsOptions defines optional parameters for listing objects.
+type ListObjectsOptions struct {
+	// Prefix restricts the listing to objects whose name starts with Prefix.
+	Prefix string
+	// WithVersions includes every version of each object, newest first.
+	WithVersions bool
+}
+
+// RemoveObjectOptions defines optional parameters for removing an object.
+type RemoveObjectOptions struct {
+	// VersionID permanently removes a specific version. When empty, the
+	// latest version is removed, which in a versioned bucket only adds a
+	// delete marker.
+	VersionID string
+}
+
+// CopyObjectOptions defines optional parameters for copying an object.
+type CopyObjectOptions struct {
+	// SrcVersionID selects the version of the source object to copy.
+	SrcVersionID string
+}
+
+// PutObjectOptions defines optional parameters for storing an object.
+type PutObjectOptions struct {
+	ContentType string
+	// Metadata is user-defined metadata stored alongside the object.
+	Metadata map[string]string
+	// Tags are key/value tags attached to the object, e.g. for lifecycle rules.
+	Tags map[string]string
+}
+
+// IncompleteUpload describes a multipart upload that was started but never
+// completed or aborted.
+type IncompleteUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
 }
 
 // Client defines the interface for interacting with an object storage service,
@@ -39,11 +108,11 @@ type Client interface {
 	// Returns an error if the existence check could not be performed.
 	DoesBucketExists(ctx context.Context, bucketName string) (bool, error)
 
-	// GetObject retrieves the full object from the bucket.
+	// GetObject retrieves the object, or the byte range of it selected by
+	// opts, from the bucket.
 	//
-	// Returns a MinIO object (*minio.Object) to allow reading and other MinIO-specific operations.
 	// Returns an error if the object does not exist or cannot be accessed.
-	GetObject(ctx context.Context, bucketName, objectName string, opts GetObjectOptions) (*minio.Object, error)
+	GetObject(ctx context.Context, bucketName, objectName string, opts GetObjectOptions) (io.ReadCloser, error)
 
 	// GetObjectWithRange retrieves a portion of the object using start and end byte offsets.
 	//
@@ -56,19 +125,144 @@ type Client interface {
 	//
 	// Returns an ObjectInfo containing the object's size and other optional metadata.
 	// Returns an error if the object does not exist or cannot be accessed.
-	GetObjectInfo(ctx context.Context, bucketName, objectName string) (ObjectInfo, error)
+	GetObjectInfo(ctx context.Context, bucketName, objectName string, opts GetObjectInfoOptions) (ObjectInfo, error)
+
+	// PutObject stores the contents of reader as objectName in the bucket.
+	//
+	// size is the number of bytes in reader, or -1 if unknown, in which case
+	// the object is uploaded in parts until reader returns io.EOF.
+	// Returns the ObjectInfo of the stored object.
+	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, size int64, opts PutObjectOptions) (ObjectInfo, error)
+
+	// ListObjects lists the objects in the bucket, recursively.
+	//
+	// When opts.WithVersions is set every version of every object is returned,
+	// newest first, including delete markers.
+	ListObjects(ctx context.Context, bucketName string, opts ListObjectsOptions) ([]ObjectInfo, error)
+
+	// CopyObject copies srcObjectName to dstObjectName within the bucket,
+	// preserving its metadata.
+	//
+	// Returns the ObjectInfo of the new object.
+	CopyObject(ctx context.Context, bucketName, srcObjectName, dstObjectName string, opts CopyObjectOptions) (ObjectInfo, error)
+
+	// RemoveObject removes an object (or one version of it) from the bucket.
+	//
+	// Removing an object that does not exist is not an error.
+	RemoveObject(ctx context.Context, bucketName, objectName string, opts RemoveObjectOptions) error
+
+	// ListIncompleteUploads lists the multipart uploads in the bucket under
+	// prefix that were never completed or aborted.
+	ListIncompleteUploads(ctx context.Context, bucketName, prefix string) ([]IncompleteUpload, error)
+
+	// AbortIncompleteUpload aborts a multipart upload and frees its parts.
+	AbortIncompleteUpload(ctx context.Context, bucketName, objectName, uploadID string) error
+
+	// EnableVersioning turns on versioning for the bucket so that overwriting
+	// an object keeps its previous versions.
+	EnableVersioning(ctx context.Context, bucketName string) error
+}
+
+// Role is the role of an endpoint in a multi-endpoint client.
+type Role string
+
+const (
+	// RolePrimary endpoints receive the writes.
+	RolePrimary Role = "primary"
+	// RoleReplica endpoints serve reads, and writes when no primary is
+	// reachable.
+	RoleReplica Role = "replica"
+)
+
+// Endpoint describes a MinIO endpoint.
+type Endpoint struct {
+	// Name identifies the endpoint in logs and metrics.
+	Name            string
+	Host            string
+	AccessKeyID     string
+	SecretAccessKey string
+	UseSSL          bool
+	Role            Role
+}
+
+// Option configures NewStorageClient.
+type Option func(*options)
+
+type options struct {
+	wrap       func(Endpoint, Client) Client
+	hedgeDelay time.Duration
+	observe    func(RoutingEvent)
+}
+
+// RoutingEvent is a decision taken when routing a call between endpoints.
+type RoutingEvent string
+
+const (
+	// RoutingFailover is a call retried on another endpoint after the one
+	// tried failed.
+	RoutingFailover RoutingEvent = "failover"
+	// RoutingHedgedRead is a read duplicated to another endpoint because
+	// the first one was slow to answer.
+	RoutingHedgedRead RoutingEvent = "hedged_read"
+	// RoutingHedgeWin is a hedged read won by the duplicate request.
+	RoutingHedgeWin RoutingEvent = "hedge_win"
+)
+
+// WithEndpointWrapper decorates the client of every endpoint with wrap, so
+// that, e.g., each endpoint is instrumented on its own.
+func WithEndpointWrapper(wrap func(Endpoint, Client) Client) Option {
+	return func(o *options) {
+		o.wrap = wrap
+	}
+}
+
+// WithHedgeDelay makes reads still waiting for an endpoint after delay be
+// duplicated to the next best endpoint, using whichever answers first.
+// Zero, the default, disables hedging.
+func WithHedgeDelay(delay time.Duration) Option {
+	return func(o *options) {
+		o.hedgeDelay = delay
+	}
+}
+
+// WithRoutingObserver makes observe be called with every routing event of
+// a client of several endpoints, so that, e.g., failovers are counted.
+func WithRoutingObserver(observe func(RoutingEvent)) Option {
+	return func(o *options) {
+		o.observe = observe
+	}
 }
 
-func NewStorageClient(
-	endpoint string,
-	accessKeyID string,
-	secretAccessKey string,
-	useSSL bool,
-) (Client, error) {
-	return newClient(
-		endpoint,
-		accessKeyID,
-		secretAccessKey,
-		useSSL,
-	)
+// NewStorageClient returns a client of the given endpoints. With several
+// endpoints, reads go to the healthiest, fastest one and writes to the
+// primary ones, failing over to the others when they are unreachable.
+func NewStorageClient(endpoints []Endpoint, opts ...Option) (Client, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if !slices.ContainsFunc(endpoints, func(e Endpoint) bool { return e.Role == RolePrimary }) {
+		return nil, errors.New("storage: no primary endpoint")
+	}
+	members := make([]*member, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		blob, err := newClient(
+			endpoint.Host,
+			endpoint.AccessKeyID,
+			endpoint.SecretAccessKey,
+			endpoint.UseSSL,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("storage endpoint %q: %w", endpoint.Name, err)
+		}
+		var client Client = blob
+		if o.wrap != nil {
+			client = o.wrap(endpoint, client)
+		}
+		members = append(members, &member{endpoint: endpoint, client: client})
+	}
+	if len(members) == 1 {
+		return members[0].client, nil
+	}
+	return &multiClient{members: members, hedgeDelay: o.hedgeDelay, observe: o.observe}, nil
 }
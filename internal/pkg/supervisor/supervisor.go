@@ -0,0 +1,209 @@
+// Package supervisor runs the servers of the process under a single
+// lifecycle: they start together, report ready together, and on shutdown
+// stop taking new work, drain in-flight requests and streams across all of
+// them within a grace period, then force-close what remains.
+package supervisor
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/listener"
+)
+
+// Supervisor runs a set of HTTP servers.
+type Supervisor struct {
+	grace      time.Duration
+	drainDelay time.Duration
+	servers    []namedServer
+	ready      atomic.Bool
+	inFlight   tracker
+	// requests is the base context of every request. It is only canceled
+	// when the grace period expires, so that in-flight streams can finish.
+	requests context.Context
+	abort    context.CancelFunc
+}
+
+type namedServer struct {
+	name      string
+	server    *http.Server
+	addresses []string
+}
+
+// boundListener is a listener opened for a server.
+type boundListener struct {
+	server   namedServer
+	listener net.Listener
+	tls      bool
+}
+
+// New returns a Supervisor. On shutdown it reports not ready, waits
+// drainDelay so that load balancers stop routing to the process, then
+// allows in-flight requests up to grace to finish.
+func New(grace, drainDelay time.Duration) *Supervisor {
+	requests, abort := context.WithCancel(context.Background())
+	return &Supervisor{
+		grace:      grace,
+		drainDelay: drainDelay,
+		requests:   requests,
+		abort:      abort,
+	}
+}
+
+// Add registers server to be run under name on each of addresses (see
+// listener.Listen), or on its Addr when none is given. Its handler is
+// wrapped so that its requests, including hijacked HTTP/2 cleartext
+// streams, are drained on shutdown.
+func (s *Supervisor) Add(name string, server *http.Server, addresses ...string) {
+	if len(addresses) == 0 {
+		addresses = []string{server.Addr}
+	}
+	server.Handler = s.inFlight.track(server.Handler)
+	server.BaseContext = func(net.Listener) context.Context { return s.requests }
+	s.servers = append(s.servers, namedServer{name: name, server: server, addresses: addresses})
+}
+
+// Ready reports whether every server is listening and the process is not
+// shutting down.
+func (s *Supervisor) Ready() bool {
+	return s.ready.Load()
+}
+
+// Run starts every server and blocks until ctx is canceled or a server
+// fails, then shuts them all down. It returns the first server error.
+func (s *Supervisor) Run(ctx context.Context) error {
+	var listeners []boundListener
+	for _, ns := range s.servers {
+		for _, address := range ns.addresses {
+			ln, err := listener.Listen(address)
+			if err != nil {
+				for _, l := range listeners {
+					l.listener.Close()
+				}
+				return fmt.Errorf("%s server: %w", ns.name, err)
+			}
+			// Serve sets a TLSConfig for HTTP/2 on servers without one, so
+			// whether to terminate TLS is decided before serving.
+			bound := boundListener{server: ns, listener: ln, tls: ns.server.TLSConfig != nil}
+			if bound.tls {
+				bound.listener = tls.NewListener(ln, ns.server.TLSConfig)
+			}
+			listeners = append(listeners, bound)
+		}
+	}
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() {
+			slog.Info("server listening",
+				"server", l.server.name,
+				"address", l.listener.Addr().String(),
+				"tls", l.tls,
+			)
+			if err := l.server.server.Serve(l.listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errs <- fmt.Errorf("%s server: %w", l.server.name, err)
+			}
+		}()
+	}
+	s.ready.Store(true)
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		slog.Info("shutting down")
+	case runErr = <-errs:
+		slog.Error("server failed; shutting down", "error", runErr)
+	}
+	s.ready.Store(false)
+	if runErr == nil && s.drainDelay > 0 {
+		// Keep serving while load balancers notice the process is not
+		// ready anymore.
+		time.Sleep(s.drainDelay)
+	}
+	s.shutdown()
+	return runErr
+}
+
+// shutdown closes the listeners and waits up to the grace period for
+// in-flight requests, then cancels and force-closes the remaining ones.
+func (s *Supervisor) shutdown() {
+	graceCtx, cancel := context.WithTimeout(context.Background(), s.grace)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, ns := range s.servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := ns.server.Shutdown(graceCtx); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				slog.Error("server shutdown", "server", ns.name, "error", err)
+			}
+		}()
+	}
+	wg.Wait()
+	select {
+	case <-s.inFlight.idle():
+		slog.Info("drained in-flight requests")
+	case <-graceCtx.Done():
+		slog.Warn("grace period expired; aborting in-flight requests",
+			"grace", s.grace,
+			"in_flight", s.inFlight.count(),
+		)
+		s.abort()
+		for _, ns := range s.servers {
+			ns.server.Close()
+		}
+	}
+}
+
+// tracker counts in-flight requests.
+type tracker struct {
+	mu     sync.Mutex
+	n      int
+	zeroCh chan struct{}
+}
+
+func (t *tracker) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.add(1)
+		defer t.add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (t *tracker) add(delta int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.n += delta
+	if t.n == 0 && t.zeroCh != nil {
+		close(t.zeroCh)
+		t.zeroCh = nil
+	}
+}
+
+func (t *tracker) count() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.n
+}
+
+// idle returns a channel closed once no request is in flight.
+func (t *tracker) idle() <-chan struct{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch := make(chan struct{})
+	if t.n == 0 {
+		close(ch)
+		return ch
+	}
+	if t.zeroCh == nil {
+		t.zeroCh = make(chan struct{})
+	}
+	return t.zeroCh
+}
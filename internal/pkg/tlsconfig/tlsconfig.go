@@ -0,0 +1,206 @@
+// Package tlsconfig builds the TLS configuration of the server listeners
+// from certificate files, reloading the certificates when the files change
+// so that they can be rotated without a restart.
+package tlsconfig
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDelay batches the file events of a rotation, which usually
+// replaces the certificate and the key one after the other.
+const reloadDelay = 500 * time.Millisecond
+
+// Config describes the TLS configuration of a listener.
+type Config struct {
+	// CertFile and KeyFile hold the PEM encoded certificate chain and
+	// private key of the server.
+	CertFile string
+	KeyFile  string
+	// MinVersion is the minimum TLS version accepted: "1.2" (the default)
+	// or "1.3".
+	MinVersion string
+	// CipherSuites restricts the TLS 1.2 cipher suites to the named ones,
+	// e.g. "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256". Go's defaults are used
+	// when empty. TLS 1.3 suites are not configurable.
+	CipherSuites []string
+	// ClientCAFile holds the CAs whose client certificates are verified
+	// during the handshake. Clients without a certificate are still
+	// accepted, and left to the other authenticators.
+	ClientCAFile string
+	// RequestClientCert asks clients for a certificate without verifying
+	// it, leaving that to the mTLS authenticator. It is ignored when
+	// ClientCAFile is set.
+	RequestClientCert bool
+}
+
+// Load returns a server TLS configuration negotiating HTTP/2 or HTTP/1.1
+// through ALPN. The certificate, key and client CA files are watched until
+// ctx is canceled; a change that fails to load is logged and the previous
+// certificates are kept.
+func Load(ctx context.Context, config Config) (*tls.Config, error) {
+	if config.CertFile == "" || config.KeyFile == "" {
+		return nil, errors.New("TLS requires both a certificate and a key file")
+	}
+	minVersion, err := parseVersion(config.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	cipherSuites, err := parseCipherSuites(config.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	base := &tls.Config{
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+	switch {
+	case config.ClientCAFile != "":
+		base.ClientAuth = tls.VerifyClientCertIfGiven
+	case config.RequestClientCert:
+		base.ClientAuth = tls.RequestClientCert
+	}
+	r := &reloader{config: config, base: base}
+	if err := r.reload(); err != nil {
+		return nil, err
+	}
+	if err := r.watch(ctx); err != nil {
+		return nil, err
+	}
+	// The outer configuration only selects the current one per handshake,
+	// but http.Server looks at its NextProtos to decide to serve HTTP/2.
+	return &tls.Config{
+		MinVersion: minVersion,
+		NextProtos: base.NextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return r.current.Load(), nil
+		},
+	}, nil
+}
+
+// reloader holds the current configuration, rebuilt from the files on
+// every change.
+type reloader struct {
+	config  Config
+	base    *tls.Config
+	current atomic.Pointer[tls.Config]
+}
+
+func (r *reloader) reload() error {
+	cert, err := tls.LoadX509KeyPair(r.config.CertFile, r.config.KeyFile)
+	if err != nil {
+		return fmt.Errorf("loading TLS certificate: %w", err)
+	}
+	tlsConfig := r.base.Clone()
+	tlsConfig.Certificates = []tls.Certificate{cert}
+	if r.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("reading TLS client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.New("TLS client CA file holds no certificates")
+		}
+		tlsConfig.ClientCAs = pool
+	}
+	r.current.Store(tlsConfig)
+	return nil
+}
+
+// watch reloads the configuration when one of its files changes. The
+// directories are watched rather than the files, so that files replaced by
+// a rename or a symlink swap (as with Kubernetes secrets) are picked up.
+func (r *reloader) watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("watching TLS certificates: %w", err)
+	}
+	files := []string{r.config.CertFile, r.config.KeyFile}
+	if r.config.ClientCAFile != "" {
+		files = append(files, r.config.ClientCAFile)
+	}
+	watched := make(map[string]bool)
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if watched[dir] {
+			continue
+		}
+		if err := watcher.Add(dir); err != nil {
+			watcher.Close()
+			return fmt.Errorf("watching TLS certificates: %w", err)
+		}
+		watched[dir] = true
+	}
+	go func() {
+		defer watcher.Close()
+		var pending <-chan time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-watcher.Events:
+				if event.Has(fsnotify.Chmod) {
+					continue
+				}
+				pending = time.After(reloadDelay)
+			case err := <-watcher.Errors:
+				slog.Error("watching TLS certificates", "error", err)
+			case <-pending:
+				pending = nil
+				if err := r.reload(); err != nil {
+					slog.Error("keeping previous TLS certificates", "error", err)
+					continue
+				}
+				slog.Info("TLS certificates reloaded", "cert_file", r.config.CertFile)
+			}
+		}
+	}()
+	return nil
+}
+
+func parseVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unsupported minimum TLS version %q", version)
+	}
+}
+
+// parseCipherSuites returns the IDs of the named cipher suites. Suites with
+// known security issues are refused.
+func parseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	byName := make(map[string]uint16)
+	for _, suite := range tls.CipherSuites() {
+		byName[suite.Name] = suite.ID
+	}
+	var ids []uint16
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		id, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown or insecure TLS cipher suite %q", name)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
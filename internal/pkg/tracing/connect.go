@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"connectrpc.com/connect"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Interceptor starts a server span for every RPC, unary and streaming,
+// continuing the trace context in the request headers.
+type Interceptor struct{}
+
+// Compile-time check to ensure Interceptor implements connect.Interceptor.
+var _ connect.Interceptor = (*Interceptor)(nil)
+
+// NewInterceptor returns an Interceptor.
+func NewInterceptor() *Interceptor {
+	return &Interceptor{}
+}
+
+// WrapUnary implements connect.Interceptor.
+func (i *Interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		ctx, span := startRPC(ctx, req.Spec().Procedure, req.Header())
+		defer span.End()
+		res, err := next(ctx, req)
+		endRPC(span, err)
+		return res, err
+	}
+}
+
+// WrapStreamingClient implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+// WrapStreamingHandler implements connect.Interceptor.
+func (i *Interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		ctx, span := startRPC(ctx, conn.Spec().Procedure, conn.RequestHeader())
+		defer span.End()
+		err := next(ctx, conn)
+		endRPC(span, err)
+		return err
+	}
+}
+
+// startRPC starts the span of the RPC to procedure, e.g.
+// "/transfer.v1.TransferService/StreamFile".
+func startRPC(ctx context.Context, procedure string, header http.Header) (context.Context, trace.Span) {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(header))
+	name := strings.TrimPrefix(procedure, "/")
+	service, method, _ := strings.Cut(name, "/")
+	return tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			semconv.RPCSystemKey.String("connect_rpc"),
+			semconv.RPCService(service),
+			semconv.RPCMethod(method),
+		),
+	)
+}
+
+// endRPC records the outcome of an RPC on its span.
+func endRPC(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.SetAttributes(attribute.String("rpc.connect_rpc.error_code", connect.CodeOf(err).String()))
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,86 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// progressInterval is how many bytes pass between "transfer.progress"
+// events.
+const progressInterval = 1024 * 1024 // 1mb
+
+// Progress records the milestones of a transfer as events on the span in
+// its context: "transfer.first_byte", "transfer.progress" every
+// progressInterval bytes and "transfer.complete".
+type Progress struct {
+	span         trace.Span
+	transferred  int64
+	lastProgress int64
+}
+
+// NewProgress returns a Progress recording on the span of ctx.
+func NewProgress(ctx context.Context) *Progress {
+	return &Progress{span: trace.SpanFromContext(ctx)}
+}
+
+// Add records that n more bytes were transferred.
+func (p *Progress) Add(n int) {
+	if n <= 0 {
+		return
+	}
+	if p.transferred == 0 {
+		p.span.AddEvent("transfer.first_byte")
+	}
+	p.transferred += int64(n)
+	if p.transferred-p.lastProgress >= progressInterval {
+		p.lastProgress = p.transferred
+		p.span.AddEvent("transfer.progress", trace.WithAttributes(attribute.Int64("transfer.bytes", p.transferred)))
+	}
+}
+
+// Complete records that the transfer finished.
+func (p *Progress) Complete() {
+	p.span.AddEvent("transfer.complete", trace.WithAttributes(attribute.Int64("transfer.bytes", p.transferred)))
+}
+
+// Reader returns r recording its progress, completing on io.EOF.
+func (p *Progress) Reader(r io.Reader) io.Reader {
+	return &progressReader{r: r, progress: p}
+}
+
+// Writer returns w recording its progress. Complete must be called once the
+// transfer finished.
+func (p *Progress) Writer(w io.Writer) io.Writer {
+	return &progressWriter{w: w, progress: p}
+}
+
+type progressReader struct {
+	r        io.Reader
+	progress *Progress
+	done     bool
+}
+
+func (r *progressReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.progress.Add(n)
+	if errors.Is(err, io.EOF) && !r.done {
+		r.done = true
+		r.progress.Complete()
+	}
+	return n, err
+}
+
+type progressWriter struct {
+	w        io.Writer
+	progress *Progress
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.progress.Add(n)
+	return n, err
+}
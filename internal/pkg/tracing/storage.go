@@ -0,0 +1,200 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// storageClient records a child span for every call to a storage backend.
+// For reads, the span covers opening the object, not reading it.
+type storageClient struct {
+	client  storage.Client
+	backend string
+}
+
+// Compile-time check to ensure storageClient implements storage.Client.
+var _ storage.Client = (*storageClient)(nil)
+
+// NewStorageClient wraps client so that its calls are traced, tagged with
+// the backend name.
+func NewStorageClient(client storage.Client, backend string) storage.Client {
+	return &storageClient{client: client, backend: backend}
+}
+
+// Unwrap returns the traced client.
+func (s *storageClient) Unwrap() storage.Client {
+	return s.client
+}
+
+// start starts the span of a call to method on bucket.
+func (s *storageClient) start(ctx context.Context, method, bucket string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs,
+		attribute.String("storage.backend", s.backend),
+		attribute.String("storage.bucket", bucket),
+	)
+	return tracer().Start(ctx, "storage."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// end records err, if any, and ends span.
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, storage.ErrBucketAlreadyExists) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func object(name string) attribute.KeyValue {
+	return attribute.String("storage.object", name)
+}
+
+func (s *storageClient) CreateBucket(ctx context.Context, bucketName string) error {
+	ctx, span := s.start(ctx, "CreateBucket", bucketName)
+	err := s.client.CreateBucket(ctx, bucketName)
+	end(span, err)
+	return err
+}
+
+func (s *storageClient) DoesBucketExists(ctx context.Context, bucketName string) (bool, error) {
+	ctx, span := s.start(ctx, "DoesBucketExists", bucketName)
+	exists, err := s.client.DoesBucketExists(ctx, bucketName)
+	end(span, err)
+	return exists, err
+}
+
+func (s *storageClient) GetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectOptions,
+) (io.ReadCloser, error) {
+	ctx, span := s.start(ctx, "GetObject", bucketName, object(objectName),
+		attribute.Int64("storage.range.start", opts.Start),
+		attribute.Int64("storage.range.end", opts.End),
+	)
+	obj, err := s.client.GetObject(ctx, bucketName, objectName, opts)
+	end(span, err)
+	return obj, err
+}
+
+func (s *storageClient) GetObjectWithRange(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	start int64,
+	endOffset int64,
+) (io.ReadCloser, error) {
+	ctx, span := s.start(ctx, "GetObjectWithRange", bucketName, object(objectName),
+		attribute.Int64("storage.range.start", start),
+		attribute.Int64("storage.range.end", endOffset),
+	)
+	obj, err := s.client.GetObjectWithRange(ctx, bucketName, objectName, start, endOffset)
+	end(span, err)
+	return obj, err
+}
+
+func (s *storageClient) GetObjectInfo(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectInfoOptions,
+) (storage.ObjectInfo, error) {
+	ctx, span := s.start(ctx, "GetObjectInfo", bucketName, object(objectName))
+	info, err := s.client.GetObjectInfo(ctx, bucketName, objectName, opts)
+	end(span, err)
+	return info, err
+}
+
+func (s *storageClient) PutObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	reader io.Reader,
+	size int64,
+	opts storage.PutObjectOptions,
+) (storage.ObjectInfo, error) {
+	ctx, span := s.start(ctx, "PutObject", bucketName, object(objectName))
+	info, err := s.client.PutObject(ctx, bucketName, objectName, reader, size, opts)
+	if err == nil {
+		span.SetAttributes(attribute.Int64("storage.object.size", info.Size))
+	}
+	end(span, err)
+	return info, err
+}
+
+func (s *storageClient) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts storage.ListObjectsOptions,
+) ([]storage.ObjectInfo, error) {
+	ctx, span := s.start(ctx, "ListObjects", bucketName, attribute.String("storage.prefix", opts.Prefix))
+	objects, err := s.client.ListObjects(ctx, bucketName, opts)
+	end(span, err)
+	return objects, err
+}
+
+func (s *storageClient) CopyObject(
+	ctx context.Context,
+	bucketName string,
+	srcObjectName string,
+	dstObjectName string,
+	opts storage.CopyObjectOptions,
+) (storage.ObjectInfo, error) {
+	ctx, span := s.start(ctx, "CopyObject", bucketName, object(srcObjectName),
+		attribute.String("storage.destination", dstObjectName),
+	)
+	info, err := s.client.CopyObject(ctx, bucketName, srcObjectName, dstObjectName, opts)
+	end(span, err)
+	return info, err
+}
+
+func (s *storageClient) RemoveObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.RemoveObjectOptions,
+) error {
+	ctx, span := s.start(ctx, "RemoveObject", bucketName, object(objectName))
+	err := s.client.RemoveObject(ctx, bucketName, objectName, opts)
+	end(span, err)
+	return err
+}
+
+func (s *storageClient) ListIncompleteUploads(
+	ctx context.Context,
+	bucketName string,
+	prefix string,
+) ([]storage.IncompleteUpload, error) {
+	ctx, span := s.start(ctx, "ListIncompleteUploads", bucketName, attribute.String("storage.prefix", prefix))
+	uploads, err := s.client.ListIncompleteUploads(ctx, bucketName, prefix)
+	end(span, err)
+	return uploads, err
+}
+
+func (s *storageClient) AbortIncompleteUpload(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	uploadID string,
+) error {
+	ctx, span := s.start(ctx, "AbortIncompleteUpload", bucketName, object(objectName))
+	err := s.client.AbortIncompleteUpload(ctx, bucketName, objectName, uploadID)
+	end(span, err)
+	return err
+}
+
+func (s *storageClient) EnableVersioning(ctx context.Context, bucketName string) error {
+	ctx, span := s.start(ctx, "EnableVersioning", bucketName)
+	err := s.client.EnableVersioning(ctx, bucketName)
+	end(span, err)
+	return err
+}
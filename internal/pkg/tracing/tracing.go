@@ -0,0 +1,100 @@
+// Package tracing sets up OpenTelemetry distributed tracing. Every HTTP
+// request and Connect RPC gets a server span continuing the W3C trace context
+// sent by the client, storage calls get child spans, and transfers record
+// span events as their bytes flow.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters spans can be sent to.
+const (
+	ExporterNone   = ""
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+const (
+	serviceName = "file-streamer"
+	// instrumentationName names the tracer of this package.
+	instrumentationName = "github.com/gilwong00/file-streamer/internal/pkg/tracing"
+)
+
+// Config selects where spans are exported.
+type Config struct {
+	// Exporter is ExporterOTLP, ExporterStdout or ExporterNone to disable
+	// tracing.
+	Exporter string
+	// Endpoint is the host:port of the OTLP/HTTP collector.
+	Endpoint string
+	// Insecure sends spans to the collector over plain HTTP.
+	Insecure bool
+	// SampleRatio is the fraction of new traces recorded; traces started by
+	// clients follow the client's sampling decision.
+	SampleRatio float64
+}
+
+// Setup installs the global tracer provider and W3C trace context
+// propagator, returning the function flushing and stopping the exporter.
+// With ExporterNone spans are not recorded, but trace context is still
+// propagated.
+func Setup(ctx context.Context, config Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch config.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{}
+		if config.Endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(config.Endpoint))
+		}
+		if config.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", config.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("creating %s trace exporter: %w", config.Exporter, err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("creating trace resource: %w", err)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// tracer returns the tracer of the global provider, so that spans follow
+// whatever provider Setup installed.
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
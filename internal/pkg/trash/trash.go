@@ -0,0 +1,240 @@
+// Package trash implements soft deletion. Deleted files are moved into a
+// reserved area of their namespace, from which they can be restored until
+// the namespace's retention window passes and the purger removes them.
+package trash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+var (
+	// ErrNotFound is returned when no matching trash entry exists.
+	ErrNotFound = errors.New("trash entry not found")
+	// ErrFileExists is returned when undeleting over a file that exists again.
+	ErrFileExists = errors.New("file already exists")
+)
+
+// Entry is a deleted file held in the trash.
+type Entry struct {
+	// ID identifies the deletion; a file deleted several times has one
+	// entry per deletion.
+	ID        string
+	FileName  string
+	Size      int64
+	DeletedAt time.Time
+	ExpiresAt time.Time
+}
+
+// Trash manages the trash area of every namespace.
+type Trash struct {
+	storageClient storage.Client
+	namespaces    *namespace.Registry
+	now           func() time.Time
+}
+
+// New returns a Trash storing deleted files through storageClient.
+func New(storageClient storage.Client, namespaces *namespace.Registry) *Trash {
+	return &Trash{
+		storageClient: storageClient,
+		namespaces:    namespaces,
+		now:           time.Now,
+	}
+}
+
+// key returns the object key of a trash entry. The deletion time comes last
+// so that file names containing slashes can still be parsed back.
+func key(fileName, id string) string {
+	return fileutils.TrashPrefix + fileName + "/" + id
+}
+
+// parseKey splits a trash object key into its file name and entry ID.
+func parseKey(objectKey string) (fileName, id string, deletedAt time.Time, ok bool) {
+	rest, ok := strings.CutPrefix(objectKey, fileutils.TrashPrefix)
+	if !ok {
+		return "", "", time.Time{}, false
+	}
+	i := strings.LastIndex(rest, "/")
+	if i <= 0 {
+		return "", "", time.Time{}, false
+	}
+	nanos, err := strconv.ParseInt(rest[i+1:], 10, 64)
+	if err != nil {
+		return "", "", time.Time{}, false
+	}
+	return rest[:i], rest[i+1:], time.Unix(0, nanos), true
+}
+
+// Delete moves fileName into the trash of ns and returns its trash entry.
+func (t *Trash) Delete(ctx context.Context, ns namespace.Namespace, fileName string) (Entry, error) {
+	info, err := t.storageClient.GetObjectInfo(ctx, ns.Bucket, fileName, storage.GetObjectInfoOptions{})
+	if err != nil {
+		return Entry{}, err
+	}
+	deletedAt := t.now()
+	id := strconv.FormatInt(deletedAt.UnixNano(), 10)
+	if _, err := t.storageClient.CopyObject(ctx, ns.Bucket, fileName, key(fileName, id), storage.CopyObjectOptions{
+		SrcVersionID: info.VersionID,
+	}); err != nil {
+		return Entry{}, fmt.Errorf("moving %q to trash: %w", fileName, err)
+	}
+	if err := t.storageClient.RemoveObject(ctx, ns.Bucket, fileName, storage.RemoveObjectOptions{}); err != nil {
+		return Entry{}, fmt.Errorf("removing %q: %w", fileName, err)
+	}
+	return Entry{
+		ID:        id,
+		FileName:  fileName,
+		Size:      info.Size,
+		DeletedAt: deletedAt,
+		ExpiresAt: deletedAt.Add(ns.TrashRetention),
+	}, nil
+}
+
+// List returns the entries in the trash of ns, most recently deleted first.
+func (t *Trash) List(ctx context.Context, ns namespace.Namespace) ([]Entry, error) {
+	objects, err := t.storageClient.ListObjects(ctx, ns.Bucket, storage.ListObjectsOptions{
+		Prefix: fileutils.TrashPrefix,
+	})
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(objects))
+	for _, object := range objects {
+		fileName, id, deletedAt, ok := parseKey(object.Key)
+		if !ok {
+			continue
+		}
+		entries = append(entries, Entry{
+			ID:        id,
+			FileName:  fileName,
+			Size:      object.Size,
+			DeletedAt: deletedAt,
+			ExpiresAt: deletedAt.Add(ns.TrashRetention),
+		})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].DeletedAt.After(entries[j].DeletedAt) })
+	return entries, nil
+}
+
+// Undelete restores fileName from the trash of ns. When id is empty the most
+// recent deletion of the file is restored. It fails with ErrFileExists if a
+// file with the same name has been uploaded since.
+func (t *Trash) Undelete(ctx context.Context, ns namespace.Namespace, fileName, id string) (storage.ObjectInfo, error) {
+	entries, err := t.List(ctx, ns)
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	var entry *Entry
+	for i := range entries {
+		if entries[i].FileName == fileName && (id == "" || entries[i].ID == id) {
+			entry = &entries[i]
+			break
+		}
+	}
+	if entry == nil {
+		return storage.ObjectInfo{}, ErrNotFound
+	}
+	_, err = t.storageClient.GetObjectInfo(ctx, ns.Bucket, fileName, storage.GetObjectInfoOptions{})
+	switch {
+	case err == nil:
+		return storage.ObjectInfo{}, ErrFileExists
+	case !storage.IsNotFound(err):
+		// The file may exist: restoring could overwrite it.
+		return storage.ObjectInfo{}, err
+	}
+	info, err := t.storageClient.CopyObject(ctx, ns.Bucket, key(fileName, entry.ID), fileName, storage.CopyObjectOptions{})
+	if err != nil {
+		return storage.ObjectInfo{}, fmt.Errorf("restoring %q: %w", fileName, err)
+	}
+	if err := t.remove(ctx, ns, key(fileName, entry.ID)); err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	return info, nil
+}
+
+// Purge permanently removes every trash entry whose retention window has
+// passed, across all namespaces, and returns how many were removed.
+func (t *Trash) Purge(ctx context.Context) (int, error) {
+	var purged int
+	var errs []error
+	now := t.now()
+	for _, ns := range t.namespaces.List() {
+		entries, err := t.List(ctx, ns)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("namespace %q: %w", ns.Name, err))
+			continue
+		}
+		for _, entry := range entries {
+			if entry.ExpiresAt.After(now) {
+				continue
+			}
+			if err := t.remove(ctx, ns, key(entry.FileName, entry.ID)); err != nil {
+				errs = append(errs, fmt.Errorf("namespace %q: %w", ns.Name, err))
+				continue
+			}
+			purged++
+		}
+	}
+	return purged, errors.Join(errs...)
+}
+
+// RunPurger purges expired trash entries every interval until ctx is done.
+// A non-positive interval disables purging.
+func (t *Trash) RunPurger(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		slog.Warn("trash purging disabled; TRASH_PURGE_INTERVAL is not positive")
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := t.Purge(ctx)
+			if err != nil {
+				slog.Error("trash purge failed", "error", err)
+			}
+			if purged > 0 {
+				slog.Info("purged expired trash entries", "count", purged)
+			}
+		}
+	}
+}
+
+// remove permanently deletes a trash object. In versioned namespaces every
+// version is removed so that no delete marker or old copy is left behind.
+func (t *Trash) remove(ctx context.Context, ns namespace.Namespace, objectKey string) error {
+	if !ns.Versioned {
+		return t.storageClient.RemoveObject(ctx, ns.Bucket, objectKey, storage.RemoveObjectOptions{})
+	}
+	versions, err := t.storageClient.ListObjects(ctx, ns.Bucket, storage.ListObjectsOptions{
+		Prefix:       objectKey,
+		WithVersions: true,
+	})
+	if err != nil {
+		return err
+	}
+	for _, version := range versions {
+		if version.Key != objectKey {
+			continue
+		}
+		if err := t.storageClient.RemoveObject(ctx, ns.Bucket, objectKey, storage.RemoveObjectOptions{
+			VersionID: version.VersionID,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
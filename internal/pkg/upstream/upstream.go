@@ -0,0 +1,584 @@
+// Package upstream is a storage backend served by another file-streamer
+// instance, so that this binary can run at an edge site in front of an
+// origin: metadata is read with HEAD requests and revalidated with ETags,
+// content with ranged GETs pinned to an ETag, and listings with ListFiles.
+// It is read-only.
+//
+// Reads may go through an origin shield, a file-streamer instance between
+// the edges and the origin that caches content for all of them, so that a
+// burst of misses at many edges reaches the origin once.
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/gen/proto/v1/transferv1connect"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// metadataHeaderPrefix prefixes user metadata in the origin's responses.
+const metadataHeaderPrefix = "X-File-Meta-"
+
+// probeName is a file name used to check that a namespace exists.
+const probeName = "file-streamer-probe"
+
+// Options configures an upstream storage client.
+type Options struct {
+	// URL is the origin's HTTP API, e.g. "https://files.example.com".
+	URL string
+	// ConnectURL is the origin's ConnectRPC server, used for listings.
+	// It defaults to URL, for origins serving both on one port.
+	ConnectURL string
+	// ShieldURL, when set, is the HTTP API of an origin shield reads go
+	// through, falling back to the origin when it is unreachable.
+	ShieldURL string
+	// APIKey authenticates the requests made to the origin and the shield.
+	APIKey string
+	// MetadataTTL is how long the metadata of an object is trusted before
+	// it is revalidated with the origin. Zero revalidates on every use.
+	MetadataTTL time.Duration
+	// HTTPClient makes the requests; http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// client reads from an upstream file-streamer.
+type client struct {
+	opts       Options
+	httpClient *http.Client
+	rpc        transferv1connect.TransferServiceClient
+
+	mu       sync.Mutex
+	metadata map[string]*entry
+}
+
+// entry is the cached metadata of an object or version.
+type entry struct {
+	info      storage.ObjectInfo
+	checkedAt time.Time
+	// done is closed once a revalidation in flight completes; other
+	// callers wait for it instead of asking the origin again.
+	done chan struct{}
+	err  error
+}
+
+// Compile-time check to ensure client implements storage.Client.
+var _ storage.Client = (*client)(nil)
+
+// NewStorageClient returns a client of the file-streamer at opts.URL.
+func NewStorageClient(opts Options) (storage.Client, error) {
+	for _, u := range []string{opts.URL, opts.ConnectURL, opts.ShieldURL} {
+		if u == "" {
+			continue
+		}
+		if parsed, err := url.Parse(u); err != nil || parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("upstream: %q is not an absolute URL", u)
+		}
+	}
+	if opts.ConnectURL == "" {
+		opts.ConnectURL = opts.URL
+	}
+	httpClient := opts.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	c := &client{
+		opts:       opts,
+		httpClient: httpClient,
+		metadata:   make(map[string]*entry),
+	}
+	var interceptors []connect.Interceptor
+	if opts.APIKey != "" {
+		interceptors = append(interceptors, connect.UnaryInterceptorFunc(
+			func(next connect.UnaryFunc) connect.UnaryFunc {
+				return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+					req.Header().Set("X-API-Key", opts.APIKey)
+					return next(ctx, req)
+				}
+			},
+		))
+	}
+	c.rpc = transferv1connect.NewTransferServiceClient(
+		httpClient,
+		strings.TrimSuffix(opts.ConnectURL, "/"),
+		connect.WithInterceptors(interceptors...),
+	)
+	return c, nil
+}
+
+// request builds a request for the file in the namespace at the HTTP API
+// at base.
+func (c *client) request(
+	ctx context.Context,
+	method string,
+	base string,
+	namespace string,
+	fileName string,
+	versionID string,
+) (*http.Request, error) {
+	query := url.Values{"namespace": {namespace}}
+	if versionID != "" {
+		query.Set("version", versionID)
+	}
+	u := strings.TrimSuffix(base, "/") + "/file/" + url.PathEscape(fileName) + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.opts.APIKey != "" {
+		req.Header.Set("X-API-Key", c.opts.APIKey)
+	}
+	return req, nil
+}
+
+// do sends a request for the file to the shield, when configured, and to
+// the origin if the shield is unavailable.
+func (c *client) do(
+	ctx context.Context,
+	op string,
+	method string,
+	namespace string,
+	fileName string,
+	versionID string,
+	setHeaders func(http.Header),
+) (*http.Response, error) {
+	bases := []string{c.opts.URL}
+	if c.opts.ShieldURL != "" {
+		bases = []string{c.opts.ShieldURL, c.opts.URL}
+	}
+	var err error
+	for i, base := range bases {
+		var req *http.Request
+		req, err = c.request(ctx, method, base, namespace, fileName, versionID)
+		if err != nil {
+			return nil, err
+		}
+		setHeaders(req.Header)
+		metrics.RecordUpstreamRequest()
+		var resp *http.Response
+		resp, err = c.httpClient.Do(req)
+		if err == nil {
+			err = responseError(op, resp)
+			if err == nil || !storage.IsRetryable(err) {
+				return resp, err
+			}
+		} else {
+			err = storage.Translate(op, err)
+		}
+		if i < len(bases)-1 && storage.IsRetryable(err) {
+			metrics.RecordUpstreamShieldFailover()
+			logging.FromContext(ctx).Warn("origin shield failed; asking the origin", "error", err)
+			continue
+		}
+	}
+	return nil, err
+}
+
+// responseError returns the storage error matching an error response, and
+// closes its body. It returns nil for successful responses.
+func responseError(op string, resp *http.Response) error {
+	if resp.StatusCode < 400 {
+		return nil
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	message := strings.TrimSpace(string(body))
+	err := fmt.Errorf("upstream returned %s: %s", resp.Status, message)
+	var kind error
+	switch {
+	case resp.StatusCode == http.StatusNotFound && message == storage.ErrBucketNotFound.Error():
+		kind = storage.ErrBucketNotFound
+	case resp.StatusCode == http.StatusNotFound:
+		kind = storage.ErrNotFound
+	case resp.StatusCode == http.StatusBadRequest && strings.Contains(message, "unknown namespace"):
+		kind = storage.ErrBucketNotFound
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		kind = storage.ErrAccessDenied
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		kind = storage.ErrInvalidRange
+	case resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusPreconditionFailed:
+		kind = storage.ErrModified
+	case resp.StatusCode == http.StatusTooManyRequests:
+		kind = storage.ErrThrottled
+	case resp.StatusCode == http.StatusGatewayTimeout:
+		kind = storage.ErrTimeout
+	case resp.StatusCode >= 500:
+		kind = storage.ErrUnavailable
+	default:
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	return &storage.Error{Kind: kind, Op: op, Err: err}
+}
+
+// CreateBucket only checks that the origin serves the namespace: it cannot
+// create namespaces.
+func (c *client) CreateBucket(ctx context.Context, bucketName string) error {
+	exists, err := c.DoesBucketExists(ctx, bucketName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return storage.ErrBucketAlreadyExists
+	}
+	return &storage.Error{
+		Kind: storage.ErrReadOnly,
+		Op:   "make bucket",
+		Err:  fmt.Errorf("namespace %q is not served upstream", bucketName),
+	}
+}
+
+// DoesBucketExists reports whether the origin serves the namespace, by
+// asking for a file that does not exist in it.
+func (c *client) DoesBucketExists(ctx context.Context, bucketName string) (bool, error) {
+	const op = "checking if bucket exists"
+	req, err := c.request(ctx, http.MethodHead, c.opts.URL, bucketName, probeName, "")
+	if err != nil {
+		return false, err
+	}
+	metrics.RecordUpstreamRequest()
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, storage.Translate(op, err)
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound:
+		return true, nil
+	case resp.StatusCode == http.StatusBadRequest:
+		// HEAD responses carry no message: a valid file name in an unknown
+		// namespace is the only bad request.
+		return false, nil
+	}
+	return false, responseError(op, resp)
+}
+
+// GetObjectInfo returns the object's metadata, revalidating it with the
+// origin once older than the metadata TTL. Concurrent lookups of the same
+// object share one request.
+func (c *client) GetObjectInfo(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectInfoOptions,
+) (storage.ObjectInfo, error) {
+	key := metadataKey(bucketName, objectName, opts.VersionID)
+	c.mu.Lock()
+	cached := c.metadata[key]
+	if cached != nil && cached.done == nil && time.Since(cached.checkedAt) < c.opts.MetadataTTL {
+		c.mu.Unlock()
+		metrics.RecordUpstreamMetadataLookup("hit")
+		return cached.info, nil
+	}
+	if cached != nil && cached.done != nil {
+		done := cached.done
+		c.mu.Unlock()
+		metrics.RecordUpstreamMetadataLookup("coalesced")
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return storage.ObjectInfo{}, ctx.Err()
+		}
+		return cached.info, cached.err
+	}
+	pending := &entry{done: make(chan struct{})}
+	if cached != nil {
+		pending.info = cached.info
+	} else if len(c.metadata) >= maxMetadata {
+		c.evictSettled()
+	}
+	c.metadata[key] = pending
+	c.mu.Unlock()
+
+	info, err := c.stat(ctx, bucketName, objectName, opts.VersionID, pending.info.ETag)
+	c.mu.Lock()
+	if errors.Is(err, errNotModified) {
+		info, err = pending.info, nil
+	}
+	pending.info, pending.err = info, err
+	if err == nil {
+		c.metadata[key] = &entry{info: info, checkedAt: time.Now()}
+	} else {
+		delete(c.metadata, key)
+	}
+	close(pending.done)
+	c.mu.Unlock()
+	return info, err
+}
+
+// maxMetadata caps the number of objects and versions whose metadata is
+// cached.
+const maxMetadata = 1 << 16
+
+// evictSettled drops the cached metadata of every object not being looked
+// up. Starting over rather than growing without bound only costs a full
+// lookup instead of a revalidation. c.mu must be held.
+func (c *client) evictSettled() {
+	for key, cached := range c.metadata {
+		if cached.done == nil {
+			delete(c.metadata, key)
+		}
+	}
+}
+
+// metadataKey returns the key of a version of an object in c.metadata.
+func metadataKey(bucketName, objectName, versionID string) string {
+	return bucketName + "\x00" + objectName + "\x00" + versionID
+}
+
+// forget drops the cached metadata of a version of an object, unless it is
+// being looked up, so that the next lookup asks the origin.
+func (c *client) forget(bucketName, objectName, versionID string) {
+	key := metadataKey(bucketName, objectName, versionID)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached := c.metadata[key]; cached != nil && cached.done == nil {
+		delete(c.metadata, key)
+	}
+}
+
+// errNotModified is returned by stat when the object still has the ETag
+// it was revalidated against.
+var errNotModified = errors.New("not modified")
+
+// stat asks the origin for the object's metadata. When etag is set, the
+// request is conditional and errNotModified is returned if it still holds.
+func (c *client) stat(ctx context.Context, bucketName, objectName, versionID, etag string) (storage.ObjectInfo, error) {
+	resp, err := c.do(ctx, "stat object", http.MethodHead, bucketName, objectName, versionID, func(h http.Header) {
+		if etag != "" {
+			h.Set("If-None-Match", `"`+etag+`"`)
+		}
+	})
+	if err != nil {
+		return storage.ObjectInfo{}, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusNotModified {
+		metrics.RecordUpstreamMetadataLookup("revalidated")
+		return storage.ObjectInfo{}, errNotModified
+	}
+	info := storage.ObjectInfo{
+		Key:         objectName,
+		Size:        resp.ContentLength,
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+		ContentType: resp.Header.Get("Content-Type"),
+		VersionID:   resp.Header.Get("X-Version-Id"),
+		IsLatest:    versionID == "",
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.LastModified = modified
+	}
+	for name, values := range resp.Header {
+		if key, ok := strings.CutPrefix(name, metadataHeaderPrefix); ok && len(values) > 0 {
+			if info.Metadata == nil {
+				info.Metadata = make(map[string]string)
+			}
+			info.Metadata[key] = values[0]
+		}
+	}
+	return info, nil
+}
+
+// GetObject reads the object, or the byte range of it selected by opts. A
+// read finding the object modified or gone drops its cached metadata,
+// which is then stale.
+func (c *client) GetObject(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	opts storage.GetObjectOptions,
+) (io.ReadCloser, error) {
+	const op = "getting object"
+	ranged := opts.Start >= 0 && opts.End >= 0
+	if ranged && opts.Start > opts.End {
+		return nil, &storage.Error{
+			Kind: storage.ErrInvalidRange,
+			Op:   op,
+			Err:  fmt.Errorf("range %d-%d", opts.Start, opts.End),
+		}
+	}
+	resp, err := c.do(ctx, op, http.MethodGet, bucketName, objectName, opts.VersionID, func(h http.Header) {
+		if ranged {
+			h.Set("Range", fmt.Sprintf("bytes=%d-%d", opts.Start, opts.End))
+		}
+		if opts.MatchETag != "" {
+			h.Set("If-Match", `"`+opts.MatchETag+`"`)
+		}
+	})
+	if errors.Is(err, storage.ErrModified) || errors.Is(err, storage.ErrNotFound) {
+		c.forget(bucketName, objectName, opts.VersionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !ranged {
+		return resp.Body, nil
+	}
+	// The origin answers a range reaching past the end of the object with
+	// the whole object: skip to the requested start.
+	start, total, err := parseContentRange(resp.Header.Get("Content-Range"))
+	if err != nil {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if opts.Start >= total && total > 0 {
+		resp.Body.Close()
+		return nil, &storage.Error{
+			Kind: storage.ErrInvalidRange,
+			Op:   op,
+			Err:  fmt.Errorf("range %d-%d of %d bytes", opts.Start, opts.End, total),
+		}
+	}
+	if start < opts.Start {
+		if _, err := io.CopyN(io.Discard, resp.Body, opts.Start-start); err != nil {
+			resp.Body.Close()
+			return nil, storage.Translate(op, err)
+		}
+	}
+	length := min(opts.End, total-1) - opts.Start + 1
+	return &limitedBody{Reader: io.LimitReader(resp.Body, length), body: resp.Body}, nil
+}
+
+// parseContentRange parses a "bytes start-end/total" header.
+func parseContentRange(header string) (start, total int64, err error) {
+	rng, size, ok := strings.Cut(strings.TrimPrefix(header, "bytes "), "/")
+	first, _, ok2 := strings.Cut(rng, "-")
+	if !ok || !ok2 {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	if start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+		return 0, 0, fmt.Errorf("invalid Content-Range %q", header)
+	}
+	return start, total, nil
+}
+
+// limitedBody reads part of a response body and closes the body.
+type limitedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+func (l *limitedBody) Close() error {
+	return l.body.Close()
+}
+
+func (c *client) GetObjectWithRange(
+	ctx context.Context,
+	bucketName string,
+	objectName string,
+	start int64,
+	end int64,
+) (io.ReadCloser, error) {
+	return c.GetObject(ctx, bucketName, objectName, storage.GetObjectOptions{Start: start, End: end})
+}
+
+// ListObjects lists the files of the namespace with the origin's
+// ListFiles. Deleted files and the origin's trash are not listed.
+func (c *client) ListObjects(
+	ctx context.Context,
+	bucketName string,
+	opts storage.ListObjectsOptions,
+) ([]storage.ObjectInfo, error) {
+	metrics.RecordUpstreamRequest()
+	res, err := c.rpc.ListFiles(ctx, connect.NewRequest(&transferv1.ListFilesRequest{
+		Namespace:       bucketName,
+		Prefix:          opts.Prefix,
+		IncludeVersions: opts.WithVersions,
+	}))
+	if err != nil {
+		return nil, connectError("list objects", err)
+	}
+	var objects []storage.ObjectInfo
+	for _, file := range res.Msg.Files {
+		if !opts.WithVersions {
+			objects = append(objects, storage.ObjectInfo{
+				Key:          file.FileName,
+				Size:         file.Size,
+				ETag:         file.Etag,
+				LastModified: time.Unix(file.LastModified, 0),
+				VersionID:    file.VersionId,
+				IsLatest:     true,
+			})
+			continue
+		}
+		for _, version := range file.Versions {
+			objects = append(objects, storage.ObjectInfo{
+				Key:            file.FileName,
+				Size:           version.Size,
+				ETag:           version.Etag,
+				LastModified:   time.Unix(version.LastModified, 0),
+				VersionID:      version.VersionId,
+				IsLatest:       version.IsLatest,
+				IsDeleteMarker: version.IsDeleteMarker,
+			})
+		}
+	}
+	return objects, nil
+}
+
+// connectCodes maps the Connect codes of the origin's errors to storage
+// error kinds.
+var connectCodes = map[connect.Code]error{
+	connect.CodeNotFound:          storage.ErrNotFound,
+	connect.CodeInvalidArgument:   storage.ErrBucketNotFound,
+	connect.CodeUnauthenticated:   storage.ErrAccessDenied,
+	connect.CodePermissionDenied:  storage.ErrAccessDenied,
+	connect.CodeResourceExhausted: storage.ErrThrottled,
+	connect.CodeUnavailable:       storage.ErrUnavailable,
+	connect.CodeDeadlineExceeded:  storage.ErrTimeout,
+}
+
+// connectError returns the storage error matching an error of the origin's
+// ConnectRPC server.
+func connectError(op string, err error) error {
+	if kind, ok := connectCodes[connect.CodeOf(err)]; ok {
+		return &storage.Error{Kind: kind, Op: op, Err: err}
+	}
+	return storage.Translate(op, err)
+}
+
+// readOnly returns the error of the operations writing upstream.
+func readOnly(op string) error {
+	return &storage.Error{Kind: storage.ErrReadOnly, Op: op, Err: errors.New("upstream storage is read-only")}
+}
+
+func (c *client) PutObject(context.Context, string, string, io.Reader, int64, storage.PutObjectOptions) (storage.ObjectInfo, error) {
+	return storage.ObjectInfo{}, readOnly("put object")
+}
+
+func (c *client) CopyObject(context.Context, string, string, string, storage.CopyObjectOptions) (storage.ObjectInfo, error) {
+	return storage.ObjectInfo{}, readOnly("copy object")
+}
+
+func (c *client) RemoveObject(context.Context, string, string, storage.RemoveObjectOptions) error {
+	return readOnly("remove object")
+}
+
+// ListIncompleteUploads returns nothing: no upload is ever started.
+func (c *client) ListIncompleteUploads(context.Context, string, string) ([]storage.IncompleteUpload, error) {
+	return nil, nil
+}
+
+func (c *client) AbortIncompleteUpload(context.Context, string, string, string) error {
+	return readOnly("abort multipart upload")
+}
+
+// EnableVersioning does nothing: the origin decides which namespaces are
+// versioned.
+func (c *client) EnableVersioning(context.Context, string) error {
+	return nil
+}
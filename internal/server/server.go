@@ -2,26 +2,484 @@ package server
 
 import (
 	"context"
-	"log"
+	"crypto/rand"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
 
+	"github.com/gilwong00/file-streamer/internal/pkg/admission"
+	"github.com/gilwong00/file-streamer/internal/pkg/audit"
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/cache"
 	"github.com/gilwong00/file-streamer/internal/pkg/config"
+	"github.com/gilwong00/file-streamer/internal/pkg/lifecycle"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/presign"
+	"github.com/gilwong00/file-streamer/internal/pkg/ratelimit"
+	"github.com/gilwong00/file-streamer/internal/pkg/readahead"
+	"github.com/gilwong00/file-streamer/internal/pkg/replication"
+	"github.com/gilwong00/file-streamer/internal/pkg/resilience"
+	"github.com/gilwong00/file-streamer/internal/pkg/share"
 	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"github.com/gilwong00/file-streamer/internal/pkg/tlsconfig"
+	"github.com/gilwong00/file-streamer/internal/pkg/tracing"
+	"github.com/gilwong00/file-streamer/internal/pkg/trash"
+	"github.com/gilwong00/file-streamer/internal/pkg/upstream"
 	"github.com/gilwong00/file-streamer/internal/server/transport"
 )
 
 func StartServer(ctx context.Context, config *config.Config) error {
-	storageClient, err := storage.NewStorageClient(
-		config.MinioHost,
-		config.MinioAccessKeyID,
-		config.MinioAccessKey,
-		config.MinioUseSSL,
-	)
+	shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+		Exporter:    config.TracingExporter,
+		Endpoint:    config.TracingOTLPEndpoint,
+		Insecure:    config.TracingOTLPInsecure,
+		SampleRatio: config.TracingSampleRatio,
+	})
 	if err != nil {
 		return err
 	}
-	if err := transport.InitializeTransports(ctx, config, storageClient); err != nil {
-		log.Printf("server error: %v", err)
+	defer func() {
+		// Flush the spans still buffered, with a fresh context as ctx may
+		// already be canceled.
+		flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			slog.Error("flushing traces", "error", err)
+		}
+	}()
+	storageClient, err := newStorageClient(config)
+	if err != nil {
+		return err
+	}
+	namespaces := namespace.NewRegistry(config)
+	if err := prepareNamespaces(ctx, storageClient, namespaces); err != nil {
 		return err
 	}
+	var replicator *replication.Replicator
+	if len(config.ReplicationNamespaces) > 0 {
+		storageClient, replicator, err = startReplication(ctx, config, storageClient, namespaces)
+		if err != nil {
+			return err
+		}
+	}
+	if config.LifecycleConfigFile != "" {
+		storageClient, err = startLifecycle(ctx, config, storageClient, namespaces)
+		if err != nil {
+			return err
+		}
+	}
+	if config.CacheDir != "" {
+		storageClient, err = cache.NewStorageClient(storageClient, cache.Options{
+			Dir:       config.CacheDir,
+			MaxBytes:  int64(config.CacheMaxSizeMB) << 20,
+			BlockSize: int64(config.CacheBlockSizeKB) << 10,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if config.ReadaheadDepth > 0 {
+		storageClient, err = readahead.NewStorageClient(storageClient, readahead.Options{
+			ChunkSize: int64(config.ReadaheadChunkSizeKB) << 10,
+			Depth:     config.ReadaheadDepth,
+			MaxMemory: int64(config.ReadaheadMaxMemoryMB) << 20,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	trash := trash.New(storageClient, namespaces)
+	go trash.RunPurger(ctx, config.TrashPurgeInterval)
+	shares, err := newShareManager(config, storageClient)
+	if err != nil {
+		return err
+	}
+	auditLog, err := newAuditLogger(config)
+	if err != nil {
+		return err
+	}
+	presigner := presign.NewIssuer(ctx, storageClient, auditLog)
+	if replicator != nil {
+		// Presigned uploads bypass the replicating client. Those not
+		// watched, once too many are, wait for reconciliation, which
+		// bounds their replication lag by REPLICATION_RECONCILE_INTERVAL.
+		presigner.NotifyUploads(replicator.Enqueue)
+	}
+	authenticator, err := newAuthenticator(config)
+	if err != nil {
+		return err
+	}
+	var authorizer *authz.Authorizer
+	if config.AuthzPolicyFile != "" {
+		policy, err := authz.LoadPolicy(config.AuthzPolicyFile)
+		if err != nil {
+			return err
+		}
+		authorizer = authz.New(policy)
+	}
+	var limiter *ratelimit.Limiter
+	if config.LimitsConfigFile != "" {
+		limiter, err = ratelimit.Load(config.LimitsConfigFile)
+		if err != nil {
+			return err
+		}
+	}
+	accessLog, err := logging.NewAccessLogger(os.Stdout, config.AccessLogFormat)
+	if err != nil {
+		return err
+	}
+	tlsConfig, err := newTLSConfig(ctx, config)
+	if err != nil {
+		return err
+	}
+	admission := admission.New(admissionLimits(config))
+	go watchConfig(ctx, config, admission)
+	if err := transport.InitializeTransports(
+		ctx,
+		config,
+		storageClient,
+		namespaces,
+		trash,
+		shares,
+		presigner,
+		authenticator,
+		authorizer,
+		limiter,
+		admission,
+		accessLog,
+		tlsConfig,
+	); err != nil {
+		return err
+	}
+	return nil
+}
+
+// newStorageClient returns the client of the MinIO endpoints or, at an
+// edge, of the upstream file-streamer.
+func newStorageClient(config *config.Config) (storage.Client, error) {
+	if config.UpstreamURL == "" {
+		return storage.NewStorageClient(
+			storageEndpoints(config),
+			storage.WithEndpointWrapper(decorateStorage(config)),
+			storage.WithHedgeDelay(config.StorageHedgeDelay),
+			storage.WithRoutingObserver(metrics.RecordRoutingEvent),
+		)
+	}
+	client, err := upstream.NewStorageClient(upstream.Options{
+		URL:         config.UpstreamURL,
+		ConnectURL:  config.UpstreamConnectURL,
+		ShieldURL:   config.UpstreamShieldURL,
+		APIKey:      config.UpstreamAPIKey,
+		MetadataTTL: config.UpstreamMetadataTTL,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decorateStorage(config)(storage.Endpoint{Name: "upstream"}, client), nil
+}
+
+// storageEndpoints returns the primary MinIO endpoint and its replicas.
+// Replicas use the primary's credentials unless given their own.
+func storageEndpoints(config *config.Config) []storage.Endpoint {
+	endpoints := []storage.Endpoint{{
+		Name:            "primary",
+		Host:            config.MinioHost,
+		AccessKeyID:     config.MinioAccessKeyID,
+		SecretAccessKey: config.MinioAccessKey,
+		UseSSL:          config.MinioUseSSL,
+		Role:            storage.RolePrimary,
+	}}
+	accessKeyID, accessKey := config.MinioAccessKeyID, config.MinioAccessKey
+	if config.MinioReplicaAccessKeyID != "" {
+		accessKeyID, accessKey = config.MinioReplicaAccessKeyID, config.MinioReplicaAccessKey
+	}
+	for i, host := range config.MinioReplicaHosts {
+		endpoints = append(endpoints, storage.Endpoint{
+			Name:            fmt.Sprintf("replica-%d", i+1),
+			Host:            host,
+			AccessKeyID:     accessKeyID,
+			SecretAccessKey: accessKey,
+			UseSSL:          config.MinioUseSSL,
+			Role:            storage.RoleReplica,
+		})
+	}
+	return endpoints
+}
+
+// decorateStorage returns the decorator of every storage endpoint: its calls
+// are traced and measured under the endpoint name, and transient failures
+// are retried, resumed or failed fast, as configured.
+func decorateStorage(config *config.Config) func(storage.Endpoint, storage.Client) storage.Client {
+	return func(endpoint storage.Endpoint, client storage.Client) storage.Client {
+		client = metrics.NewStorageClient(tracing.NewStorageClient(client, endpoint.Name), endpoint.Name)
+		return resilience.NewStorageClient(client, endpoint.Name, resilience.Options{
+			Timeout:          config.StorageTimeout,
+			MaxAttempts:      config.StorageRetryMaxAttempts,
+			BaseDelay:        config.StorageRetryBaseDelay,
+			MaxDelay:         config.StorageRetryMaxDelay,
+			BreakerThreshold: config.StorageBreakerThreshold,
+			BreakerCooldown:  config.StorageBreakerCooldown,
+			MaxReconnects:    config.StorageMaxReconnects,
+		})
+	}
+}
+
+// admissionLimits returns the limits on concurrent uploads, downloads and
+// metadata calls.
+func admissionLimits(config *config.Config) map[admission.Class]admission.Limits {
+	limits := func(maxConcurrent int) admission.Limits {
+		return admission.Limits{
+			MaxConcurrent: maxConcurrent,
+			MaxQueue:      config.AdmissionMaxQueue,
+			MaxWait:       config.AdmissionMaxWait,
+		}
+	}
+	return map[admission.Class]admission.Limits{
+		admission.ClassUpload:   limits(config.AdmissionMaxUploads),
+		admission.ClassDownload: limits(config.AdmissionMaxDownloads),
+		admission.ClassMetadata: limits(config.AdmissionMaxMetadata),
+	}
+}
+
+// reloadableSettings are the settings a configuration reload applies to
+// the running server. Changes to the others take effect on restart.
+var reloadableSettings = []string{
+	"LOG_LEVEL",
+	"ADMISSION_MAX_UPLOADS",
+	"ADMISSION_MAX_DOWNLOADS",
+	"ADMISSION_MAX_METADATA",
+	"ADMISSION_MAX_QUEUE",
+	"ADMISSION_MAX_WAIT",
+}
+
+// watchConfig applies reloaded configurations to the running server until
+// ctx is canceled.
+func watchConfig(ctx context.Context, current *config.Config, admission *admission.Controller) {
+	config.Watch(ctx, current, func(next *config.Config) {
+		changed := config.Changed(current, next)
+		current = next
+		var restart []string
+		for _, name := range changed {
+			if !slices.Contains(reloadableSettings, name) {
+				restart = append(restart, name)
+			}
+		}
+		if len(restart) > 0 {
+			slog.Warn("settings changed that only apply after a restart", "settings", restart)
+		}
+		if slices.Contains(changed, "LOG_LEVEL") {
+			// The level was validated with the rest of the configuration.
+			logging.SetLevel(next.LogLevel)
+		}
+		admission.SetLimits(admissionLimits(next))
+	})
+}
+
+// prepareNamespaces creates the bucket backing each namespace if needed and
+// enables versioning on the namespaces configured to keep old versions.
+func prepareNamespaces(ctx context.Context, storageClient storage.Client, namespaces *namespace.Registry) error {
+	for _, ns := range namespaces.List() {
+		err := storageClient.CreateBucket(ctx, ns.Bucket)
+		if err != nil && !errors.Is(err, storage.ErrBucketAlreadyExists) {
+			return fmt.Errorf("namespace %q: %w", ns.Name, err)
+		}
+		if !ns.Versioned {
+			continue
+		}
+		if err := storageClient.EnableVersioning(ctx, ns.Bucket); err != nil {
+			return fmt.Errorf("namespace %q: %w", ns.Name, err)
+		}
+	}
 	return nil
 }
+
+// newShareManager returns the share link manager. Link records live in the
+// default namespace's bucket.
+func newShareManager(config *config.Config, storageClient storage.Client) (*share.Manager, error) {
+	secret := []byte(config.ShareLinkSecret)
+	if len(secret) == 0 {
+		slog.Warn("SHARE_LINK_SECRET is not set; share links will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, err
+		}
+	}
+	return share.NewManager(storageClient, config.BucketName, secret, config.PublicURL), nil
+}
+
+// newAuditLogger returns the audit logger, appending to the configured file
+// or writing to stderr.
+func newAuditLogger(config *config.Config) (*audit.Logger, error) {
+	if config.AuditLogFile == "" {
+		return audit.New(os.Stderr), nil
+	}
+	f, err := os.OpenFile(config.AuditLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+	return audit.New(f), nil
+}
+
+// newAuthenticator returns the authenticator chain for the configured
+// credential types, or nil when none is configured and requests are served
+// anonymously.
+func newAuthenticator(config *config.Config) (auth.Authenticator, error) {
+	var authenticators []auth.Authenticator
+	if config.AuthMTLSCAFile != "" {
+		a, err := auth.NewMTLSAuthenticator(config.AuthMTLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if config.AuthJWKSFile != "" {
+		a, err := auth.NewJWTAuthenticator(auth.JWTConfig{
+			JWKSFile:    config.AuthJWKSFile,
+			Issuer:      config.AuthJWTIssuer,
+			Audience:    config.AuthJWTAudience,
+			GroupsClaim: config.AuthJWTGroupsClaim,
+		})
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if len(config.AuthAPIKeys) > 0 {
+		a, err := auth.NewAPIKeyAuthenticator(config.AuthAPIKeys)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if len(authenticators) == 0 {
+		slog.Warn("no authentication configured; serving anonymous requests")
+		return nil, nil
+	}
+	return auth.NewChain(config.AuthAllowAnonymous, authenticators...), nil
+}
+
+// newTLSConfig returns the TLS configuration of the listeners, or nil when
+// TLS is not configured and they serve plaintext. Client certificates are
+// requested when the mTLS authenticator is enabled.
+func newTLSConfig(ctx context.Context, config *config.Config) (*tls.Config, error) {
+	if config.TLSCertFile == "" && config.TLSKeyFile == "" {
+		if config.AuthMTLSCAFile != "" {
+			slog.Warn("AUTH_MTLS_CA_FILE is set but TLS is not configured; client certificates cannot be presented")
+		}
+		return nil, nil
+	}
+	return tlsconfig.Load(ctx, tlsconfig.Config{
+		CertFile:          config.TLSCertFile,
+		KeyFile:           config.TLSKeyFile,
+		MinVersion:        config.TLSMinVersion,
+		CipherSuites:      config.TLSCipherSuites,
+		ClientCAFile:      config.TLSClientCAFile,
+		RequestClientCert: config.AuthMTLSCAFile != "",
+	})
+}
+
+// startReplication starts replicating the configured namespaces to the
+// replication target. The returned client enqueues the objects written
+// through it for replication; others are enqueued with the returned
+// replicator.
+func startReplication(
+	ctx context.Context,
+	config *config.Config,
+	storageClient storage.Client,
+	namespaces *namespace.Registry,
+) (storage.Client, *replication.Replicator, error) {
+	var target storage.Client
+	if config.ReplicationDirectory != "" {
+		directory, err := storage.NewFilesystemClient(config.ReplicationDirectory)
+		if err != nil {
+			return nil, nil, err
+		}
+		target = decorateStorage(config)(storage.Endpoint{Name: "replication"}, directory)
+	} else {
+		var err error
+		target, err = storage.NewStorageClient([]storage.Endpoint{{
+			Name:            "replication",
+			Host:            config.ReplicationMinioHost,
+			AccessKeyID:     config.ReplicationMinioAccessKeyID,
+			SecretAccessKey: config.ReplicationMinioAccessKey,
+			UseSSL:          config.ReplicationMinioUseSSL,
+			Role:            storage.RolePrimary,
+		}}, storage.WithEndpointWrapper(decorateStorage(config)))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	buckets := make([]string, 0, len(config.ReplicationNamespaces))
+	for _, name := range config.ReplicationNamespaces {
+		ns, err := namespaces.Resolve(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		err = target.CreateBucket(ctx, ns.Bucket)
+		if err != nil && !errors.Is(err, storage.ErrBucketAlreadyExists) {
+			return nil, nil, fmt.Errorf("replication target: namespace %q: %w", ns.Name, err)
+		}
+		buckets = append(buckets, ns.Bucket)
+	}
+	queue, err := replication.OpenQueue(config.ReplicationQueueDir)
+	if err != nil {
+		return nil, nil, err
+	}
+	replicator := replication.New(storageClient, target, queue, buckets, replication.Options{
+		Workers:       config.ReplicationWorkers,
+		MaxRetryDelay: config.ReplicationRetryMaxDelay,
+	})
+	go replicator.Run(ctx)
+	if config.ReplicationReconcileInterval > 0 {
+		go replicator.RunReconciler(ctx, config.ReplicationReconcileInterval)
+	}
+	return replication.NewStorageClient(storageClient, replicator), replicator, nil
+}
+
+// startLifecycle loads the lifecycle rules and starts their scheduler. When
+// rules transition objects to a secondary backend, the returned client
+// reads, copies and removes transitioned objects there.
+func startLifecycle(
+	ctx context.Context,
+	config *config.Config,
+	storageClient storage.Client,
+	namespaces *namespace.Registry,
+) (storage.Client, error) {
+	lifecycleConfig, err := lifecycle.LoadConfig(config.LifecycleConfigFile)
+	if err != nil {
+		return nil, err
+	}
+	if err := lifecycleConfig.Validate(namespaces); err != nil {
+		return nil, fmt.Errorf("invalid lifecycle config: %w", err)
+	}
+	var secondary storage.Client
+	if lifecycleConfig.HasTransitions() {
+		secondary, err = storage.NewStorageClient([]storage.Endpoint{{
+			Name:            "secondary",
+			Host:            lifecycleConfig.Secondary.MinioHost,
+			AccessKeyID:     lifecycleConfig.Secondary.MinioAccessKeyID,
+			SecretAccessKey: lifecycleConfig.Secondary.MinioAccessKey,
+			UseSSL:          lifecycleConfig.Secondary.MinioUseSSL,
+			Role:            storage.RolePrimary,
+		}}, storage.WithEndpointWrapper(decorateStorage(config)))
+		if err != nil {
+			return nil, err
+		}
+		if err := prepareNamespaces(ctx, secondary, namespaces); err != nil {
+			return nil, fmt.Errorf("secondary storage: %w", err)
+		}
+	}
+	scheduler := lifecycle.NewScheduler(lifecycleConfig, storageClient, secondary, namespaces)
+	go scheduler.Run(ctx)
+	if secondary == nil {
+		return storageClient, nil
+	}
+	return lifecycle.NewTieredClient(storageClient, secondary), nil
+}
@@ -4,14 +4,27 @@ package grpctransport
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"log"
 	"net/http"
-	"time"
 
+	"connectrpc.com/connect"
+	"connectrpc.com/grpchealth"
 	"github.com/gilwong00/file-streamer/internal/gen/proto/v1/transferv1connect"
+	"github.com/gilwong00/file-streamer/internal/pkg/admission"
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
 	"github.com/gilwong00/file-streamer/internal/pkg/config"
+	"github.com/gilwong00/file-streamer/internal/pkg/health"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/presign"
+	"github.com/gilwong00/file-streamer/internal/pkg/ratelimit"
+	"github.com/gilwong00/file-streamer/internal/pkg/share"
 	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"github.com/gilwong00/file-streamer/internal/pkg/tracing"
+	"github.com/gilwong00/file-streamer/internal/pkg/trash"
 	"github.com/gilwong00/file-streamer/internal/server/transport/grpc/transferservice"
 	"golang.org/x/net/http2"
 	"golang.org/x/net/http2/h2c"
@@ -27,6 +40,17 @@ type connectRPCServer struct {
 	ctx           context.Context
 	address       string
 	storageClient storage.Client
+	namespaces    *namespace.Registry
+	trash         *trash.Trash
+	shares        *share.Manager
+	presigner     *presign.Issuer
+	authenticator auth.Authenticator
+	authorizer    *authz.Authorizer
+	limiter       *ratelimit.Limiter
+	admission     *admission.Controller
+	accessLog     *logging.AccessLogger
+	health        *health.Checker
+	tlsConfig     *tls.Config
 }
 
 // NewConnectRPCServer creates and returns a new ConnectRPC server instance.
@@ -37,54 +61,90 @@ func NewConnectRPCServer(
 	ctx context.Context,
 	config *config.Config,
 	storageClient storage.Client,
+	namespaces *namespace.Registry,
+	trash *trash.Trash,
+	shares *share.Manager,
+	presigner *presign.Issuer,
+	authenticator auth.Authenticator,
+	authorizer *authz.Authorizer,
+	limiter *ratelimit.Limiter,
+	admission *admission.Controller,
+	accessLog *logging.AccessLogger,
+	health *health.Checker,
+	tlsConfig *tls.Config,
 ) (*connectRPCServer, error) {
 	return &connectRPCServer{
 		ctx:           ctx,
-		address:       fmt.Sprintf(":%v", 5555),
+		address:       fmt.Sprintf(":%v", config.ConnectRPCServerAddress),
 		storageClient: storageClient,
+		namespaces:    namespaces,
+		trash:         trash,
+		shares:        shares,
+		presigner:     presigner,
+		authenticator: authenticator,
+		authorizer:    authorizer,
+		limiter:       limiter,
+		admission:     admission,
+		accessLog:     accessLog,
+		health:        health,
+		tlsConfig:     tlsConfig,
 	}, nil
 }
 
-// StartServer launches the ConnectRPC server and begins listening for incoming requests.
-//
-// It sets up HTTP handlers with HTTP/2 cleartext (h2c) support to allow gRPC
-// communication without TLS, typically for local or internal use.
-//
-// The server runs in a separate goroutine, and this method blocks until either:
-//   - The server encounters a fatal error during startup or runtime, which is returned.
-//   - The server's context is canceled, triggering a graceful shutdown.
+// admissionClass returns the admission class of an RPC: transfers are
+// limited separately from the cheaper metadata calls.
+func admissionClass(procedure string) admission.Class {
+	switch procedure {
+	case transferv1connect.TransferServiceStreamFileProcedure:
+		return admission.ClassDownload
+	case transferv1connect.TransferServiceUploadFileProcedure:
+		return admission.ClassUpload
+	default:
+		return admission.ClassMetadata
+	}
+}
+
+// Server returns the ConnectRPC server. It is run by the caller.
 //
-// The shutdown process waits up to 10 seconds for active connections to close.
-func (s *connectRPCServer) StartServer() error {
-	mux := http.NewServeMux()
-	transferService := transferservice.NewTransferService(s.storageClient, "")
-	transferPath, transferHandler := transferv1connect.NewTransferServiceHandler(transferService)
-	mux.Handle(transferPath, transferHandler)
-	srv := &http.Server{
+// Without TLS it sets up HTTP handlers with HTTP/2 cleartext (h2c) support
+// to allow gRPC communication, typically for local or internal use. With
+// TLS, HTTP/2 is negotiated through ALPN.
+func (s *connectRPCServer) Server() *http.Server {
+	if s.tlsConfig != nil {
+		return &http.Server{Addr: s.address, Handler: s.Handler(), TLSConfig: s.tlsConfig}
+	}
+	return &http.Server{
 		Addr:    s.address,
-		Handler: h2c.NewHandler(mux, &http2.Server{}),
+		Handler: h2c.NewHandler(s.Handler(), &http2.Server{}),
 	}
-	errChan := make(chan error, 1)
-	go func() {
-		log.Printf("ConnectRPC server listening on %s", s.address)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			errChan <- fmt.Errorf("listen failed: %w", err)
-		}
-	}()
-	// Wait for cancellation signal on the context.
-	<-s.ctx.Done()
-	log.Printf("ConnectRPC server shutting down")
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-	// Attempt graceful shutdown within timeout.
-	if err := srv.Shutdown(shutdownCtx); err != nil {
-		return fmt.Errorf("server shutdown error: %w", err)
+}
+
+// Handler returns the handler serving the TransferService and the gRPC
+// health service, for a server run by the caller.
+func (s *connectRPCServer) Handler() http.Handler {
+	mux := http.NewServeMux()
+	transferService := transferservice.NewTransferService(s.storageClient, s.namespaces, s.trash, s.shares, s.presigner, s.authorizer, s.limiter)
+	interceptors := []connect.Interceptor{
+		logging.NewInterceptor(s.accessLog),
+		tracing.NewInterceptor(),
+		metrics.NewInterceptor(),
 	}
-	// Return any listen error received.
-	select {
-	case err := <-errChan:
-		return err
-	default:
-		return nil
+	if s.authenticator != nil {
+		interceptors = append(interceptors, auth.NewInterceptor(s.authenticator))
+	}
+	if s.limiter != nil {
+		interceptors = append(interceptors, ratelimit.NewInterceptor(s.limiter, s.namespaces.Canonical))
+	}
+	if s.admission != nil {
+		interceptors = append(interceptors, admission.NewInterceptor(s.admission, admissionClass))
 	}
+	transferPath, transferHandler := transferv1connect.NewTransferServiceHandler(
+		transferService,
+		connect.WithInterceptors(interceptors...),
+	)
+	mux.Handle(transferPath, auth.WithConnectionState(transferHandler))
+	// The health service is probed by orchestrators and load balancers, so
+	// it is served without authentication, limits or access logs.
+	mux.Handle(grpchealth.NewHandler(s.health.GRPCChecker(transferv1connect.TransferServiceName)))
+	return mux
 }
@@ -0,0 +1,45 @@
+package transferservice
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+)
+
+// CheckAccess explains whether a principal may perform an action on a path.
+func (s *transferService) CheckAccess(
+	ctx context.Context,
+	req *connect.Request[transferv1.CheckAccessRequest],
+) (*connect.Response[transferv1.CheckAccessResponse], error) {
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	decision, err := s.authorizer.Check(auth.Caller(ctx), authz.Request{
+		Identity:  auth.Identity{Subject: req.Msg.Principal, Groups: req.Msg.Groups},
+		Namespace: ns.Name,
+		Path:      req.Msg.Path,
+		Action:    req.Msg.Action,
+	})
+	switch {
+	case errors.Is(err, authz.ErrDenied):
+		return nil, connect.NewError(connect.CodePermissionDenied, err)
+	case err != nil:
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	principal := req.Msg.Principal
+	if principal == "" {
+		principal = auth.Caller(ctx).Subject
+	}
+	return connect.NewResponse(&transferv1.CheckAccessResponse{
+		Allowed:   decision.Allowed,
+		RuleId:    decision.RuleID,
+		Reason:    decision.Reason,
+		Principal: principal,
+		Groups:    decision.Groups,
+	}), nil
+}
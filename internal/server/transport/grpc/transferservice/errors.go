@@ -0,0 +1,70 @@
+package transferservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+const (
+	// errorDomain is the domain of the ErrorInfo details of storage errors.
+	errorDomain = "file-streamer"
+	// retryDelay is the delay suggested to clients for retryable errors.
+	retryDelay = time.Second
+)
+
+// storageCodes maps storage error kinds to the Connect code and ErrorInfo
+// reason reported to clients.
+var storageCodes = map[error]struct {
+	code   connect.Code
+	reason string
+}{
+	storage.ErrNotFound:       {connect.CodeNotFound, "NOT_FOUND"},
+	storage.ErrBucketNotFound: {connect.CodeNotFound, "BUCKET_NOT_FOUND"},
+	storage.ErrAccessDenied:   {connect.CodeInternal, "STORAGE_ACCESS_DENIED"},
+	storage.ErrInvalidRange:   {connect.CodeOutOfRange, "INVALID_RANGE"},
+	storage.ErrModified:       {connect.CodeAborted, "OBJECT_MODIFIED"},
+	storage.ErrThrottled:      {connect.CodeResourceExhausted, "STORAGE_THROTTLED"},
+	storage.ErrUnavailable:    {connect.CodeUnavailable, "STORAGE_UNAVAILABLE"},
+	storage.ErrTimeout:        {connect.CodeDeadlineExceeded, "STORAGE_TIMEOUT"},
+	storage.ErrReadOnly:       {connect.CodeFailedPrecondition, "STORAGE_READ_ONLY"},
+}
+
+// storageError converts an error returned by storage into a Connect error.
+// Clients only see the kind of the error and its details; the backend's
+// message, which may name buckets or hosts, is logged instead.
+func storageError(ctx context.Context, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return connect.NewError(connect.CodeCanceled, err)
+	}
+	kind := storage.KindOf(err)
+	mapping, ok := storageCodes[kind]
+	if !ok {
+		logging.FromContext(ctx).Error("storage request failed", "error", err)
+		return connect.NewError(connect.CodeInternal, errors.New("internal error"))
+	}
+	if !storage.IsNotFound(err) && kind != storage.ErrInvalidRange && kind != storage.ErrReadOnly {
+		logging.FromContext(ctx).Warn("storage request failed", "error", err)
+	}
+	connectErr := connect.NewError(mapping.code, kind)
+	if detail, err := connect.NewErrorDetail(&errdetails.ErrorInfo{
+		Reason: mapping.reason,
+		Domain: errorDomain,
+	}); err == nil {
+		connectErr.AddDetail(detail)
+	}
+	if storage.IsRetryable(err) {
+		if detail, err := connect.NewErrorDetail(&errdetails.RetryInfo{
+			RetryDelay: durationpb.New(retryDelay),
+		}); err == nil {
+			connectErr.AddDetail(detail)
+		}
+	}
+	return connectErr
+}
@@ -0,0 +1,70 @@
+package transferservice
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+func (s *transferService) GetFileInfo(
+	ctx context.Context,
+	req *connect.Request[transferv1.GetFileInfoRequest],
+) (*connect.Response[transferv1.GetFileInfoResponse], error) {
+	if err := fileutils.ValidateFileName(req.Msg.FileName); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, req.Msg.FileName, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	info, err := s.storageClient.GetObjectInfo(ctx, ns.Bucket, req.Msg.FileName, storage.GetObjectInfoOptions{
+		VersionID: req.Msg.VersionId,
+	})
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	res := &transferv1.GetFileInfoResponse{
+		FileName:     req.Msg.FileName,
+		Size:         info.Size,
+		Etag:         info.ETag,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified.Unix(),
+		Metadata:     info.Metadata,
+		VersionId:    info.VersionID,
+	}
+	if req.Msg.IncludeVersions {
+		versions, err := s.storageClient.ListObjects(ctx, ns.Bucket, storage.ListObjectsOptions{
+			Prefix:       req.Msg.FileName,
+			WithVersions: true,
+		})
+		if err != nil {
+			return nil, storageError(ctx, err)
+		}
+		for _, version := range versions {
+			// The listing is by prefix, so skip other files sharing it.
+			if version.Key == req.Msg.FileName {
+				res.Versions = append(res.Versions, toFileVersion(version))
+			}
+		}
+	}
+	return connect.NewResponse(res), nil
+}
+
+// toFileVersion converts a storage object version to its protobuf form.
+func toFileVersion(info storage.ObjectInfo) *transferv1.FileVersion {
+	return &transferv1.FileVersion{
+		VersionId:      info.VersionID,
+		Size:           info.Size,
+		Etag:           info.ETag,
+		LastModified:   info.LastModified.Unix(),
+		IsLatest:       info.IsLatest,
+		IsDeleteMarker: info.IsDeleteMarker,
+	}
+}
@@ -5,15 +5,28 @@ import (
 
 	"connectrpc.com/connect"
 	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
 )
 
 func (s *transferService) GetFileSize(
 	ctx context.Context,
 	req *connect.Request[transferv1.GetFileSizeRequest],
 ) (*connect.Response[transferv1.GetFileSizeResponse], error) {
-	info, err := s.storageClient.GetObjectInfo(ctx, s.bucketName, req.Msg.FileName)
+	if err := fileutils.ValidateFileName(req.Msg.FileName); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, req.Msg.FileName, authz.ActionRead); err != nil {
+		return nil, err
+	}
+	info, err := s.storageClient.GetObjectInfo(ctx, ns.Bucket, req.Msg.FileName, storage.GetObjectInfoOptions{})
 	if err != nil {
-		return nil, connect.NewError(connect.CodeNotFound, err)
+		return nil, storageError(ctx, err)
 	}
 	return connect.NewResponse(&transferv1.GetFileSizeResponse{
 		Size: info.Size,
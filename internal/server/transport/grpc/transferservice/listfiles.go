@@ -0,0 +1,60 @@
+package transferservice
+
+import (
+	"context"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// ListFiles lists the files in a namespace under an optional prefix.
+//
+// With include_versions every file carries its version history, and files
+// whose latest version is a delete marker are still listed so that their
+// older versions can be restored.
+func (s *transferService) ListFiles(
+	ctx context.Context,
+	req *connect.Request[transferv1.ListFilesRequest],
+) (*connect.Response[transferv1.ListFilesResponse], error) {
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, req.Msg.Prefix, authz.ActionList); err != nil {
+		return nil, err
+	}
+	objects, err := s.storageClient.ListObjects(ctx, ns.Bucket, storage.ListObjectsOptions{
+		Prefix:       req.Msg.Prefix,
+		WithVersions: req.Msg.IncludeVersions,
+	})
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	res := &transferv1.ListFilesResponse{}
+	entries := make(map[string]*transferv1.FileEntry)
+	for _, object := range objects {
+		// Deleted files are only visible through ListTrash.
+		if fileutils.IsReserved(object.Key) || !s.listable(ctx, ns, object.Key) {
+			continue
+		}
+		entry, ok := entries[object.Key]
+		if !ok {
+			entry = &transferv1.FileEntry{FileName: object.Key}
+			entries[object.Key] = entry
+			res.Files = append(res.Files, entry)
+		}
+		if object.IsLatest && !object.IsDeleteMarker {
+			entry.Size = object.Size
+			entry.Etag = object.ETag
+			entry.LastModified = object.LastModified.Unix()
+			entry.VersionId = object.VersionID
+		}
+		if req.Msg.IncludeVersions {
+			entry.Versions = append(entry.Versions, toFileVersion(object))
+		}
+	}
+	return connect.NewResponse(res), nil
+}
@@ -0,0 +1,67 @@
+package transferservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/presign"
+)
+
+// GetPresignedURL returns a time-limited URL for transferring a file directly
+// to or from the storage backend.
+func (s *transferService) GetPresignedURL(
+	ctx context.Context,
+	req *connect.Request[transferv1.GetPresignedURLRequest],
+) (*connect.Response[transferv1.GetPresignedURLResponse], error) {
+	if err := fileutils.ValidateFileName(req.Msg.FileName); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	var method, action string
+	switch req.Msg.Method {
+	case transferv1.PresignMethod_PRESIGN_METHOD_GET:
+		method, action = presign.MethodGet, authz.ActionRead
+	case transferv1.PresignMethod_PRESIGN_METHOD_PUT:
+		method, action = presign.MethodPut, authz.ActionWrite
+	default:
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("method must be GET or PUT"))
+	}
+	if err := s.authorize(ctx, ns, req.Msg.FileName, action); err != nil {
+		return nil, err
+	}
+	if req.Msg.ExpiresInSeconds < 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("expiry must not be negative"))
+	}
+	u, err := s.presigner.Issue(ctx, presign.Request{
+		Namespace:        ns,
+		FileName:         req.Msg.FileName,
+		Method:           method,
+		VersionID:        req.Msg.VersionId,
+		TTL:              time.Duration(req.Msg.ExpiresInSeconds) * time.Second,
+		VerifyCompletion: req.Msg.VerifyCompletion,
+		Remote:           req.Peer().Addr,
+	})
+	switch {
+	case errors.Is(err, presign.ErrInvalidRequest):
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	case errors.Is(err, presign.ErrUnsupported):
+		return nil, connect.NewError(connect.CodeUnimplemented, err)
+	case errors.Is(err, presign.ErrTooManyWatchers):
+		return nil, connect.NewError(connect.CodeResourceExhausted, err)
+	case err != nil:
+		return nil, storageError(ctx, err)
+	}
+	return connect.NewResponse(&transferv1.GetPresignedURLResponse{
+		Url:       u.URL,
+		Method:    req.Msg.Method,
+		ExpiresAt: u.ExpiresAt.Unix(),
+	}), nil
+}
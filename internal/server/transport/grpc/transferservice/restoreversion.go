@@ -0,0 +1,56 @@
+package transferservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// RestoreVersion makes an old version of a file the latest one by copying it
+// over the file. The previous latest version is kept in the history.
+func (s *transferService) RestoreVersion(
+	ctx context.Context,
+	req *connect.Request[transferv1.RestoreVersionRequest],
+) (*connect.Response[transferv1.RestoreVersionResponse], error) {
+	fileName := req.Msg.FileName
+	if err := fileutils.ValidateFileName(fileName); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	if req.Msg.VersionId == "" {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("missing version id"))
+	}
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, fileName, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	if !ns.Versioned {
+		return nil, connect.NewError(
+			connect.CodeFailedPrecondition,
+			fmt.Errorf("namespace %q is not versioned", ns.Name),
+		)
+	}
+	if _, err := s.storageClient.GetObjectInfo(ctx, ns.Bucket, fileName, storage.GetObjectInfoOptions{
+		VersionID: req.Msg.VersionId,
+	}); err != nil {
+		return nil, storageError(ctx, err)
+	}
+	info, err := s.storageClient.CopyObject(ctx, ns.Bucket, fileName, fileName, storage.CopyObjectOptions{
+		SrcVersionID: req.Msg.VersionId,
+	})
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return connect.NewResponse(&transferv1.RestoreVersionResponse{
+		FileName:  fileName,
+		VersionId: info.VersionID,
+	}), nil
+}
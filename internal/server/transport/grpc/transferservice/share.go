@@ -0,0 +1,108 @@
+package transferservice
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/share"
+)
+
+// CreateShareLink creates a link through which the file can be downloaded
+// without credentials. Like listing and revoking links, it requires the
+// admin action, since the link outlives the caller's own access.
+func (s *transferService) CreateShareLink(
+	ctx context.Context,
+	req *connect.Request[transferv1.CreateShareLinkRequest],
+) (*connect.Response[transferv1.CreateShareLinkResponse], error) {
+	if err := fileutils.ValidateFileName(req.Msg.FileName); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, req.Msg.FileName, authz.ActionAdmin); err != nil {
+		return nil, err
+	}
+	if req.Msg.ExpiresInSeconds < 0 || req.Msg.MaxDownloads < 0 {
+		return nil, connect.NewError(connect.CodeInvalidArgument, errors.New("expiry and max downloads must not be negative"))
+	}
+	link, token, err := s.shares.Create(ctx, ns, req.Msg.FileName, share.CreateOptions{
+		VersionID:    req.Msg.VersionId,
+		TTL:          time.Duration(req.Msg.ExpiresInSeconds) * time.Second,
+		MaxDownloads: req.Msg.MaxDownloads,
+		Password:     req.Msg.Password,
+	})
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return connect.NewResponse(&transferv1.CreateShareLinkResponse{
+		Link:  toShareLink(link),
+		Token: token,
+		Url:   s.shares.URL(token),
+	}), nil
+}
+
+// ListShareLinks lists the active share links of a namespace.
+func (s *transferService) ListShareLinks(
+	ctx context.Context,
+	req *connect.Request[transferv1.ListShareLinksRequest],
+) (*connect.Response[transferv1.ListShareLinksResponse], error) {
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, "", authz.ActionAdmin); err != nil {
+		return nil, err
+	}
+	links, err := s.shares.List(ctx, ns)
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	res := &transferv1.ListShareLinksResponse{}
+	for _, link := range links {
+		res.Links = append(res.Links, toShareLink(link))
+	}
+	return connect.NewResponse(res), nil
+}
+
+// RevokeShareLink permanently disables a share link.
+func (s *transferService) RevokeShareLink(
+	ctx context.Context,
+	req *connect.Request[transferv1.RevokeShareLinkRequest],
+) (*connect.Response[transferv1.RevokeShareLinkResponse], error) {
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, "", authz.ActionAdmin); err != nil {
+		return nil, err
+	}
+	err = s.shares.Revoke(ctx, ns, req.Msg.Id)
+	switch {
+	case errors.Is(err, share.ErrNotFound):
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	case err != nil:
+		return nil, storageError(ctx, err)
+	}
+	return connect.NewResponse(&transferv1.RevokeShareLinkResponse{}), nil
+}
+
+// toShareLink converts a share link to its protobuf form.
+func toShareLink(link share.Link) *transferv1.ShareLink {
+	return &transferv1.ShareLink{
+		Id:                link.ID,
+		FileName:          link.FileName,
+		VersionId:         link.VersionID,
+		CreatedAt:         link.CreatedAt.Unix(),
+		ExpiresAt:         link.ExpiresAt.Unix(),
+		MaxDownloads:      link.MaxDownloads,
+		Downloads:         link.Downloads,
+		PasswordProtected: link.PasswordProtected(),
+	}
+}
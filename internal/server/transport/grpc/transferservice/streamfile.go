@@ -1,17 +1,139 @@
 package transferservice
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 
 	"connectrpc.com/connect"
 	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/ratelimit"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"github.com/gilwong00/file-streamer/internal/pkg/tracing"
 )
 
+const (
+	defaultChunkSize = 64 * 1024       // 64kb
+	maxChunkSize     = 4 * 1024 * 1024 // 4mb
+)
+
+// StreamFile streams the object (or the requested version of it) from
+// req.Start to the end of the file in chunks of req.ChunkSize bytes. Chunks
+// are gzip compressed when the client can decompress them and compression
+// actually reduces their size.
 func (s *transferService) StreamFile(
 	ctx context.Context,
 	req *connect.Request[transferv1.StreamFileRequest],
 	stream *connect.ServerStream[transferv1.StreamFileResponse],
 ) error {
-	return connect.NewError(connect.CodeUnimplemented, errors.New("this method is not implemented"))
+	fileName := req.Msg.FileName
+	if err := fileutils.ValidateFileName(fileName); err != nil {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	chunkSize := req.Msg.ChunkSize
+	if chunkSize <= 0 {
+		chunkSize = defaultChunkSize
+	}
+	if chunkSize > maxChunkSize {
+		return connect.NewError(
+			connect.CodeInvalidArgument,
+			fmt.Errorf("chunk size must not exceed %d bytes", maxChunkSize),
+		)
+	}
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, ns, fileName, authz.ActionRead); err != nil {
+		return err
+	}
+	info, err := s.storageClient.GetObjectInfo(ctx, ns.Bucket, fileName, storage.GetObjectInfoOptions{
+		VersionID: req.Msg.VersionId,
+	})
+	if err != nil {
+		return storageError(ctx, err)
+	}
+	start := req.Msg.Start
+	if start < 0 || start > info.Size {
+		return connect.NewError(
+			connect.CodeOutOfRange,
+			fmt.Errorf("start %d is outside of file size %d", start, info.Size),
+		)
+	}
+	// Nothing left to send
+	if start == info.Size {
+		return nil
+	}
+	obj, err := s.storageClient.GetObject(ctx, ns.Bucket, fileName, storage.GetObjectOptions{
+		Start:     start,
+		End:       info.Size - 1,
+		VersionID: req.Msg.VersionId,
+		MatchETag: info.ETag,
+	})
+	if err != nil {
+		return storageError(ctx, err)
+	}
+	defer obj.Close()
+	metrics.RecordDownload(metrics.TransportConnect, start > 0)
+	body := s.limiter.Reader(ctx, ratelimit.KeysFor(ctx, ns.Name), obj)
+	body = tracing.NewProgress(ctx).Reader(body)
+	buf := make([]byte, chunkSize)
+	offset := start
+	for {
+		n, err := io.ReadFull(body, buf)
+		if n > 0 {
+			res := &transferv1.StreamFileResponse{
+				Chunk:  buf[:n],
+				Offset: offset,
+			}
+			if req.Msg.CanDecompress && n >= minCompressionSize {
+				if compressed, ok := compressChunk(buf[:n]); ok {
+					metrics.RecordCompression(metrics.TransportConnect, int64(n), int64(len(compressed)))
+					res.Chunk = compressed
+					res.Compressed = true
+				}
+			}
+			if err := stream.Send(res); err != nil {
+				return err
+			}
+			offset += int64(n)
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			if offset == info.Size {
+				return nil
+			}
+			// The object ended early: fail rather than end the stream, so
+			// that the client resumes instead of keeping a short file.
+			return storageError(ctx, &storage.Error{
+				Kind: storage.ErrUnavailable,
+				Op:   "reading object",
+				Err:  fmt.Errorf("object ended at byte %d of %d", offset, info.Size),
+			})
+		}
+		if err != nil {
+			return storageError(ctx, storage.Translate("reading object", err))
+		}
+	}
+}
+
+// compressChunk gzips chunk and reports whether the result is smaller.
+func compressChunk(chunk []byte) ([]byte, bool) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(chunk); err != nil {
+		return nil, false
+	}
+	if err := gz.Close(); err != nil {
+		return nil, false
+	}
+	if buf.Len() >= len(chunk) {
+		return nil, false
+	}
+	return buf.Bytes(), true
 }
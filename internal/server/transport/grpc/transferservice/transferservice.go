@@ -1,21 +1,87 @@
 package transferservice
 
 import (
+	"context"
+
+	"connectrpc.com/connect"
 	"github.com/gilwong00/file-streamer/internal/gen/proto/v1/transferv1connect"
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/presign"
+	"github.com/gilwong00/file-streamer/internal/pkg/ratelimit"
+	"github.com/gilwong00/file-streamer/internal/pkg/share"
 	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"github.com/gilwong00/file-streamer/internal/pkg/trash"
+)
+
+const (
+	minCompressionSize = 8 * 1024 // 8kb
 )
 
 type transferService struct {
 	storageClient storage.Client
-	bucketName    string
+	namespaces    *namespace.Registry
+	trash         *trash.Trash
+	shares        *share.Manager
+	presigner     *presign.Issuer
+	authorizer    *authz.Authorizer
+	limiter       *ratelimit.Limiter
 }
 
 func NewTransferService(
 	storageClient storage.Client,
-	bucketName string,
+	namespaces *namespace.Registry,
+	trash *trash.Trash,
+	shares *share.Manager,
+	presigner *presign.Issuer,
+	authorizer *authz.Authorizer,
+	limiter *ratelimit.Limiter,
 ) transferv1connect.TransferServiceHandler {
 	return &transferService{
 		storageClient: storageClient,
-		bucketName:    bucketName,
+		namespaces:    namespaces,
+		trash:         trash,
+		shares:        shares,
+		presigner:     presigner,
+		authorizer:    authorizer,
+		limiter:       limiter,
+	}
+}
+
+// resolveNamespace looks up the namespace named in a request, returning a
+// Connect error suitable for the client if it is not configured.
+func (s *transferService) resolveNamespace(name string) (namespace.Namespace, error) {
+	ns, err := s.namespaces.Resolve(name)
+	if err != nil {
+		return namespace.Namespace{}, connect.NewError(connect.CodeInvalidArgument, err)
 	}
+	return ns, nil
+}
+
+// authorize checks that the caller may perform action on path in ns,
+// returning a PermissionDenied error explaining why not otherwise.
+func (s *transferService) authorize(ctx context.Context, ns namespace.Namespace, path, action string) error {
+	decision := s.authorizer.Authorize(authz.Request{
+		Identity:  auth.Caller(ctx),
+		Namespace: ns.Name,
+		Path:      path,
+		Action:    action,
+	})
+	if err := decision.Err(); err != nil {
+		return connect.NewError(connect.CodePermissionDenied, err)
+	}
+	return nil
+}
+
+// listable reports whether the caller may see the file name in listings of
+// ns: being allowed to list a prefix does not reveal the files under it
+// that a rule denies listing.
+func (s *transferService) listable(ctx context.Context, ns namespace.Namespace, fileName string) bool {
+	return s.authorizer.Authorize(authz.Request{
+		Identity:  auth.Caller(ctx),
+		Namespace: ns.Name,
+		Path:      fileName,
+		Action:    authz.ActionList,
+	}).Allowed
 }
@@ -0,0 +1,102 @@
+package transferservice
+
+import (
+	"context"
+	"errors"
+
+	"connectrpc.com/connect"
+	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/trash"
+)
+
+// DeleteFile soft deletes a file by moving it into the namespace's trash.
+func (s *transferService) DeleteFile(
+	ctx context.Context,
+	req *connect.Request[transferv1.DeleteFileRequest],
+) (*connect.Response[transferv1.DeleteFileResponse], error) {
+	if err := fileutils.ValidateFileName(req.Msg.FileName); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, req.Msg.FileName, authz.ActionDelete); err != nil {
+		return nil, err
+	}
+	entry, err := s.trash.Delete(ctx, ns, req.Msg.FileName)
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	return connect.NewResponse(&transferv1.DeleteFileResponse{
+		Entry: toTrashEntry(entry),
+	}), nil
+}
+
+// ListTrash lists the deleted files that can still be undeleted.
+func (s *transferService) ListTrash(
+	ctx context.Context,
+	req *connect.Request[transferv1.ListTrashRequest],
+) (*connect.Response[transferv1.ListTrashResponse], error) {
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, "", authz.ActionList); err != nil {
+		return nil, err
+	}
+	entries, err := s.trash.List(ctx, ns)
+	if err != nil {
+		return nil, storageError(ctx, err)
+	}
+	res := &transferv1.ListTrashResponse{}
+	for _, entry := range entries {
+		if s.listable(ctx, ns, entry.FileName) {
+			res.Entries = append(res.Entries, toTrashEntry(entry))
+		}
+	}
+	return connect.NewResponse(res), nil
+}
+
+// Undelete restores a deleted file from the trash.
+func (s *transferService) Undelete(
+	ctx context.Context,
+	req *connect.Request[transferv1.UndeleteRequest],
+) (*connect.Response[transferv1.UndeleteResponse], error) {
+	if err := fileutils.ValidateFileName(req.Msg.FileName); err != nil {
+		return nil, connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	ns, err := s.resolveNamespace(req.Msg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, ns, req.Msg.FileName, authz.ActionWrite); err != nil {
+		return nil, err
+	}
+	info, err := s.trash.Undelete(ctx, ns, req.Msg.FileName, req.Msg.TrashId)
+	switch {
+	case errors.Is(err, trash.ErrNotFound):
+		return nil, connect.NewError(connect.CodeNotFound, err)
+	case errors.Is(err, trash.ErrFileExists):
+		return nil, connect.NewError(connect.CodeAlreadyExists, err)
+	case err != nil:
+		return nil, storageError(ctx, err)
+	}
+	return connect.NewResponse(&transferv1.UndeleteResponse{
+		FileName:  req.Msg.FileName,
+		VersionId: info.VersionID,
+	}), nil
+}
+
+// toTrashEntry converts a trash entry to its protobuf form.
+func toTrashEntry(entry trash.Entry) *transferv1.TrashEntry {
+	return &transferv1.TrashEntry{
+		TrashId:   entry.ID,
+		FileName:  entry.FileName,
+		Size:      entry.Size,
+		DeletedAt: entry.DeletedAt.Unix(),
+		ExpiresAt: entry.ExpiresAt.Unix(),
+	}
+}
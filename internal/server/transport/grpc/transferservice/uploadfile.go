@@ -1,16 +1,163 @@
 package transferservice
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"errors"
+	"fmt"
+	"io"
 
 	"connectrpc.com/connect"
 	transferv1 "github.com/gilwong00/file-streamer/internal/gen/proto/v1"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/ratelimit"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"github.com/gilwong00/file-streamer/internal/pkg/tracing"
 )
 
+const (
+	// progressInterval is how many bytes are received between progress updates.
+	progressInterval = 1024 * 1024 // 1mb
+)
+
+// UploadFile receives a file as a stream of chunks and stores it in object storage.
+//
+// The first message must carry the file name and may carry the namespace and
+// the user metadata and tags to store with the object. Each chunk's offset
+// must match the number of (decompressed) bytes received so far. The server
+// sends a progress update every progressInterval bytes and a final response
+// once the client closes its side of the stream and the object has been
+// stored. In versioned namespaces every upload creates a new version whose ID
+// is returned in the final response.
 func (s *transferService) UploadFile(
 	ctx context.Context,
-	req *connect.BidiStream[transferv1.UploadFileRequest, transferv1.UploadFileResponse],
+	stream *connect.BidiStream[transferv1.UploadFileRequest, transferv1.UploadFileResponse],
 ) error {
-	return connect.NewError(connect.CodeUnimplemented, errors.New("this method is not implemented"))
+	msg, err := stream.Receive()
+	if errors.Is(err, io.EOF) {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("no upload request received"))
+	}
+	if err != nil {
+		return err
+	}
+	fileName := msg.FileName
+	if fileName == "" {
+		return connect.NewError(connect.CodeInvalidArgument, errors.New("missing file name"))
+	}
+	if err := fileutils.ValidateFileName(fileName); err != nil {
+		return connect.NewError(connect.CodeInvalidArgument, err)
+	}
+	ns, err := s.resolveNamespace(msg.Namespace)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, ns, fileName, authz.ActionWrite); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	pr, pw := io.Pipe()
+	type putResult struct {
+		info storage.ObjectInfo
+		err  error
+	}
+	done := make(chan putResult, 1)
+	go func() {
+		info, err := s.storageClient.PutObject(ctx, ns.Bucket, fileName, pr, -1, storage.PutObjectOptions{
+			Metadata: msg.Metadata,
+			Tags:     msg.Tags,
+		})
+		// Unblock the receive loop if storage gave up early.
+		pr.CloseWithError(err)
+		done <- putResult{info: info, err: err}
+	}()
+	progress := tracing.NewProgress(ctx)
+	w := progress.Writer(s.limiter.Writer(ctx, ratelimit.KeysFor(ctx, ns.Name), pw))
+	received, err := s.receiveChunks(ctx, stream, fileName, msg, w)
+	if err != nil {
+		pw.CloseWithError(err)
+		cancel()
+		<-done
+		metrics.RecordUpload(metrics.TransportConnect, err)
+		return err
+	}
+	pw.Close()
+	res := <-done
+	metrics.RecordUpload(metrics.TransportConnect, res.err)
+	if res.err != nil {
+		return storageError(ctx, res.err)
+	}
+	progress.Complete()
+	return stream.Send(&transferv1.UploadFileResponse{
+		FileName:      fileName,
+		BytesReceived: received,
+		Success:       true,
+		VersionId:     res.info.VersionID,
+	})
+}
+
+// receiveChunks writes the chunk in msg and every following message to w
+// until the client closes the stream, returning the number of bytes written.
+func (s *transferService) receiveChunks(
+	ctx context.Context,
+	stream *connect.BidiStream[transferv1.UploadFileRequest, transferv1.UploadFileResponse],
+	fileName string,
+	msg *transferv1.UploadFileRequest,
+	w io.Writer,
+) (int64, error) {
+	var received, lastProgress int64
+	for {
+		if msg.Offset != received {
+			return received, connect.NewError(
+				connect.CodeInvalidArgument,
+				fmt.Errorf("unexpected chunk offset %d, expected %d", msg.Offset, received),
+			)
+		}
+		chunk := msg.Chunk
+		if msg.Compressed {
+			decompressed, err := decompressChunk(chunk)
+			if err != nil {
+				return received, connect.NewError(connect.CodeInvalidArgument, err)
+			}
+			chunk = decompressed
+		}
+		if _, err := w.Write(chunk); err != nil {
+			return received, storageError(ctx, err)
+		}
+		received += int64(len(chunk))
+		if received-lastProgress >= progressInterval {
+			lastProgress = received
+			if err := stream.Send(&transferv1.UploadFileResponse{
+				FileName:      fileName,
+				BytesReceived: received,
+			}); err != nil {
+				return received, err
+			}
+		}
+		next, err := stream.Receive()
+		if errors.Is(err, io.EOF) {
+			return received, nil
+		}
+		if err != nil {
+			return received, err
+		}
+		msg = next
+	}
+}
+
+// decompressChunk gunzips a compressed upload chunk.
+func decompressChunk(chunk []byte) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(chunk))
+	if err != nil {
+		return nil, fmt.Errorf("decompressing chunk: %w", err)
+	}
+	defer gz.Close()
+	decompressed, err := io.ReadAll(gz)
+	if err != nil {
+		return nil, fmt.Errorf("decompressing chunk: %w", err)
+	}
+	return decompressed, nil
 }
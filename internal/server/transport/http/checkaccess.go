@@ -0,0 +1,71 @@
+package httptransport
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+)
+
+// checkAccessRequest is the JSON body of POST /authz/check.
+type checkAccessRequest struct {
+	// Principal is the subject to check; empty checks the caller.
+	Principal string   `json:"principal"`
+	Groups    []string `json:"groups"`
+	Namespace string   `json:"namespace"`
+	Path      string   `json:"path"`
+	Action    string   `json:"action"`
+}
+
+// checkAccessResponse is the JSON representation of an explained decision.
+type checkAccessResponse struct {
+	Allowed   bool     `json:"allowed"`
+	RuleID    string   `json:"rule_id,omitempty"`
+	Reason    string   `json:"reason"`
+	Principal string   `json:"principal"`
+	Groups    []string `json:"groups"`
+}
+
+// checkAccessHandler explains whether a principal may perform an action on a
+// path. Checking another principal than the caller, or the caller with other
+// groups than its own, requires the admin action.
+func (s *httpServer) checkAccessHandler(w http.ResponseWriter, r *http.Request) {
+	var req checkAccessRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	ns, err := s.namespaces.Resolve(req.Namespace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	caller := auth.Caller(r.Context())
+	decision, err := s.authorizer.Check(caller, authz.Request{
+		Identity:  auth.Identity{Subject: req.Principal, Groups: req.Groups},
+		Namespace: ns.Name,
+		Path:      req.Path,
+		Action:    req.Action,
+	})
+	switch {
+	case errors.Is(err, authz.ErrDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	principal := req.Principal
+	if principal == "" {
+		principal = caller.Subject
+	}
+	writeJSON(w, http.StatusOK, checkAccessResponse{
+		Allowed:   decision.Allowed,
+		RuleID:    decision.RuleID,
+		Reason:    decision.Reason,
+		Principal: principal,
+		Groups:    decision.Groups,
+	})
+}
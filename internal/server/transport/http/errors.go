@@ -0,0 +1,59 @@
+package httptransport
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+const (
+	// retryAfterSeconds is the Retry-After sent with retryable storage
+	// errors.
+	retryAfterSeconds = 1
+	// statusClientClosedRequest is the status logged for requests the
+	// client canceled, as nginx does.
+	statusClientClosedRequest = 499
+)
+
+// storageStatuses maps storage error kinds to the status reported to
+// clients. A storage backend refusing the server's own credentials is a
+// gateway error rather than the client's fault.
+var storageStatuses = map[error]int{
+	storage.ErrNotFound:       http.StatusNotFound,
+	storage.ErrBucketNotFound: http.StatusNotFound,
+	storage.ErrAccessDenied:   http.StatusBadGateway,
+	storage.ErrInvalidRange:   http.StatusRequestedRangeNotSatisfiable,
+	storage.ErrModified:       http.StatusConflict,
+	storage.ErrThrottled:      http.StatusServiceUnavailable,
+	storage.ErrUnavailable:    http.StatusServiceUnavailable,
+	storage.ErrTimeout:        http.StatusGatewayTimeout,
+	storage.ErrReadOnly:       http.StatusMethodNotAllowed,
+}
+
+// writeStorageError writes the response for an error returned by storage.
+// Clients only see the kind of the error; the backend's message, which may
+// name buckets or hosts, is logged instead.
+func writeStorageError(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, context.Canceled) {
+		http.Error(w, "request canceled", statusClientClosedRequest)
+		return
+	}
+	kind := storage.KindOf(err)
+	status, ok := storageStatuses[kind]
+	if !ok {
+		logging.FromContext(r.Context()).Error("storage request failed", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	if !storage.IsNotFound(err) && kind != storage.ErrInvalidRange && kind != storage.ErrReadOnly {
+		logging.FromContext(r.Context()).Warn("storage request failed", "error", err)
+	}
+	if storage.IsRetryable(err) {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds))
+	}
+	http.Error(w, kind.Error(), status)
+}
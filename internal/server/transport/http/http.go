@@ -3,20 +3,31 @@ package httptransport
 import (
 	"compress/gzip"
 	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
-	"os/signal"
 	"strconv"
 	"strings"
-	"syscall"
 	"time"
 
+	"github.com/gilwong00/file-streamer/internal/pkg/admission"
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
 	"github.com/gilwong00/file-streamer/internal/pkg/config"
 	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/health"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/metrics"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/presign"
+	"github.com/gilwong00/file-streamer/internal/pkg/ratelimit"
+	"github.com/gilwong00/file-streamer/internal/pkg/share"
 	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"github.com/gilwong00/file-streamer/internal/pkg/tracing"
+	"github.com/gilwong00/file-streamer/internal/pkg/trash"
 )
 
 type httpServer struct {
@@ -24,60 +35,108 @@ type httpServer struct {
 	port             int
 	uploadFolderName string
 	storageClient    storage.Client
-	bucketName       string
+	namespaces       *namespace.Registry
+	trash            *trash.Trash
+	shares           *share.Manager
+	presigner        *presign.Issuer
+	authenticator    auth.Authenticator
+	authorizer       *authz.Authorizer
+	limiter          *ratelimit.Limiter
+	admission        *admission.Controller
+	accessLog        *logging.AccessLogger
+	health           *health.Checker
+	tlsConfig        *tls.Config
 }
 
 const (
 	MinCompressionSize = 8 * 1024 // 8kb
+
+	// metadataHeaderPrefix prefixes user metadata returned in response headers.
+	metadataHeaderPrefix = "X-File-Meta-"
 )
 
 func NewHttpServer(
 	ctx context.Context,
 	config *config.Config,
 	storageClient storage.Client,
-	bucketName string,
+	namespaces *namespace.Registry,
+	trash *trash.Trash,
+	shares *share.Manager,
+	presigner *presign.Issuer,
+	authenticator auth.Authenticator,
+	authorizer *authz.Authorizer,
+	limiter *ratelimit.Limiter,
+	admission *admission.Controller,
+	accessLog *logging.AccessLogger,
+	health *health.Checker,
+	tlsConfig *tls.Config,
 ) *httpServer {
 	return &httpServer{
 		ctx:              ctx,
 		port:             config.HTTPServerPort,
 		uploadFolderName: config.FileDirectoryName,
 		storageClient:    storageClient,
-		bucketName:       bucketName,
+		namespaces:       namespaces,
+		trash:            trash,
+		shares:           shares,
+		presigner:        presigner,
+		authenticator:    authenticator,
+		authorizer:       authorizer,
+		limiter:          limiter,
+		admission:        admission,
+		accessLog:        accessLog,
+		health:           health,
+		tlsConfig:        tlsConfig,
 	}
 }
 
-func (s *httpServer) Run() error {
+// Server returns the HTTP server serving the REST API, share links and
+// metrics. It is run by the caller.
+func (s *httpServer) Server() *http.Server {
+	api := http.NewServeMux()
+	api.Handle("HEAD /file/{fileName}", s.admit(admission.ClassMetadata, s.headHandler))
+	api.Handle("GET /file/{fileName}", s.admit(admission.ClassDownload, s.getHandler))
+	api.Handle("DELETE /file/{fileName}", s.admit(admission.ClassMetadata, s.deleteHandler))
+	api.Handle("POST /file/{fileName}", s.admit(admission.ClassMetadata, s.postFileHandler))
+	api.Handle("GET /trash", s.admit(admission.ClassMetadata, s.listTrashHandler))
+	api.Handle("POST /trash/{fileName}/undelete", s.admit(admission.ClassMetadata, s.undeleteHandler))
+	api.Handle("POST /share", s.admit(admission.ClassMetadata, s.createShareHandler))
+	api.Handle("GET /share", s.admit(admission.ClassMetadata, s.listSharesHandler))
+	api.Handle("DELETE /share/{id}", s.admit(admission.ClassMetadata, s.revokeShareHandler))
+	api.HandleFunc("POST /authz/check", s.checkAccessHandler)
+
 	mux := http.NewServeMux()
-	mux.HandleFunc("HEAD /file/{fileName}", s.headHandler)
-	mux.HandleFunc("GET /file/{fileName}", s.getHandler)
+	limited := ratelimit.Middleware(s.limiter, s.namespaces.Canonical, api)
+	if s.authenticator != nil {
+		mux.Handle("/", auth.Middleware(s.authenticator, limited))
+	} else {
+		mux.Handle("/", limited)
+	}
+	// Share links carry their own credential in the token, so they are
+	// reachable without authenticating. Their namespace is only known once
+	// the token is opened, so they are limited per client IP.
+	shareHandler := ratelimit.Middleware(s.limiter, nil, s.admit(admission.ClassDownload, s.shareHandler))
+	mux.Handle("HEAD /s/{token}", shareHandler)
+	mux.Handle("GET /s/{token}", shareHandler)
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.Handle("GET /healthz", s.health.LivenessHandler())
+	mux.Handle("GET /readyz", s.health.ReadinessHandler())
+	route := func(r *http.Request) string {
+		_, pattern := mux.Handler(r)
+		if pattern == "/" {
+			_, pattern = api.Handler(r)
+		}
+		return pattern
+	}
 
-	server := http.Server{
+	return &http.Server{
 		Addr:         fmt.Sprintf(":%v", s.port),
-		Handler:      mux,
+		Handler:      s.accessLog.Middleware(tracing.Middleware(route, metrics.Middleware(route, mux))),
 		ReadTimeout:  5 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  120 * time.Second,
+		TLSConfig:    s.tlsConfig,
 	}
-	// start the server
-	go func() {
-		log.Printf("Starting server on port %v\n", s.port)
-		if err := server.ListenAndServe(); err != nil {
-			fmt.Printf("Error starting server: %s", err.Error())
-			os.Exit(1)
-		}
-	}()
-	// trap sigterm or interupt and gracefully shutdown the server
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	signal.Notify(c, syscall.SIGTERM)
-	signal.Notify(c, syscall.SIGINT)
-	// Block until a signal is received.
-	sig := <-c
-	log.Println("Got signal:", sig)
-	// gracefully shutdown the server, waiting max 30 seconds for current operations to complete
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	return server.Shutdown(shutdownCtx)
 }
 
 func (s *httpServer) headHandler(w http.ResponseWriter, r *http.Request) {
@@ -96,7 +155,7 @@ func (s *httpServer) headHandler(w http.ResponseWriter, r *http.Request) {
 	// w.WriteHeader(http.StatusOK)
 
 	// using minio instead of writing to disk
-	fileName := r.PathValue("filename")
+	fileName := r.PathValue("fileName")
 	if fileName == "" {
 		http.Error(w, "missing fileName", http.StatusBadRequest)
 		return
@@ -105,25 +164,67 @@ func (s *httpServer) headHandler(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	info, err := s.storageClient.GetObjectInfo(r.Context(), s.bucketName, fileName)
+	ns, err := s.namespaces.Resolve(r.URL.Query().Get("namespace"))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, ns, fileName, authz.ActionRead) {
+		return
+	}
+	info, err := s.storageClient.GetObjectInfo(r.Context(), ns.Bucket, fileName, storage.GetObjectInfoOptions{
+		VersionID: r.URL.Query().Get("version"),
+	})
+	if err != nil {
+		writeStorageError(w, r, err)
 		return
 	}
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", info.Size))
 	w.Header().Set("Accept-Ranges", "bytes")
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	setMetadataHeaders(w, info)
+	if !checkPreconditions(w, r, info) {
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 }
 
 func (s *httpServer) getHandler(w http.ResponseWriter, r *http.Request) {
-	fileName := r.PathValue("filename")
+	fileName := r.PathValue("fileName")
 	if err := fileutils.ValidateFileName(fileName); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	info, err := s.storageClient.GetObjectInfo(r.Context(), s.bucketName, fileName)
+	ns, err := s.namespaces.Resolve(r.URL.Query().Get("namespace"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, ns, fileName, authz.ActionRead) {
+		return
+	}
+	s.serveObject(w, r, ns, fileName, r.URL.Query().Get("version"))
+}
+
+// serveObject streams a file, or the byte range requested by the Range
+// header, to the client.
+func (s *httpServer) serveObject(
+	w http.ResponseWriter,
+	r *http.Request,
+	ns namespace.Namespace,
+	fileName string,
+	versionID string,
+) {
+	info, err := s.storageClient.GetObjectInfo(r.Context(), ns.Bucket, fileName, storage.GetObjectInfoOptions{
+		VersionID: versionID,
+	})
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeStorageError(w, r, err)
+		return
+	}
+	if !checkPreconditions(w, r, info) {
 		return
 	}
 	// Parse Range header (supports bytes=start-end, bytes=start-, bytes=-suffix)
@@ -135,14 +236,16 @@ func (s *httpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	obj, err := s.storageClient.GetObject(
 		r.Context(),
-		s.bucketName,
+		ns.Bucket,
 		fileName, storage.GetObjectOptions{
-			Start: start,
-			End:   end,
+			Start:     start,
+			End:       end,
+			VersionID: versionID,
+			MatchETag: info.ETag,
 		},
 	)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeStorageError(w, r, err)
 		return
 	}
 	defer obj.Close()
@@ -150,14 +253,134 @@ func (s *httpServer) getHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, info.Size))
 	w.Header().Set("Content-Length", fmt.Sprintf("%d", end-start+1))
 	w.Header().Set("Content-Type", "application/octet-stream")
+	setMetadataHeaders(w, info)
+	// Downloads, throttled ones especially, outlive the write timeout meant
+	// for short responses.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
 	w.WriteHeader(http.StatusPartialContent)
+	metrics.RecordDownload(metrics.TransportHTTP, r.Header.Get("Range") != "")
+	body := s.limiter.Reader(r.Context(), ratelimit.KeysFor(r.Context(), ns.Name), obj)
+	body = tracing.NewProgress(r.Context()).Reader(body)
 	// Optional gzip compression
+	var copyErr error
 	if shouldCompress(r, fileName, end-start+1) {
-		gz := gzip.NewWriter(w)
-		defer gz.Close()
-		io.Copy(gz, obj)
+		out := &metrics.CountingWriter{W: w}
+		gz := gzip.NewWriter(out)
+		var n int64
+		n, copyErr = io.Copy(gz, body)
+		gz.Close()
+		metrics.RecordCompression(metrics.TransportHTTP, n, out.Written)
 	} else {
-		io.Copy(w, obj)
+		_, copyErr = io.Copy(w, body)
+	}
+	if copyErr != nil {
+		// The status line is already sent; the client sees a short body.
+		logging.FromContext(r.Context()).Warn("download interrupted",
+			"namespace", ns.Name,
+			"file_name", fileName,
+			"error", copyErr,
+		)
+	}
+}
+
+// admit wraps handler so that it only runs once admitted as class.
+func (s *httpServer) admit(class admission.Class, handler http.HandlerFunc) http.Handler {
+	return admission.Middleware(s.admission, class, handler)
+}
+
+// authorize checks that the caller may perform action on path in ns. When
+// it may not, a 403 response explaining why is written and false returned.
+func (s *httpServer) authorize(
+	w http.ResponseWriter,
+	r *http.Request,
+	ns namespace.Namespace,
+	path string,
+	action string,
+) bool {
+	decision := s.authorizer.Authorize(authz.Request{
+		Identity:  auth.Caller(r.Context()),
+		Namespace: ns.Name,
+		Path:      path,
+		Action:    action,
+	})
+	if err := decision.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// listable reports whether the caller may see the file name in listings of
+// ns: being allowed to list a prefix does not reveal the files under it
+// that a rule denies listing.
+func (s *httpServer) listable(r *http.Request, ns namespace.Namespace, fileName string) bool {
+	return s.authorizer.Authorize(authz.Request{
+		Identity:  auth.Caller(r.Context()),
+		Namespace: ns.Name,
+		Path:      fileName,
+		Action:    authz.ActionList,
+	}).Allowed
+}
+
+// setMetadataHeaders exposes the object's user metadata as X-File-Meta-* headers,
+// e.g. so clients can read the header of a client-side encrypted object.
+// The version ID is returned as X-Version-Id in versioned namespaces, along
+// with the ETag and Last-Modified validators.
+func setMetadataHeaders(w http.ResponseWriter, info storage.ObjectInfo) {
+	for key, value := range info.Metadata {
+		w.Header().Set(metadataHeaderPrefix+key, value)
+	}
+	if info.VersionID != "" {
+		w.Header().Set("X-Version-Id", info.VersionID)
+	}
+	if info.ETag != "" {
+		w.Header().Set("ETag", `"`+info.ETag+`"`)
+	}
+	if !info.LastModified.IsZero() {
+		w.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	}
+}
+
+// checkPreconditions evaluates the If-Match and If-None-Match headers
+// against the object's ETag, so that caches can revalidate their copy and
+// pin ranged reads to it. When a precondition fails, the 412 or 304
+// response is written and false returned.
+func checkPreconditions(w http.ResponseWriter, r *http.Request, info storage.ObjectInfo) bool {
+	if header := r.Header.Get("If-Match"); header != "" && !etagMatches(header, info.ETag) {
+		http.Error(w, storage.ErrModified.Error(), http.StatusPreconditionFailed)
+		return false
+	}
+	if header := r.Header.Get("If-None-Match"); header != "" && etagMatches(header, info.ETag) {
+		w.Header().Del("Content-Length")
+		w.Header().Del("Content-Type")
+		w.WriteHeader(http.StatusNotModified)
+		return false
+	}
+	return true
+}
+
+// etagMatches reports whether an If-Match or If-None-Match header lists
+// etag, comparing weakly.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		candidate = strings.Trim(strings.TrimPrefix(candidate, "W/"), `"`)
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("writing json response", "error", err)
 	}
 }
 
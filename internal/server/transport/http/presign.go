@@ -0,0 +1,104 @@
+package httptransport
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/presign"
+)
+
+// presignSuffix marks POST /file/{fileName}:presign requests.
+const presignSuffix = ":presign"
+
+// presignRequest is the JSON body of POST /file/{fileName}:presign.
+type presignRequest struct {
+	// Method is "GET" (default) or "PUT".
+	Method           string `json:"method"`
+	VersionID        string `json:"version_id"`
+	ExpiresInSeconds int64  `json:"expires_in_seconds"`
+	VerifyCompletion bool   `json:"verify_completion"`
+}
+
+// presignResponse is the JSON representation of a presigned URL.
+type presignResponse struct {
+	URL       string    `json:"url"`
+	Method    string    `json:"method"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// postFileHandler dispatches POST /file/{fileName}:<verb> requests. The
+// verb is part of the last path segment, which ServeMux patterns cannot
+// express.
+func (s *httpServer) postFileHandler(w http.ResponseWriter, r *http.Request) {
+	if fileName, ok := strings.CutSuffix(r.PathValue("fileName"), presignSuffix); ok {
+		s.presignHandler(w, r, fileName)
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// presignHandler returns a time-limited URL for transferring a file directly
+// to or from the storage backend.
+func (s *httpServer) presignHandler(w http.ResponseWriter, r *http.Request, fileName string) {
+	if err := fileutils.ValidateFileName(fileName); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ns, err := s.namespaces.Resolve(r.URL.Query().Get("namespace"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	req := presignRequest{Method: presign.MethodGet}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if req.ExpiresInSeconds < 0 {
+		http.Error(w, "expiry must not be negative", http.StatusBadRequest)
+		return
+	}
+	req.Method = strings.ToUpper(req.Method)
+	action := authz.ActionRead
+	if req.Method == presign.MethodPut {
+		action = authz.ActionWrite
+	}
+	if !s.authorize(w, r, ns, fileName, action) {
+		return
+	}
+	u, err := s.presigner.Issue(r.Context(), presign.Request{
+		Namespace:        ns,
+		FileName:         fileName,
+		Method:           req.Method,
+		VersionID:        req.VersionID,
+		TTL:              time.Duration(req.ExpiresInSeconds) * time.Second,
+		VerifyCompletion: req.VerifyCompletion,
+		Remote:           r.RemoteAddr,
+	})
+	switch {
+	case errors.Is(err, presign.ErrInvalidRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, presign.ErrUnsupported):
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	case errors.Is(err, presign.ErrTooManyWatchers):
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, presignResponse{
+		URL:       u.URL,
+		Method:    u.Method,
+		ExpiresAt: u.ExpiresAt,
+	})
+}
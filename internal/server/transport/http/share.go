@@ -0,0 +1,213 @@
+package httptransport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/share"
+	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+)
+
+// sharePasswordHeader carries the password of a protected share link. The
+// password may also be passed as the "password" query parameter.
+const sharePasswordHeader = "X-Share-Password"
+
+// createShareRequest is the JSON body of POST /share.
+type createShareRequest struct {
+	FileName         string `json:"file_name"`
+	Namespace        string `json:"namespace"`
+	VersionID        string `json:"version_id"`
+	ExpiresInSeconds int64  `json:"expires_in_seconds"`
+	MaxDownloads     int64  `json:"max_downloads"`
+	Password         string `json:"password"`
+}
+
+// shareLink is the JSON representation of a share link.
+type shareLink struct {
+	ID                string    `json:"id"`
+	FileName          string    `json:"file_name"`
+	VersionID         string    `json:"version_id,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	ExpiresAt         time.Time `json:"expires_at"`
+	MaxDownloads      int64     `json:"max_downloads"`
+	Downloads         int64     `json:"downloads"`
+	PasswordProtected bool      `json:"password_protected"`
+	// Token and URL are only returned when the link is created.
+	Token string `json:"token,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+func toShareLink(link share.Link) shareLink {
+	return shareLink{
+		ID:                link.ID,
+		FileName:          link.FileName,
+		VersionID:         link.VersionID,
+		CreatedAt:         link.CreatedAt,
+		ExpiresAt:         link.ExpiresAt,
+		MaxDownloads:      link.MaxDownloads,
+		Downloads:         link.Downloads,
+		PasswordProtected: link.PasswordProtected(),
+	}
+}
+
+// createShareHandler creates a link through which a file can be downloaded
+// without credentials. Like listing and revoking links, it requires the
+// admin action, since the link outlives the caller's own access.
+func (s *httpServer) createShareHandler(w http.ResponseWriter, r *http.Request) {
+	var req createShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := fileutils.ValidateFileName(req.FileName); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ns, err := s.namespaces.Resolve(req.Namespace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, ns, req.FileName, authz.ActionAdmin) {
+		return
+	}
+	if req.ExpiresInSeconds < 0 || req.MaxDownloads < 0 {
+		http.Error(w, "expiry and max downloads must not be negative", http.StatusBadRequest)
+		return
+	}
+	link, token, err := s.shares.Create(r.Context(), ns, req.FileName, share.CreateOptions{
+		VersionID:    req.VersionID,
+		TTL:          time.Duration(req.ExpiresInSeconds) * time.Second,
+		MaxDownloads: req.MaxDownloads,
+		Password:     req.Password,
+	})
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	res := toShareLink(link)
+	res.Token = token
+	res.URL = s.shares.URL(token)
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// listSharesHandler lists the active share links of a namespace.
+func (s *httpServer) listSharesHandler(w http.ResponseWriter, r *http.Request) {
+	ns, err := s.namespaces.Resolve(r.URL.Query().Get("namespace"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, ns, "", authz.ActionAdmin) {
+		return
+	}
+	links, err := s.shares.List(r.Context(), ns)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	res := make([]shareLink, 0, len(links))
+	for _, link := range links {
+		res = append(res, toShareLink(link))
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// revokeShareHandler permanently disables a share link.
+func (s *httpServer) revokeShareHandler(w http.ResponseWriter, r *http.Request) {
+	ns, err := s.namespaces.Resolve(r.URL.Query().Get("namespace"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, ns, "", authz.ActionAdmin) {
+		return
+	}
+	err = s.shares.Revoke(r.Context(), ns, r.PathValue("id"))
+	switch {
+	case errors.Is(err, share.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		writeStorageError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// shareHandler serves the file behind a share link through the same path as
+// getHandler, including range requests.
+//
+// Every GET counts as a download, except one resuming a transfer, i.e.
+// requesting a range that does not start at the first byte, from a client
+// that already downloaded the file, so that clients fetching a file in
+// pieces only use up one download. HEAD requests never count. A malformed range counts too and
+// is refused, rather than served the whole file, so that no request
+// escapes the limit.
+func (s *httpServer) shareHandler(w http.ResponseWriter, r *http.Request) {
+	password := r.Header.Get(sharePasswordHeader)
+	if password == "" {
+		password = r.URL.Query().Get("password")
+	}
+	link, err := s.shares.Open(r.Context(), r.PathValue("token"), password)
+	if err != nil {
+		writeShareError(w, r, err)
+		return
+	}
+	ns, err := s.namespaces.Resolve(link.Namespace)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	info, err := s.storageClient.GetObjectInfo(r.Context(), ns.Bucket, link.FileName, storage.GetObjectInfoOptions{
+		VersionID: link.VersionID,
+	})
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	start, _, rangeErr := parseRange(r.Header.Get("Range"), info.Size)
+	if r.Method == http.MethodGet {
+		resume := rangeErr == nil && start > 0
+		if err := s.shares.Count(r.Context(), link.ID, clientHost(r), resume); err != nil {
+			writeShareError(w, r, err)
+			return
+		}
+	}
+	if rangeErr != nil {
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes */%d", info.Size))
+		http.Error(w, rangeErr.Error(), http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	s.serveObject(w, r, ns, link.FileName, link.VersionID)
+}
+
+// clientHost returns the address of the client of r, without its port.
+func clientHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// writeShareError writes the response to a share link that cannot be
+// served.
+func writeShareError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, share.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, share.ErrPasswordRequired):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, share.ErrExhausted):
+		http.Error(w, err.Error(), http.StatusGone)
+	default:
+		writeStorageError(w, r, err)
+	}
+}
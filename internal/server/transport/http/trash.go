@@ -0,0 +1,111 @@
+package httptransport
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
+	"github.com/gilwong00/file-streamer/internal/pkg/fileutils"
+	"github.com/gilwong00/file-streamer/internal/pkg/trash"
+)
+
+// trashEntry is the JSON representation of a trash entry.
+type trashEntry struct {
+	TrashID   string    `json:"trash_id"`
+	FileName  string    `json:"file_name"`
+	Size      int64     `json:"size"`
+	DeletedAt time.Time `json:"deleted_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func toTrashEntry(entry trash.Entry) trashEntry {
+	return trashEntry{
+		TrashID:   entry.ID,
+		FileName:  entry.FileName,
+		Size:      entry.Size,
+		DeletedAt: entry.DeletedAt,
+		ExpiresAt: entry.ExpiresAt,
+	}
+}
+
+// deleteHandler soft deletes a file by moving it into the namespace's trash.
+func (s *httpServer) deleteHandler(w http.ResponseWriter, r *http.Request) {
+	fileName := r.PathValue("fileName")
+	if err := fileutils.ValidateFileName(fileName); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ns, err := s.namespaces.Resolve(r.URL.Query().Get("namespace"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, ns, fileName, authz.ActionDelete) {
+		return
+	}
+	entry, err := s.trash.Delete(r.Context(), ns, fileName)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toTrashEntry(entry))
+}
+
+// listTrashHandler lists the deleted files that can still be undeleted.
+func (s *httpServer) listTrashHandler(w http.ResponseWriter, r *http.Request) {
+	ns, err := s.namespaces.Resolve(r.URL.Query().Get("namespace"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, ns, "", authz.ActionList) {
+		return
+	}
+	entries, err := s.trash.List(r.Context(), ns)
+	if err != nil {
+		writeStorageError(w, r, err)
+		return
+	}
+	res := make([]trashEntry, 0, len(entries))
+	for _, entry := range entries {
+		if s.listable(r, ns, entry.FileName) {
+			res = append(res, toTrashEntry(entry))
+		}
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// undeleteHandler restores a file from the trash. The optional id query
+// parameter selects a deletion; by default the most recent one is restored.
+func (s *httpServer) undeleteHandler(w http.ResponseWriter, r *http.Request) {
+	fileName := r.PathValue("fileName")
+	if err := fileutils.ValidateFileName(fileName); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ns, err := s.namespaces.Resolve(r.URL.Query().Get("namespace"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.authorize(w, r, ns, fileName, authz.ActionWrite) {
+		return
+	}
+	info, err := s.trash.Undelete(r.Context(), ns, fileName, r.URL.Query().Get("id"))
+	switch {
+	case errors.Is(err, trash.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, trash.ErrFileExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		writeStorageError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{
+		"file_name":  fileName,
+		"version_id": info.VersionID,
+	})
+}
@@ -0,0 +1,59 @@
+package transport
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"connectrpc.com/grpchealth"
+	"github.com/gilwong00/file-streamer/internal/gen/proto/v1/transferv1connect"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// connectServices are the services served by the ConnectRPC handler.
+var connectServices = []string{
+	transferv1connect.TransferServiceName,
+	grpchealth.HealthV1ServiceName,
+}
+
+// multiplexedServer returns server serving the ConnectRPC handler as well,
+// for the requests isConnectRequest recognizes.
+func multiplexedServer(server *http.Server, connectHandler http.Handler) *http.Server {
+	httpHandler := server.Handler
+	handler := http.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isConnectRequest(r) {
+			httpHandler.ServeHTTP(w, r)
+			return
+		}
+		// Streams outlive the read and write timeouts meant for REST
+		// requests, which HTTP/2 applies to every stream.
+		rc := http.NewResponseController(w)
+		rc.SetReadDeadline(time.Time{})
+		rc.SetWriteDeadline(time.Time{})
+		connectHandler.ServeHTTP(w, r)
+	}))
+	if server.TLSConfig == nil {
+		handler = h2c.NewHandler(handler, &http2.Server{})
+	}
+	server.Handler = handler
+	return server
+}
+
+// isConnectRequest reports whether r is a gRPC, gRPC-Web or Connect
+// request. Those protocols are told apart by their content type, except
+// for Connect unary calls, which use plain content types and are told apart
+// by their procedure path.
+func isConnectRequest(r *http.Request) bool {
+	contentType := r.Header.Get("Content-Type")
+	if strings.HasPrefix(contentType, "application/grpc") ||
+		strings.HasPrefix(contentType, "application/connect+") {
+		return true
+	}
+	for _, service := range connectServices {
+		if strings.HasPrefix(r.URL.Path, "/"+service+"/") {
+			return true
+		}
+	}
+	return false
+}
@@ -2,9 +2,21 @@ package transport
 
 import (
 	"context"
+	"crypto/tls"
 
+	"github.com/gilwong00/file-streamer/internal/pkg/admission"
+	"github.com/gilwong00/file-streamer/internal/pkg/auth"
+	"github.com/gilwong00/file-streamer/internal/pkg/authz"
 	"github.com/gilwong00/file-streamer/internal/pkg/config"
+	"github.com/gilwong00/file-streamer/internal/pkg/health"
+	"github.com/gilwong00/file-streamer/internal/pkg/logging"
+	"github.com/gilwong00/file-streamer/internal/pkg/namespace"
+	"github.com/gilwong00/file-streamer/internal/pkg/presign"
+	"github.com/gilwong00/file-streamer/internal/pkg/ratelimit"
+	"github.com/gilwong00/file-streamer/internal/pkg/share"
 	"github.com/gilwong00/file-streamer/internal/pkg/storage"
+	"github.com/gilwong00/file-streamer/internal/pkg/supervisor"
+	"github.com/gilwong00/file-streamer/internal/pkg/trash"
 	grpctransport "github.com/gilwong00/file-streamer/internal/server/transport/grpc"
 	httptransport "github.com/gilwong00/file-streamer/internal/server/transport/http"
 )
@@ -19,21 +31,50 @@ func InitializeTransports(
 	ctx context.Context,
 	config *config.Config,
 	storageClient storage.Client,
+	namespaces *namespace.Registry,
+	trash *trash.Trash,
+	shares *share.Manager,
+	presigner *presign.Issuer,
+	authenticator auth.Authenticator,
+	authorizer *authz.Authorizer,
+	limiter *ratelimit.Limiter,
+	admission *admission.Controller,
+	accessLog *logging.AccessLogger,
+	tlsConfig *tls.Config,
 ) error {
-	errors := make(chan error, 2)
-	// TODO: add bucket name
-	httpServer := httptransport.NewHttpServer(ctx, config, storageClient, "")
-	connectRPCServer, err := grpctransport.NewConnectRPCServer(ctx, config, storageClient)
+	supervisor := supervisor.New(config.ShutdownGracePeriod, config.ShutdownDrainDelay)
+	health := newHealthChecker(config, storageClient, namespaces, supervisor)
+	httpServer := httptransport.NewHttpServer(ctx, config, storageClient, namespaces, trash, shares, presigner, authenticator, authorizer, limiter, admission, accessLog, health, tlsConfig)
+	connectRPCServer, err := grpctransport.NewConnectRPCServer(ctx, config, storageClient, namespaces, trash, shares, presigner, authenticator, authorizer, limiter, admission, accessLog, health, tlsConfig)
 	if err != nil {
 		return err
 	}
-	go func() {
-		errors <- httpServer.Run()
-	}()
-	go func() {
-		errors <- connectRPCServer.StartServer()
-	}()
-	// Wait for the first error
-	err = <-errors
-	return err
+	if config.SinglePort {
+		supervisor.Add("http", multiplexedServer(httpServer.Server(), connectRPCServer.Handler()), config.HTTPListenAddresses...)
+	} else {
+		supervisor.Add("http", httpServer.Server(), config.HTTPListenAddresses...)
+		supervisor.Add("connect", connectRPCServer.Server(), config.ConnectRPCListenAddresses...)
+	}
+	// Run until ctx is canceled, then drain both servers together.
+	return supervisor.Run(ctx)
+}
+
+// newHealthChecker returns the health checker reporting the server ready
+// while the supervisor is and the bucket of every namespace is reachable.
+func newHealthChecker(
+	config *config.Config,
+	storageClient storage.Client,
+	namespaces *namespace.Registry,
+	supervisor *supervisor.Supervisor,
+) *health.Checker {
+	var checks []health.Check
+	seen := make(map[string]bool)
+	for _, ns := range namespaces.List() {
+		if seen[ns.Bucket] {
+			continue
+		}
+		seen[ns.Bucket] = true
+		checks = append(checks, health.BucketCheck(storageClient, ns.Bucket))
+	}
+	return health.NewChecker(supervisor.Ready, config.HealthCheckCacheTTL, config.HealthCheckTimeout, checks...)
 }